@@ -5,16 +5,32 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"runtime/debug"
 	"syscall"
+	"time"
+	_ "time/tzdata"
 
+	"github.com/codex-k8s/telegram-executor/internal/audit"
 	"github.com/codex-k8s/telegram-executor/internal/config"
+	"github.com/codex-k8s/telegram-executor/internal/events"
 	"github.com/codex-k8s/telegram-executor/internal/executions"
 	httpapi "github.com/codex-k8s/telegram-executor/internal/http"
 	"github.com/codex-k8s/telegram-executor/internal/i18n"
 	"github.com/codex-k8s/telegram-executor/internal/log"
+	"github.com/codex-k8s/telegram-executor/internal/metrics"
+	"github.com/codex-k8s/telegram-executor/internal/reporting"
+	"github.com/codex-k8s/telegram-executor/internal/schedules"
+	"github.com/codex-k8s/telegram-executor/internal/state"
 	"github.com/codex-k8s/telegram-executor/internal/telegram"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/outbound"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/updates"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+	"github.com/codex-k8s/telegram-executor/internal/tools"
 )
 
+// version is set at build time via -ldflags "-X main.version=...".
+var version string
+
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
@@ -23,24 +39,141 @@ func main() {
 	}
 
 	logger := log.New(cfg.LogLevel)
-	bundle, err := i18n.Load(cfg.Lang)
+	bundle, err := i18n.Load(cfg.Lang, cfg.I18nDir)
 	if err != nil {
 		logger.Error("failed to load i18n", "error", err)
 		os.Exit(1)
 	}
 
+	reporter, err := reporting.New(cfg.SentryDSN, cfg.SentryEnvironment, buildVersion(), logger)
+	if err != nil {
+		logger.Error("failed to init error reporting", "error", err)
+		os.Exit(1)
+	}
+
+	auditLog, err := audit.New(cfg.AuditLogFile, logger)
+	if err != nil {
+		logger.Error("failed to init audit log", "error", err)
+		os.Exit(1)
+	}
+
+	store, err := state.Open(cfg.StateFile)
+	if err != nil {
+		logger.Error("failed to open state file", "error", err)
+		os.Exit(1)
+	}
+
+	tenantSet, err := tenants.Load(cfg.TenantsFile)
+	if err != nil {
+		logger.Error("failed to load tenants", "error", err)
+		os.Exit(1)
+	}
+
+	toolRegistry, err := tools.Load(cfg.ToolsFile)
+	if err != nil {
+		logger.Error("failed to load tool profiles", "error", err)
+		os.Exit(1)
+	}
+	if toolRegistry.Len() > 0 {
+		logger.Info("Loaded tool profiles", "file", cfg.ToolsFile, "count", toolRegistry.Len())
+	}
+
 	registry := executions.NewRegistry()
-	service, err := telegram.New(cfg, bundle, registry, logger)
+	registry.SetRetention(cfg.ResultRetention)
+	metricsRegistry := metrics.NewRegistry()
+	metricsRegistry.GaugeFunc("telegram_executor_pending_executions", "Number of unresolved executions.", func() float64 {
+		return float64(registry.Stats().Pending)
+	})
+	if len(cfg.MetricLabels) > 0 {
+		metricsRegistry.GaugeVecFunc("telegram_executor_pending_executions_by_label", "Number of unresolved executions by request label.", []string{"label", "value"}, func() []metrics.Sample {
+			return pendingByLabel(registry.List(nil), cfg.MetricLabels)
+		})
+	}
+	if tenantSet.Enabled() {
+		registerTenantMetrics(metricsRegistry, tenantSet, registry)
+	}
+	bus := events.NewBus()
+	bus.Subscribe(metrics.NewEventCollector(metricsRegistry).Handle)
+	bus.Subscribe(auditLog.Handle)
+
+	var service *telegram.Service
+	if cfg.Simulate {
+		logger.Warn("Simulation mode: Telegram is not contacted, updates are injected via /simulate")
+		service, err = telegram.NewSimulated(cfg, bundle, registry, store, tenantSet, bus, reporter, logger)
+	} else {
+		service, err = telegram.New(cfg, bundle, registry, store, tenantSet, bus, reporter, logger)
+	}
 	if err != nil {
 		logger.Error("failed to init telegram service", "error", err)
 		os.Exit(1)
 	}
+	registerOutboundMetrics(metricsRegistry, service.OutboundQueue())
+	registerUpdateMetrics(metricsRegistry, service)
 
-	server := httpapi.New(cfg.HTTPAddr(), logger)
-	server.Handle("/execute", httpapi.NewExecuteHandler(service, cfg, logger))
+	server := httpapi.New(cfg.HTTPAddr(), reporter, logger)
+	// Admin endpoints share the main listener unless TG_EXECUTOR_ADMIN_HTTP_PORT moves them to their own.
+	adminServer := server
+	if cfg.AdminEnabled() {
+		adminServer = httpapi.New(cfg.AdminHTTPAddr(), reporter, logger)
+		adminServer.RestrictNetworks(cfg.AdminAllowedNetworks)
+		adminServer.HandleProfiling()
+	}
+	executeHandler := httpapi.NewExecuteHandler(service, cfg, tenantSet, toolRegistry, logger)
+	server.Handle("/execute", executeHandler)
+	scheduleRegistry := schedules.NewRegistry(store, cfg.Timezone, logger)
+	schedulesHandler := httpapi.NewSchedulesHandler(scheduleRegistry, executeHandler, tenantSet, logger)
+	server.Handle("/schedules", schedulesHandler)
+	server.Handle("/schedules/", schedulesHandler)
+	adminServer.Handle("/executions", httpapi.NewExecutionsHandler(registry, tenantSet))
+	adminServer.Handle("/executions/", httpapi.NewExecutionHandler(service, registry, tenantSet))
+	adminServer.Handle("/usage", httpapi.NewUsageHandler(registry, tenantSet))
+	server.Handle("/groups/", httpapi.NewGroupsHandler(service, tenantSet))
+	toolsHandler := httpapi.NewToolsHandler(toolRegistry, tenantSet, logger)
+	server.Handle("/tools", toolsHandler)
+	server.Handle("/tools/", toolsHandler)
+	adminServer.Handle("/events", httpapi.NewEventsHandler(bus, tenantSet, logger))
+	// The dashboard cancels and resolves executions, so it is served only behind tenant keys or the restricted
+	// admin listener.
+	if tenantSet.Enabled() || cfg.AdminRestricted() {
+		adminServer.Handle(httpapi.DashboardPath, httpapi.NewDashboardHandler(tenantSet))
+	} else {
+		logger.Warn("Dashboard disabled: configure tenants or TG_EXECUTOR_ADMIN_ALLOWED_NETWORKS to serve /ui")
+	}
+	testPrompts := httpapi.NewTestPromptHandler(service, registry, cfg, tenantSet, logger)
+	adminServer.Handle(httpapi.TestPromptPath, testPrompts)
+	adminServer.Handle(httpapi.TestPromptPath+"/", testPrompts)
+	adminServer.Handle("/metrics", metricsRegistry.Handler())
+	if cfg.WebAppURL != "" {
+		server.Handle(config.WebAppPath, httpapi.NewWebAppHandler(registry, service.Messages, logger))
+	}
+	if cfg.Simulate {
+		server.Handle(httpapi.SimulatePath+"/", httpapi.NewSimulateHandler(service, registry, cfg, tenantSet, logger))
+	}
 	if webhook := service.WebhookHandler(); webhook != nil {
-		server.Handle("/webhook", webhook)
+		server.Handle(cfg.WebhookPath(), webhook)
+	}
+	cors := httpapi.CORSConfig{AllowedOrigins: cfg.CORSAllowedOrigins, AllowedHeaders: cfg.CORSAllowedHeaders, AllowedMethods: cfg.CORSAllowedMethods}
+	server.SetCORS(cors)
+	if adminServer != server {
+		adminServer.SetCORS(cors)
+	}
+	if cfg.AccessLog {
+		server.RedactPattern(cfg.WebhookPath(), "/webhook")
+		server.RedactPattern(config.WebAppPath, config.WebAppPath+"<token>")
+		server.SetAccessLog(cfg.AccessLogSampleRate)
+		if adminServer != server {
+			adminServer.SetAccessLog(cfg.AccessLogSampleRate)
+		}
+	}
+	server.AddReadinessCheck("telegram", service.CheckTelegram)
+	server.AddReadinessCheck("chats", service.CheckChats)
+	if cfg.UpdatesLagThreshold > 0 {
+		server.AddReadinessCheck("updates_lag", service.CheckUpdateLag)
+	}
+	if cfg.WebhookEnabled() {
+		server.AddReadinessCheck("webhook", service.CheckUpdates)
 	}
+	server.SetPendingStats(registry.Stats)
 
 	baseCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -49,10 +182,20 @@ func main() {
 		logger.Error("failed to start telegram updates", "error", err)
 		os.Exit(1)
 	}
+	if cfg.StartupAnnouncement {
+		if err := service.Announce(baseCtx, buildVersion()); err != nil {
+			logger.Warn("Failed to send startup announcement", "error", err)
+		}
+	}
+	go scheduleRegistry.Run(baseCtx, schedulesHandler.Submit)
 	server.SetReady(true)
+	adminServer.SetReady(true)
 
-	errCh := make(chan error, 1)
+	errCh := make(chan error, 2)
 	go func() { errCh <- server.ListenAndServe() }()
+	if adminServer != server {
+		go func() { errCh <- adminServer.ListenAndServe() }()
+	}
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
@@ -66,8 +209,89 @@ func main() {
 
 	cancel()
 	server.SetReady(false)
+	adminServer.SetReady(false)
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer shutdownCancel()
 	_ = server.Shutdown(shutdownCtx)
+	if adminServer != server {
+		_ = adminServer.Shutdown(shutdownCtx)
+	}
 	_ = service.Stop(shutdownCtx)
+	_ = reporter.Close(shutdownCtx)
+	_ = auditLog.Close()
+}
+
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// pendingByLabel counts pending executions per value of each exported label key.
+func pendingByLabel(pending []executions.Summary, keys []string) []metrics.Sample {
+	counts := map[[2]string]float64{}
+	for _, summary := range pending {
+		for _, key := range keys {
+			if value, ok := summary.Labels[key]; ok {
+				counts[[2]string{key, value}]++
+			}
+		}
+	}
+	samples := make([]metrics.Sample, 0, len(counts))
+	for pair, count := range counts {
+		samples = append(samples, metrics.Sample{LabelValues: pair[:], Value: count})
+	}
+	return samples
+}
+
+// registerTenantMetrics exports per-tenant quota usage computed at scrape time.
+func registerTenantMetrics(metricsRegistry *metrics.Registry, tenantSet *tenants.Set, registry *executions.Registry) {
+	gauge := func(name, help string, value func(tenants.Usage) int) {
+		metricsRegistry.GaugeVecFunc(name, help, []string{"tenant"}, func() []metrics.Sample {
+			usage := tenantSet.Usage(registry.Count, time.Now())
+			samples := make([]metrics.Sample, 0, len(usage))
+			for _, item := range usage {
+				samples = append(samples, metrics.Sample{LabelValues: []string{item.Tenant}, Value: float64(value(item))})
+			}
+			return samples
+		})
+	}
+	gauge("telegram_executor_tenant_pending_executions", "Number of unresolved executions by tenant.", func(u tenants.Usage) int { return u.Pending })
+	gauge("telegram_executor_tenant_submissions_today", "Accepted submissions of the current UTC day by tenant.", func(u tenants.Usage) int { return u.SubmittedToday })
+	gauge("telegram_executor_tenant_rejections_today", "Submissions rejected by quotas during the current UTC day by tenant.", func(u tenants.Usage) int { return u.RejectedToday })
+}
+
+// updateLatencyBuckets cover handling time of one update, from plain button presses to voice transcription.
+var updateLatencyBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// registerUpdateMetrics exports update delivery counters and handling latency.
+func registerUpdateMetrics(metricsRegistry *metrics.Registry, service *telegram.Service) {
+	counter := func(name, help string, value func(updates.Stats) uint64) {
+		metricsRegistry.CounterFunc(name, help, func() float64 { return float64(value(service.UpdateStats())) })
+	}
+	counter("telegram_executor_updates_received_total", "Telegram updates received from the update source.", func(s updates.Stats) uint64 { return s.Received })
+	counter("telegram_executor_updates_dropped_total", "Webhook updates rejected because the update queue was full.", func(s updates.Stats) uint64 { return s.Dropped })
+	counter("telegram_executor_updates_duplicate_total", "Webhook redeliveries of already queued updates ignored.", func(s updates.Stats) uint64 { return s.Duplicates })
+	counter("telegram_executor_poll_errors_total", "Failed getUpdates calls of long polling.", func(s updates.Stats) uint64 { return s.PollErrors })
+	counter("telegram_executor_poll_reconnects_total", "Long polling recoveries after failed getUpdates calls.", func(s updates.Stats) uint64 { return s.Reconnects })
+	latency := metricsRegistry.Histogram("telegram_executor_update_handling_seconds", "Time spent handling one Telegram update.", updateLatencyBuckets)
+	service.ObserveUpdateLatency(func(elapsed time.Duration) {
+		latency.Observe(elapsed.Seconds())
+	})
+}
+
+// registerOutboundMetrics exports the depth of per-chat queues of outgoing Telegram calls.
+func registerOutboundMetrics(metricsRegistry *metrics.Registry, queue *outbound.Queue) {
+	metricsRegistry.GaugeVecFunc("telegram_executor_outbound_queue_depth", "Number of queued and running Telegram calls by chat.", []string{"chat_id"}, func() []metrics.Sample {
+		depths := queue.Depths()
+		samples := make([]metrics.Sample, 0, len(depths))
+		for _, depth := range depths {
+			samples = append(samples, metrics.Sample{LabelValues: []string{depth.Chat}, Value: float64(depth.Calls)})
+		}
+		return samples
+	})
 }
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/codex-k8s/telegram-executor/internal/events"
+)
+
+// Log records lifecycle events to the service log and optionally to a JSON lines file.
+type Log struct {
+	log  *slog.Logger
+	mu   sync.Mutex
+	file io.WriteCloser
+}
+
+// New creates audit log. When path is empty events are written only to the service log.
+func New(path string, log *slog.Logger) (*Log, error) {
+	a := &Log{log: log}
+	if path == "" {
+		return a, nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	a.file = file
+	return a, nil
+}
+
+// Handle consumes a lifecycle event.
+func (a *Log) Handle(event events.Event) {
+	level := slog.LevelInfo
+	switch event.Type {
+	case events.TypeCallbackFailed:
+		level = slog.LevelError
+	case events.TypeFinalizeFallback:
+		level = slog.LevelWarn
+	}
+	attrs := []any{"event", string(event.Type), "correlation_id", event.CorrelationID}
+	attrs = appendNonEmpty(attrs, "tool", event.Tool)
+	if event.MessageID > 0 {
+		attrs = append(attrs, "message_id", event.MessageID)
+	}
+	attrs = appendNonEmpty(attrs, "input_mode", event.InputMode)
+	attrs = appendNonEmpty(attrs, "status", event.Status)
+	attrs = appendNonEmpty(attrs, "error", event.Error)
+	a.log.Log(context.Background(), level, "Execution event", attrs...)
+	if a.file == nil {
+		return
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		a.log.Warn("Failed to write audit log", "error", err)
+	}
+}
+
+// Close closes audit log file.
+func (a *Log) Close() error {
+	if a.file == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
+
+func appendNonEmpty(attrs []any, key, value string) []any {
+	if value == "" {
+		return attrs
+	}
+	return append(attrs, key, value)
+}
@@ -0,0 +1,2 @@
+// Package audit writes execution lifecycle events as a structured audit trail.
+package audit
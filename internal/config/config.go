@@ -3,12 +3,20 @@ package config
 import (
 	"fmt"
 	"net"
+	"net/netip"
+	"os"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/caarlos0/env/v11"
 )
 
+// WebAppPath is the HTTP path prefix of Mini App form pages.
+const WebAppPath = "/webapp/"
+
+var webhookPathSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,128}$`)
+
 // Config describes runtime configuration for telegram-executor.
 type Config struct {
 	// ServiceName is a human-friendly service name for logs.
@@ -17,32 +25,217 @@ type Config struct {
 	HTTPHost string `env:"TG_EXECUTOR_HTTP_HOST,required"`
 	// HTTPPort is the HTTP listen port.
 	HTTPPort int `env:"TG_EXECUTOR_HTTP_PORT" envDefault:"8080"`
+	// AdminHTTPPort moves admin endpoints (/metrics, /debug/pprof, /executions, /events, /usage, /ui,
+	// /test-prompt) to a second listener on this port (0 keeps them on HTTPPort).
+	AdminHTTPPort int `env:"TG_EXECUTOR_ADMIN_HTTP_PORT"`
+	// AdminHTTPHost is the admin listen host (HTTPHost by default).
+	AdminHTTPHost string `env:"TG_EXECUTOR_ADMIN_HTTP_HOST"`
+	// AdminAllowedNetworks limits the admin listener to clients from these CIDRs (empty allows any client).
+	AdminAllowedNetworks []netip.Prefix `env:"TG_EXECUTOR_ADMIN_ALLOWED_NETWORKS" envSeparator:","`
+	// CORSAllowedOrigins enables CORS for browser tools served from these origins ("*" allows any origin without credentials).
+	CORSAllowedOrigins []string `env:"TG_EXECUTOR_CORS_ALLOWED_ORIGINS" envSeparator:","`
+	// CORSAllowedHeaders lists request headers allowed in cross-origin calls.
+	CORSAllowedHeaders []string `env:"TG_EXECUTOR_CORS_ALLOWED_HEADERS" envSeparator:"," envDefault:"Authorization,Content-Type,Content-Encoding,X-API-Key,X-Request-ID"`
+	// CORSAllowedMethods lists methods allowed in cross-origin calls.
+	CORSAllowedMethods []string `env:"TG_EXECUTOR_CORS_ALLOWED_METHODS" envSeparator:"," envDefault:"GET,POST,DELETE"`
 	// LogLevel controls log verbosity (debug, info, warn, error).
 	LogLevel string `env:"TG_EXECUTOR_LOG_LEVEL" envDefault:"info"`
-	// Lang selects i18n language (en or ru).
+	// AccessLog logs HTTP requests with status, duration, size, tenant and request id.
+	AccessLog bool `env:"TG_EXECUTOR_ACCESS_LOG" envDefault:"true"`
+	// AccessLogSampleRate is the share of successful requests logged; failed ones are always logged.
+	AccessLogSampleRate float64 `env:"TG_EXECUTOR_ACCESS_LOG_SAMPLE_RATE" envDefault:"1"`
+	// Lang selects default i18n language (en, ru or a locale from I18nDir).
 	Lang string `env:"TG_EXECUTOR_LANG" envDefault:"en"`
+	// I18nDir contains additional or overriding <lang>.yaml locale files.
+	I18nDir string `env:"TG_EXECUTOR_I18N_DIR"`
+	// Timezone is the IANA zone used to display submission time and deadlines; /tz overrides it per chat.
+	Timezone string `env:"TG_EXECUTOR_TIMEZONE" envDefault:"UTC"`
 	// Token is the Telegram bot token.
-	Token string `env:"TG_EXECUTOR_TOKEN,required"`
+	Token string `env:"TG_EXECUTOR_TOKEN"`
+	// TokenFile holds the bot token instead of Token; the file is watched and a changed token is applied at runtime.
+	TokenFile string `env:"TG_EXECUTOR_TOKEN_FILE"`
+	// TokenReloadInterval is how often TokenFile is checked for a rotated token.
+	TokenReloadInterval time.Duration `env:"TG_EXECUTOR_TOKEN_RELOAD_INTERVAL" envDefault:"1m"`
+	// StandbyToken is a token of another bot that takes over new prompts after FailoverThreshold consecutive
+	// prompts failed with auth or rate limit errors of the primary bot.
+	StandbyToken string `env:"TG_EXECUTOR_STANDBY_TOKEN"`
+	// FailoverThreshold is the number of consecutive failed prompts that triggers the failover.
+	FailoverThreshold int `env:"TG_EXECUTOR_FAILOVER_THRESHOLD" envDefault:"3"`
+	// Simulate replaces Telegram with an in-memory client: updates are injected through /simulate endpoints, so
+	// end-to-end tests run without a bot. Never enable it in production.
+	Simulate bool `env:"TG_EXECUTOR_SIMULATE" envDefault:"false"`
 	// ChatID is the allowed Telegram chat ID.
 	ChatID int64 `env:"TG_EXECUTOR_CHAT_ID,required"`
 	// ExecutionTimeout is the maximum time to wait for user response.
 	ExecutionTimeout time.Duration `env:"TG_EXECUTOR_EXECUTION_TIMEOUT" envDefault:"1h"`
 	// TimeoutMessage overrides the timeout message appended to Telegram messages.
 	TimeoutMessage string `env:"TG_EXECUTOR_TIMEOUT_MESSAGE"`
+	// SendRetries is the number of retries of the prompt message on network errors, flood control and Bot API 5xx.
+	SendRetries int `env:"TG_EXECUTOR_SEND_RETRIES" envDefault:"2"`
+	// SendRetryBackoff is the delay before the first retry; it doubles with every attempt.
+	SendRetryBackoff time.Duration `env:"TG_EXECUTOR_SEND_RETRY_BACKOFF" envDefault:"1s"`
+	// OutboundChatRate is the number of Telegram calls per second allowed in a single chat (sends, edits, deletes).
+	OutboundChatRate float64 `env:"TG_EXECUTOR_OUTBOUND_CHAT_RATE" envDefault:"1"`
+	// OutboundChatBurst is the number of calls a chat may make at once before OutboundChatRate applies.
+	OutboundChatBurst int `env:"TG_EXECUTOR_OUTBOUND_CHAT_BURST" envDefault:"5"`
+	// OutboundGlobalRate is the number of chat-bound Telegram calls per second across all chats.
+	OutboundGlobalRate float64 `env:"TG_EXECUTOR_OUTBOUND_GLOBAL_RATE" envDefault:"30"`
 	// WebhookURL enables webhook mode when set with WebhookSecret.
 	WebhookURL string `env:"TG_EXECUTOR_WEBHOOK_URL"`
 	// WebhookSecret is the Telegram webhook secret token.
 	WebhookSecret string `env:"TG_EXECUTOR_WEBHOOK_SECRET"`
+	// WebhookPreviousSecret is still accepted for WebhookSecretGrace after startup while the webhook is
+	// re-registered with WebhookSecret, so deliveries in flight during a secret rotation are not rejected.
+	WebhookPreviousSecret string `env:"TG_EXECUTOR_WEBHOOK_PREVIOUS_SECRET"`
+	// WebhookSecretGrace is how long WebhookPreviousSecret is accepted.
+	WebhookSecretGrace time.Duration `env:"TG_EXECUTOR_WEBHOOK_SECRET_GRACE" envDefault:"10m"`
+	// WebhookPathSecret moves the webhook endpoint to /webhook/<secret> and appends it to WebhookURL on
+	// registration, so guessing the endpoint is required on top of the header secret.
+	WebhookPathSecret string `env:"TG_EXECUTOR_WEBHOOK_PATH_SECRET"`
+	// STTProvider selects speech-to-text backend (openai, whisper-server, google, azure, deepgram).
+	STTProvider string `env:"TG_EXECUTOR_STT_PROVIDER" envDefault:"openai"`
 	// OpenAIAPIKey enables voice transcription.
 	OpenAIAPIKey string `env:"TG_EXECUTOR_OPENAI_API_KEY"`
+	// OpenAIBaseURL points the OpenAI provider to an OpenAI-compatible endpoint (e.g. faster-whisper, LocalAI).
+	OpenAIBaseURL string `env:"TG_EXECUTOR_OPENAI_BASE_URL"`
 	// STTModel is the OpenAI model for transcription.
 	STTModel string `env:"TG_EXECUTOR_STT_MODEL" envDefault:"gpt-4o-mini-transcribe"`
-	// STTTimeout is the OpenAI transcription timeout.
+	// STTConcurrency is the number of voice messages transcribed in parallel.
+	STTConcurrency int `env:"TG_EXECUTOR_STT_CONCURRENCY" envDefault:"2"`
+	// STTQueueSize is the number of voice messages waiting for transcription before new ones are rejected.
+	STTQueueSize int `env:"TG_EXECUTOR_STT_QUEUE_SIZE" envDefault:"16"`
+	// STTLang is the transcription language ("auto" detects it); empty uses the sender's Telegram language.
+	STTLang string `env:"TG_EXECUTOR_STT_LANG"`
+	// STTTimeout is the transcription request timeout.
 	STTTimeout time.Duration `env:"TG_EXECUTOR_STT_TIMEOUT" envDefault:"30s"`
+	// AnswerNormalization maps custom answers onto spec.output_schema with an OpenAI chat model.
+	AnswerNormalization bool `env:"TG_EXECUTOR_ANSWER_NORMALIZATION" envDefault:"false"`
+	// NormalizeModel is the OpenAI chat model for answer normalization.
+	NormalizeModel string `env:"TG_EXECUTOR_NORMALIZE_MODEL" envDefault:"gpt-4o-mini"`
+	// NormalizeTimeout is the answer normalization request timeout.
+	NormalizeTimeout time.Duration `env:"TG_EXECUTOR_NORMALIZE_TIMEOUT" envDefault:"15s"`
+	// Whisper configures the whisper-server STT provider.
+	Whisper WhisperConfig `envPrefix:"TG_EXECUTOR_WHISPER_"`
+	// Google configures the google STT provider.
+	Google GoogleSTTConfig `envPrefix:"TG_EXECUTOR_GOOGLE_STT_"`
+	// Azure configures the azure STT provider.
+	Azure AzureSpeechConfig `envPrefix:"TG_EXECUTOR_AZURE_SPEECH_"`
+	// Deepgram configures the deepgram STT provider.
+	Deepgram DeepgramConfig `envPrefix:"TG_EXECUTOR_DEEPGRAM_"`
+	// Theme configures status and priority emoji.
+	Theme ThemeConfig `envPrefix:"TG_EXECUTOR_THEME_"`
+	// EditGracePeriod delays custom text answers so that message edits within it replace the answer (0 disables).
+	EditGracePeriod time.Duration `env:"TG_EXECUTOR_EDIT_GRACE_PERIOD" envDefault:"0s"`
+	// DocumentAnswerMaxSize caps .txt/.md/.log documents accepted as custom answers, in bytes.
+	DocumentAnswerMaxSize int64 `env:"TG_EXECUTOR_DOCUMENT_ANSWER_MAX_SIZE" envDefault:"262144"`
+	// VoiceMaxDuration rejects longer voice messages and audio files before downloading (0 disables).
+	VoiceMaxDuration time.Duration `env:"TG_EXECUTOR_VOICE_MAX_DURATION" envDefault:"5m"`
+	// VoiceMaxSize rejects larger voice messages and audio files in bytes (0 disables).
+	VoiceMaxSize int64 `env:"TG_EXECUTOR_VOICE_MAX_SIZE" envDefault:"10485760"`
+	// VoiceConfirmation asks user to confirm voice transcription before resolving execution.
+	VoiceConfirmation bool `env:"TG_EXECUTOR_VOICE_CONFIRMATION" envDefault:"true"`
+	// PinUrgent pins prompts with spec.priority urgent until they resolve (the bot needs the pin permission).
+	PinUrgent bool `env:"TG_EXECUTOR_PIN_URGENT" envDefault:"true"`
+	// FinalizeMode selects how resolved prompts are updated: edit rewrites the prompt, reply keeps it and answers in-thread.
+	FinalizeMode string `env:"TG_EXECUTOR_FINALIZE_MODE" envDefault:"edit"`
+	// OptionMatchThreshold is the minimal confidence to resolve free-form answers as a predefined option (0 disables, e.g. 0.8 to enable).
+	OptionMatchThreshold float64 `env:"TG_EXECUTOR_OPTION_MATCH_THRESHOLD" envDefault:"0"`
+	// DecisionsChatID is a "decisions log" chat or channel that receives a compact summary of every resolution (0 disables).
+	DecisionsChatID int64 `env:"TG_EXECUTOR_DECISIONS_CHAT_ID"`
+	// AdminChatID receives watchdog alerts about sustained Telegram API failures, callback failure streaks and
+	// stuck update processing (0 disables the watchdog).
+	AdminChatID int64 `env:"TG_EXECUTOR_ADMIN_CHAT_ID"`
+	// WatchdogInterval is how often the watchdog checks subsystems.
+	WatchdogInterval time.Duration `env:"TG_EXECUTOR_WATCHDOG_INTERVAL" envDefault:"30s"`
+	// WatchdogThreshold is the number of consecutive failed checks or callback deliveries that raises an alert.
+	WatchdogThreshold int `env:"TG_EXECUTOR_WATCHDOG_THRESHOLD" envDefault:"3"`
+	// AlertCooldown is the minimal delay between two alerts about the same subsystem.
+	AlertCooldown time.Duration `env:"TG_EXECUTOR_ALERT_COOLDOWN" envDefault:"15m"`
+	// StartupAnnouncement sends a "service started" message to the chat on startup.
+	StartupAnnouncement bool `env:"TG_EXECUTOR_STARTUP_ANNOUNCEMENT" envDefault:"false"`
+	// SentryDSN enables error reporting to Sentry when set.
+	SentryDSN string `env:"TG_EXECUTOR_SENTRY_DSN"`
+	// SentryEnvironment is the environment name attached to reported errors.
+	SentryEnvironment string `env:"TG_EXECUTOR_SENTRY_ENVIRONMENT" envDefault:"production"`
+	// TemplatesDir contains per-tool prompt templates (<tool>.<markdown|html>.tmpl).
+	TemplatesDir string `env:"TG_EXECUTOR_TEMPLATES_DIR"`
+	// StateFile persists chat preferences (e.g. /lang) as JSON when set; otherwise they are kept in memory.
+	StateFile string `env:"TG_EXECUTOR_STATE_FILE"`
+	// MetricLabels lists request label keys exported as pending executions gauge series (keep cardinality low).
+	MetricLabels []string `env:"TG_EXECUTOR_METRIC_LABELS" envSeparator:","`
+	// ToolsFile is a YAML file with per-tool default profiles (timeout, option limits, template, chat, approvers).
+	ToolsFile string `env:"TG_EXECUTOR_TOOLS_FILE"`
+	// TenantsFile is a YAML file mapping API keys to tenant chats and defaults; when set, API requests require a key.
+	TenantsFile string `env:"TG_EXECUTOR_TENANTS_FILE"`
+	// AuditLogFile appends lifecycle events as JSON lines to the file when set.
+	AuditLogFile string `env:"TG_EXECUTOR_AUDIT_LOG_FILE"`
+	// ResultRetention keeps resolved executions queryable via GET /executions/{id} for this long (0 disables).
+	ResultRetention time.Duration `env:"TG_EXECUTOR_RESULT_RETENTION" envDefault:"1h"`
+	// CallbackLegacyStatus reports every failure as status "error" in callbacks and the status endpoint instead of
+	// timeout, cancelled, shutdown or chat_unavailable.
+	CallbackLegacyStatus bool `env:"TG_EXECUTOR_CALLBACK_LEGACY_STATUS" envDefault:"false"`
+	// ResolvePendingOnShutdown resolves pending executions with status shutdown when the service stops, for
+	// deployments without a state file whose executions cannot survive a restart.
+	ResolvePendingOnShutdown bool `env:"TG_EXECUTOR_RESOLVE_PENDING_ON_SHUTDOWN" envDefault:"false"`
+	// UpdatesLagThreshold fails the updates_lag readiness check when no update was processed for this long while
+	// prompts are pending (0 disables).
+	UpdatesLagThreshold time.Duration `env:"TG_EXECUTOR_UPDATES_LAG_THRESHOLD" envDefault:"0s"`
+	// HealthCacheTTL is how long readiness probe results for Telegram API are cached.
+	HealthCacheTTL time.Duration `env:"TG_EXECUTOR_HEALTH_CACHE_TTL" envDefault:"30s"`
+	// WebAppURL is the public HTTPS base URL of the executor used for Mini App forms.
+	WebAppURL string `env:"TG_EXECUTOR_WEBAPP_URL"`
 	// ShutdownTimeout is the graceful shutdown timeout.
 	ShutdownTimeout time.Duration `env:"TG_EXECUTOR_SHUTDOWN_TIMEOUT" envDefault:"10s"`
 }
 
+// WhisperConfig describes a self-hosted whisper.cpp server.
+type WhisperConfig struct {
+	// URL is the server base URL, e.g. http://whisper:8080.
+	URL string `env:"URL"`
+}
+
+// GoogleSTTConfig describes Google Cloud Speech-to-Text access.
+type GoogleSTTConfig struct {
+	// APIKey is the Google Cloud API key.
+	APIKey string `env:"API_KEY"`
+	// Model is the recognition model.
+	Model string `env:"MODEL" envDefault:"latest_short"`
+}
+
+// AzureSpeechConfig describes Azure AI Speech access.
+type AzureSpeechConfig struct {
+	// Key is the Speech resource key.
+	Key string `env:"KEY"`
+	// Region is the Speech resource region, e.g. westeurope.
+	Region string `env:"REGION"`
+}
+
+// DeepgramConfig describes Deepgram access.
+type DeepgramConfig struct {
+	// APIKey is the Deepgram API key.
+	APIKey string `env:"API_KEY"`
+	// Model is the Deepgram model.
+	Model string `env:"MODEL" envDefault:"nova-2"`
+}
+
+// ThemeConfig describes emoji of resolution notes and prompt titles.
+type ThemeConfig struct {
+	// Success prefixes notes of answered executions.
+	Success string `env:"SUCCESS" envDefault:"✅"`
+	// Error prefixes notes of failed executions.
+	Error string `env:"ERROR" envDefault:"⚠️"`
+	// Timeout prefixes notes of timed out executions.
+	Timeout string `env:"TIMEOUT" envDefault:"⏱️"`
+	// PriorityLow replaces the title emoji of low priority prompts (empty keeps the localized one).
+	PriorityLow string `env:"PRIORITY_LOW"`
+	// PriorityNormal replaces the title emoji of normal priority prompts (empty keeps the localized one).
+	PriorityNormal string `env:"PRIORITY_NORMAL"`
+	// PriorityHigh replaces the title emoji of high priority prompts.
+	PriorityHigh string `env:"PRIORITY_HIGH" envDefault:"❗"`
+	// PriorityUrgent replaces the title emoji of urgent prompts.
+	PriorityUrgent string `env:"PRIORITY_URGENT" envDefault:"🚨"`
+}
+
 // Load parses configuration from environment variables.
 func Load() (Config, error) {
 	cfg, err := env.ParseAs[Config]()
@@ -50,35 +243,258 @@ func Load() (Config, error) {
 		return Config{}, err
 	}
 
+	if cfg.TokenFile != "" {
+		if cfg.Token, err = ReadTokenFile(cfg.TokenFile); err != nil {
+			return Config{}, err
+		}
+		if cfg.TokenReloadInterval <= 0 {
+			return Config{}, fmt.Errorf("token reload interval must be positive")
+		}
+	}
+	cfg.Token = strings.TrimSpace(cfg.Token)
+	if cfg.Token == "" && !cfg.Simulate {
+		return Config{}, fmt.Errorf("token or token file is required")
+	}
+	if cfg.Simulate && (cfg.WebhookEnabled() || cfg.StandbyToken != "" || cfg.TokenFile != "") {
+		return Config{}, fmt.Errorf("simulation mode does not support webhook, standby token or token file")
+	}
+	if cfg.ResolvePendingOnShutdown && cfg.StateFile != "" {
+		return Config{}, fmt.Errorf("resolve pending on shutdown cannot be combined with state file")
+	}
+	cfg.StandbyToken = strings.TrimSpace(cfg.StandbyToken)
+	if cfg.StandbyToken != "" && cfg.FailoverThreshold < 1 {
+		return Config{}, fmt.Errorf("failover threshold must be at least 1")
+	}
+	if cfg.StandbyToken != "" && cfg.StandbyToken == cfg.Token {
+		return Config{}, fmt.Errorf("standby token must differ from token")
+	}
+
 	cfg.Lang = strings.ToLower(strings.TrimSpace(cfg.Lang))
 	if cfg.Lang == "" {
 		cfg.Lang = "en"
 	}
 
+	cfg.MetricLabels = trimList(cfg.MetricLabels)
+
+	cfg.CORSAllowedOrigins = trimList(cfg.CORSAllowedOrigins)
+	for idx, origin := range cfg.CORSAllowedOrigins {
+		// Browsers send origins without a trailing slash.
+		origin = strings.TrimRight(origin, "/")
+		cfg.CORSAllowedOrigins[idx] = origin
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			return Config{}, fmt.Errorf("cors origin %q must be \"*\" or an http(s) origin", origin)
+		}
+	}
+	cfg.CORSAllowedHeaders = trimList(cfg.CORSAllowedHeaders)
+	cfg.CORSAllowedMethods = trimList(cfg.CORSAllowedMethods)
+	for idx, method := range cfg.CORSAllowedMethods {
+		cfg.CORSAllowedMethods[idx] = strings.ToUpper(method)
+	}
+
+	cfg.Timezone = strings.TrimSpace(cfg.Timezone)
+	if cfg.Timezone == "" {
+		cfg.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+		return Config{}, fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+	}
+
 	if cfg.ExecutionTimeout <= 0 {
 		return Config{}, fmt.Errorf("execution timeout must be positive")
 	}
 
+	if cfg.SendRetries < 0 || cfg.SendRetryBackoff < 0 {
+		return Config{}, fmt.Errorf("send retries and backoff must not be negative")
+	}
+
+	if cfg.OutboundChatRate <= 0 || cfg.OutboundGlobalRate <= 0 || cfg.OutboundChatBurst < 1 {
+		return Config{}, fmt.Errorf("outbound rates must be positive and burst at least 1")
+	}
+
+	if cfg.AdminChatID != 0 && (cfg.WatchdogInterval <= 0 || cfg.WatchdogThreshold < 1 || cfg.AlertCooldown < 0) {
+		return Config{}, fmt.Errorf("watchdog interval and threshold must be positive")
+	}
+
+	if cfg.ResultRetention < 0 {
+		return Config{}, fmt.Errorf("result retention must not be negative")
+	}
+
+	if cfg.AccessLogSampleRate < 0 || cfg.AccessLogSampleRate > 1 {
+		return Config{}, fmt.Errorf("access log sample rate must be between 0 and 1")
+	}
+
+	if cfg.UpdatesLagThreshold < 0 {
+		return Config{}, fmt.Errorf("updates lag threshold must not be negative")
+	}
+
+	if cfg.EditGracePeriod < 0 {
+		return Config{}, fmt.Errorf("edit grace period must not be negative")
+	}
+
+	if cfg.VoiceMaxDuration < 0 || cfg.VoiceMaxSize < 0 {
+		return Config{}, fmt.Errorf("voice limits must not be negative")
+	}
+
+	if cfg.OptionMatchThreshold < 0 || cfg.OptionMatchThreshold > 1 {
+		return Config{}, fmt.Errorf("option match threshold must be between 0 and 1")
+	}
+
+	cfg.FinalizeMode = strings.ToLower(strings.TrimSpace(cfg.FinalizeMode))
+	switch cfg.FinalizeMode {
+	case "":
+		cfg.FinalizeMode = "edit"
+	case "edit", "reply":
+	default:
+		return Config{}, fmt.Errorf("unknown finalize mode %q", cfg.FinalizeMode)
+	}
+
+	if cfg.DocumentAnswerMaxSize <= 0 {
+		return Config{}, fmt.Errorf("document answer max size must be positive")
+	}
+
 	if strings.TrimSpace(cfg.HTTPHost) == "" {
 		return Config{}, fmt.Errorf("http host is required")
 	}
 	if cfg.HTTPPort < 1 || cfg.HTTPPort > 65535 {
 		return Config{}, fmt.Errorf("http port must be between 1 and 65535")
 	}
+	if cfg.AdminHTTPPort != 0 {
+		if cfg.AdminHTTPPort < 1 || cfg.AdminHTTPPort > 65535 || cfg.AdminHTTPPort == cfg.HTTPPort {
+			return Config{}, fmt.Errorf("admin http port must be between 1 and 65535 and differ from http port")
+		}
+		if strings.TrimSpace(cfg.AdminHTTPHost) == "" {
+			cfg.AdminHTTPHost = cfg.HTTPHost
+		}
+	} else if len(cfg.AdminAllowedNetworks) > 0 {
+		return Config{}, fmt.Errorf("admin allowed networks require admin http port")
+	}
 
 	if (cfg.WebhookURL == "") != (cfg.WebhookSecret == "") {
 		return Config{}, fmt.Errorf("webhook url and secret must be set together")
 	}
+	if cfg.WebhookPathSecret != "" && !webhookPathSecretPattern.MatchString(cfg.WebhookPathSecret) {
+		return Config{}, fmt.Errorf("webhook path secret must be 16-128 letters, digits, '-' or '_'")
+	}
+	if cfg.WebhookPreviousSecret != "" && (cfg.WebhookSecret == "" || cfg.WebhookSecretGrace <= 0) {
+		return Config{}, fmt.Errorf("webhook previous secret requires webhook secret and a positive grace period")
+	}
+
+	if err := validateSTT(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.AnswerNormalization && cfg.OpenAIAPIKey == "" {
+		return Config{}, fmt.Errorf("answer normalization requires openai api key")
+	}
+	if cfg.AnswerNormalization && cfg.NormalizeTimeout <= 0 {
+		return Config{}, fmt.Errorf("normalize timeout must be positive")
+	}
+
+	cfg.WebAppURL = strings.TrimRight(strings.TrimSpace(cfg.WebAppURL), "/")
+	if cfg.WebAppURL != "" && !strings.HasPrefix(cfg.WebAppURL, "https://") {
+		return Config{}, fmt.Errorf("webapp url must use https")
+	}
 
 	return cfg, nil
 }
 
+// trimList trims items of a comma-separated list and drops empty ones.
+func trimList(items []string) []string {
+	out := items[:0]
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// ReadTokenFile reads a bot token from the file, ignoring surrounding whitespace.
+func ReadTokenFile(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(raw))
+	if token == "" {
+		return "", fmt.Errorf("token file %q is empty", path)
+	}
+	return token, nil
+}
+
+func validateSTT(cfg *Config) error {
+	cfg.STTProvider = strings.ToLower(strings.TrimSpace(cfg.STTProvider))
+	switch cfg.STTProvider {
+	case "", "openai":
+		cfg.STTProvider = "openai"
+		cfg.OpenAIBaseURL = strings.TrimSpace(cfg.OpenAIBaseURL)
+		if cfg.OpenAIBaseURL != "" && !strings.HasPrefix(cfg.OpenAIBaseURL, "http://") && !strings.HasPrefix(cfg.OpenAIBaseURL, "https://") {
+			return fmt.Errorf("openai base url must be an http(s) url")
+		}
+	case "whisper-server":
+		cfg.Whisper.URL = strings.TrimRight(strings.TrimSpace(cfg.Whisper.URL), "/")
+		if cfg.Whisper.URL == "" {
+			return fmt.Errorf("whisper url is required for whisper-server stt provider")
+		}
+	case "google":
+		if cfg.Google.APIKey == "" {
+			return fmt.Errorf("google stt api key is required for google stt provider")
+		}
+	case "azure":
+		if cfg.Azure.Key == "" || cfg.Azure.Region == "" {
+			return fmt.Errorf("azure speech key and region are required for azure stt provider")
+		}
+	case "deepgram":
+		if cfg.Deepgram.APIKey == "" {
+			return fmt.Errorf("deepgram api key is required for deepgram stt provider")
+		}
+	default:
+		return fmt.Errorf("unknown stt provider %q", cfg.STTProvider)
+	}
+	cfg.STTLang = strings.ToLower(strings.TrimSpace(cfg.STTLang))
+	if cfg.STTTimeout <= 0 {
+		return fmt.Errorf("stt timeout must be positive")
+	}
+	if cfg.STTConcurrency < 1 || cfg.STTQueueSize < 0 {
+		return fmt.Errorf("stt concurrency must be positive and queue size must not be negative")
+	}
+	return nil
+}
+
 // HTTPAddr returns a listen address for the HTTP server.
 func (c Config) HTTPAddr() string {
 	return net.JoinHostPort(strings.TrimSpace(c.HTTPHost), fmt.Sprintf("%d", c.HTTPPort))
 }
 
+// AdminEnabled reports whether admin endpoints are served on a separate listener.
+func (c Config) AdminEnabled() bool {
+	return c.AdminHTTPPort != 0
+}
+
+// AdminRestricted reports whether admin endpoints are served on a separate listener limited to allowed networks.
+func (c Config) AdminRestricted() bool {
+	return c.AdminEnabled() && len(c.AdminAllowedNetworks) > 0
+}
+
+// AdminHTTPAddr returns a listen address for the admin HTTP server.
+func (c Config) AdminHTTPAddr() string {
+	return net.JoinHostPort(strings.TrimSpace(c.AdminHTTPHost), fmt.Sprintf("%d", c.AdminHTTPPort))
+}
+
 // WebhookEnabled reports whether webhook mode is configured.
 func (c Config) WebhookEnabled() bool {
 	return c.WebhookURL != "" && c.WebhookSecret != ""
 }
+
+// WebhookPath returns the HTTP path of the webhook endpoint.
+func (c Config) WebhookPath() string {
+	if c.WebhookPathSecret == "" {
+		return "/webhook"
+	}
+	return "/webhook/" + c.WebhookPathSecret
+}
+
+// WebAppFormURL returns public URL of Mini App form page for the token.
+func (c Config) WebAppFormURL(token string) string {
+	return c.WebAppURL + WebAppPath + token
+}
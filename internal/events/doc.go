@@ -0,0 +1,2 @@
+// Package events provides an in-process bus for execution lifecycle events.
+package events
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type identifies lifecycle event kind.
+type Type string
+
+const (
+	// TypeExecutionSubmitted is emitted when /execute request is accepted into the registry.
+	TypeExecutionSubmitted Type = "execution_submitted"
+	// TypePromptSent is emitted when prompt message is delivered to Telegram.
+	TypePromptSent Type = "prompt_sent"
+	// TypePromptBumped is emitted when a pending prompt is re-sent at the bottom of the chat.
+	TypePromptBumped Type = "prompt_bumped"
+	// TypeOptionSelected is emitted when user presses a predefined option button.
+	TypeOptionSelected Type = "option_selected"
+	// TypeCustomAnswer is emitted when user resolves execution with custom text or voice.
+	TypeCustomAnswer Type = "custom_answer"
+	// TypeTimedOut is emitted when execution times out without answer.
+	TypeTimedOut Type = "timed_out"
+	// TypeCancelled is emitted when execution is cancelled together with its group.
+	TypeCancelled Type = "cancelled"
+	// TypeChatUnavailable is emitted when execution fails because the bot lost access to its chat.
+	TypeChatUnavailable Type = "chat_unavailable"
+	// TypeShutdown is emitted when execution is dropped because the service stopped without persisted state.
+	TypeShutdown Type = "shutdown"
+	// TypeFinalizeFallback is emitted when the resolved prompt could not be edited and its result was sent as a new message.
+	TypeFinalizeFallback Type = "finalize_fallback"
+	// TypeCallbackDelivered is emitted when callback webhook is accepted by upstream.
+	TypeCallbackDelivered Type = "callback_delivered"
+	// TypeCallbackFailed is emitted when callback webhook delivery fails.
+	TypeCallbackFailed Type = "callback_failed"
+)
+
+// Event describes a single execution lifecycle change.
+type Event struct {
+	// Type is the event kind.
+	Type Type `json:"type"`
+	// Time is the moment event happened.
+	Time time.Time `json:"time"`
+	// CorrelationID links event to execution.
+	CorrelationID string `json:"correlation_id"`
+	// Tool is the tool name of execution.
+	Tool string `json:"tool,omitempty"`
+	// MessageID is the Telegram prompt message id.
+	MessageID int `json:"message_id,omitempty"`
+	// Answer is the selected option or custom answer text.
+	Answer string `json:"answer,omitempty"`
+	// OptionIndex is the selected option index (option_selected only).
+	OptionIndex *int `json:"option_index,omitempty"`
+	// InputMode is button, text or voice.
+	InputMode string `json:"input_mode,omitempty"`
+	// Status is the result status sent in callback.
+	Status string `json:"status,omitempty"`
+	// Error describes failure for failed events.
+	Error string `json:"error,omitempty"`
+	// ElapsedSec is the time since execution submission in seconds.
+	ElapsedSec float64 `json:"elapsed_sec,omitempty"`
+}
+
+// Handler consumes events. Handlers are called synchronously and must not block.
+type Handler func(Event)
+
+// Bus fans out events to subscribers.
+type Bus struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]Handler
+}
+
+// NewBus creates an empty event bus.
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[int]Handler)}
+}
+
+// Subscribe registers handler and returns a function that removes it.
+func (b *Bus) Subscribe(handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = handler
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+// Emit publishes event to all subscribers. Emitting on nil bus is a no-op.
+func (b *Bus) Emit(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, handler := range b.subscribers {
+		handler(event)
+	}
+}
+
+// New creates event for execution with elapsed time measured from submission.
+func New(eventType Type, correlationID, tool string, submittedAt time.Time) Event {
+	now := time.Now()
+	event := Event{Type: eventType, Time: now, CorrelationID: correlationID, Tool: tool}
+	if !submittedAt.IsZero() {
+		event.ElapsedSec = now.Sub(submittedAt).Seconds()
+	}
+	return event
+}
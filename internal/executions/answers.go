@@ -0,0 +1,42 @@
+package executions
+
+import "time"
+
+// answerRecordTTL is how long the author of an answer is remembered for callbacks that lost the race.
+const answerRecordTTL = 10 * time.Minute
+
+// AnswerRecord describes who resolved an execution and with which answer.
+type AnswerRecord struct {
+	// By is the display name of the user who answered ("@username" or the full name).
+	By string
+	// Answer is the selected option or custom answer text.
+	Answer string
+	// At is the moment the answer was recorded.
+	At time.Time
+}
+
+// RecordAnswer remembers who resolved the execution; records older than answerRecordTTL are dropped.
+func (r *Registry) RecordAnswer(correlationID string, record AnswerRecord) {
+	if record.At.IsZero() {
+		record.At = time.Now()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, existing := range r.answers {
+		if record.At.Sub(existing.At) > answerRecordTTL {
+			delete(r.answers, id)
+		}
+	}
+	r.answers[correlationID] = record
+}
+
+// Answer returns the recorded answer of a recently resolved execution.
+func (r *Registry) Answer(correlationID string) (AnswerRecord, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, ok := r.answers[correlationID]
+	if !ok || time.Since(record.At) > answerRecordTTL {
+		return AnswerRecord{}, false
+	}
+	return record, true
+}
@@ -1,9 +1,16 @@
 package executions
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/mymmrac/telego"
 )
 
 // Status describes execution status.
@@ -16,12 +23,85 @@ const (
 	StatusError Status = "error"
 	// StatusPending means execution is queued for async completion.
 	StatusPending Status = "pending"
+	// StatusTimeout means nobody answered before the deadline.
+	StatusTimeout Status = "timeout"
+	// StatusCancelled means execution was cancelled through the API or with its group.
+	StatusCancelled Status = "cancelled"
+	// StatusShutdown means execution was dropped because the service stopped without persisted state.
+	StatusShutdown Status = "shutdown"
+	// StatusChatUnavailable means the bot lost access to the chat of the execution.
+	StatusChatUnavailable Status = "chat_unavailable"
+)
+
+// Failed reports whether the status is one of the failure statuses.
+func (s Status) Failed() bool {
+	switch s {
+	case StatusError, StatusTimeout, StatusCancelled, StatusShutdown, StatusChatUnavailable:
+		return true
+	}
+	return false
+}
+
+// Legacy maps failure statuses to StatusError for callback consumers predating distinct failure statuses.
+func (s Status) Legacy() Status {
+	if s.Failed() {
+		return StatusError
+	}
+	return s
+}
+
+// Markup values accepted in requests.
+const (
+	// MarkupMarkdown renders prompts with Telegram MarkdownV2.
+	MarkupMarkdown = "markdown"
+	// MarkupMarkdownV2 is an explicit alias of MarkupMarkdown.
+	MarkupMarkdownV2 = "markdown_v2"
+	// MarkupMarkdownV1 renders prompts with legacy Telegram Markdown.
+	MarkupMarkdownV1 = "markdown_v1"
+	// MarkupHTML renders prompts with Telegram HTML.
+	MarkupHTML = "html"
+	// MarkupEntities renders plain text with explicit message entities (no escaping).
+	MarkupEntities = "entities"
 )
 
+// STTLangAuto lets the speech-to-text backend detect the spoken language.
+const STTLangAuto = "auto"
+
+// Intermediate callback events sent before the final resolution when listed in Callback.Events.
+const (
+	// CallbackEventPromptSent is sent once the prompt is posted to the chat.
+	CallbackEventPromptSent = "prompt_sent"
+	// CallbackEventCustomInputStarted is sent when a chat member starts composing a custom answer.
+	CallbackEventCustomInputStarted = "custom_input_started"
+	// CallbackEventReminderSent is sent when the pending prompt is re-sent at the bottom of the chat.
+	CallbackEventReminderSent = "reminder_sent"
+	// CallbackEventTimeoutWarning is sent when 80% of the timeout elapsed without an answer.
+	CallbackEventTimeoutWarning = "timeout_warning"
+)
+
+// CallbackEvents lists supported intermediate callback events.
+var CallbackEvents = []string{CallbackEventPromptSent, CallbackEventCustomInputStarted, CallbackEventReminderSent, CallbackEventTimeoutWarning}
+
 // Callback defines async callback settings.
 type Callback struct {
 	// URL is the webhook callback URL.
 	URL string `json:"url"`
+	// Events lists intermediate state changes posted to URL before the final result; empty sends only the result.
+	Events []string `json:"events,omitempty"`
+	// AcceptEncoding lists body encodings the receiver accepts like the Accept-Encoding header ("gzip, deflate");
+	// it is normalized to the encoding used, CallbackEncodingGzip or CallbackEncodingDeflate, or empty.
+	AcceptEncoding string `json:"accept_encoding,omitempty"`
+}
+
+// Callback body encodings.
+const (
+	CallbackEncodingGzip    = "gzip"
+	CallbackEncodingDeflate = "deflate"
+)
+
+// Notifies reports whether the intermediate event is requested.
+func (c Callback) Notifies(event string) bool {
+	return strings.TrimSpace(c.URL) != "" && slices.Contains(c.Events, event)
 }
 
 // Tool describes tool metadata from yaml-mcp-server.
@@ -35,6 +115,134 @@ type Tool struct {
 	Tags         []string       `json:"tags,omitempty"`
 }
 
+// Prompt section identifiers used by RenderProfile.
+const (
+	// SectionQuestion renders the question.
+	SectionQuestion = "question"
+	// SectionContext renders the optional context.
+	SectionContext = "context"
+	// SectionOptions renders numbered options.
+	SectionOptions = "options"
+	// SectionTool renders tool title, description and tags.
+	SectionTool = "tool"
+	// SectionParams renders raw arguments as JSON.
+	SectionParams = "params"
+	// SectionAction renders tool name and correlation id.
+	SectionAction = "action"
+)
+
+// DefaultSections is the prompt layout used when spec does not override it.
+var DefaultSections = []string{SectionQuestion, SectionContext, SectionOptions, SectionAction}
+
+// RenderProfile controls which prompt sections are shown and how.
+type RenderProfile struct {
+	// Sections lists prompt sections in display order.
+	Sections []string
+	// HideEmoji strips leading emoji from title and section headers.
+	HideEmoji bool
+	// TitleEmoji replaces the title emoji when set.
+	TitleEmoji string
+	// CollapseParams hides params section behind a "Show details" button.
+	CollapseParams bool
+	// AttachDiffs sends diff-like arguments as .diff documents.
+	AttachDiffs bool
+	// MarkdownInput renders common Markdown in question and context as formatting instead of escaping it.
+	MarkdownInput bool
+}
+
+// Options layouts accepted in spec.
+const (
+	// LayoutList renders one option per row.
+	LayoutList = "list"
+	// LayoutGrid renders options in several columns.
+	LayoutGrid = "grid"
+)
+
+// KeyboardLayout controls how option buttons are arranged.
+type KeyboardLayout struct {
+	// Columns is the number of option buttons per row.
+	Columns int
+	// OptionEmoji holds per-option label prefixes by option index.
+	OptionEmoji []string
+	// HideNumbers drops the "1. " numbering of option labels.
+	HideNumbers bool
+	// Hotkeys holds per-option texts that select the option when typed in the chat; custom keys replace numbers
+	// in labels.
+	Hotkeys []string
+}
+
+// Answer modes accepted in spec.
+const (
+	// AnswerModeButtons presents options as inline buttons under the prompt.
+	AnswerModeButtons = "buttons"
+	// AnswerModeReplyKeyboard presents options as a one-time reply keyboard matched by text.
+	AnswerModeReplyKeyboard = "reply_keyboard"
+	// AnswerModePoll presents options as a non-anonymous Telegram poll resolved by votes.
+	AnswerModePoll = "poll"
+)
+
+// Priorities accepted in spec.
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// Form field types accepted in spec.form.
+const (
+	// FieldText is a single-line text input.
+	FieldText = "text"
+	// FieldTextarea is a multi-line text input.
+	FieldTextarea = "textarea"
+	// FieldNumber is a numeric input.
+	FieldNumber = "number"
+	// FieldSelect is a dropdown with predefined options.
+	FieldSelect = "select"
+	// FieldDate is a date picker (YYYY-MM-DD).
+	FieldDate = "date"
+	// FieldCheckbox is a boolean toggle.
+	FieldCheckbox = "checkbox"
+)
+
+// FormField describes a single input of the Mini App form.
+type FormField struct {
+	Name     string   `json:"name"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options,omitempty"`
+	Required bool     `json:"required,omitempty"`
+}
+
+// Assignee directs the prompt to a Telegram user.
+type Assignee struct {
+	// Username is the Telegram username without "@".
+	Username string
+	// UserID mentions users without username; Name is the mention text then.
+	UserID int64
+	Name   string
+	// Exclusive rejects answers from other chat members.
+	Exclusive bool
+}
+
+// IsZero reports whether no assignee is set.
+func (a Assignee) IsZero() bool {
+	return a.Username == "" && a.UserID == 0
+}
+
+// Matches reports whether the Telegram user is the assignee.
+func (a Assignee) Matches(userID int64, username string) bool {
+	if a.UserID != 0 && a.UserID == userID {
+		return true
+	}
+	return a.Username != "" && strings.EqualFold(a.Username, username)
+}
+
+// CanAnswer reports whether the Telegram user may answer: anyone unless the assignee is exclusive.
+func (a Assignee) CanAnswer(userID int64, username string) bool {
+	return !a.Exclusive || a.IsZero() || a.Matches(userID, username)
+}
+
 // Request holds data required for execution.
 type Request struct {
 	CorrelationID string
@@ -46,54 +254,259 @@ type Request struct {
 	Options       []string
 	AllowCustom   bool
 	Lang          string
+	LangAuto      bool
+	STTLang       string
 	Markup        string
-	Callback      Callback
+	Render        RenderProfile
+	Keyboard      KeyboardLayout
+	AnswerMode    string
+	PollQuorum    int
+	Reactions     map[string]int
+	Form          []FormField
+	MultiMessage  bool
+	OutputSchema  map[string]any
+	Strings       map[string]string
+	Priority      string
+	// GroupID links related executions (one agent run) that are cancelled together.
+	GroupID string
+	// Labels are arbitrary key/value tags used to filter pending executions.
+	Labels map[string]string
+	// OptionNotes explain what selecting an option triggers; aligned with Options, may be shorter.
+	OptionNotes []string
+	// OptionValues carry value and metadata of structured options to the callback; aligned with Options.
+	OptionValues []OptionValue
+	// Assignee is mentioned in the prompt and, when exclusive, is the only user allowed to answer.
+	Assignee Assignee
+	// Approvers are usernames allowed to answer (tool profile); empty allows everyone.
+	Approvers []string
+	// Template names the message template used instead of the tool name.
+	Template string
+	// FullTexts hold question and context cut by spec.overflow "truncate", keyed by argument name;
+	// they are attached to the prompt as documents.
+	FullTexts map[string]string
+	// Tenant is the API key owner; CorrelationID is namespaced with it ("tenant/id").
+	Tenant string
+	// ChatID is the Telegram chat the prompt is sent to.
+	ChatID int64
+	// SubmittedAt and Deadline are shown in the prompt; they are set in the display timezone.
+	// A Deadline set by the caller is kept and overrides the execution timeout.
+	SubmittedAt time.Time
+	Deadline    time.Time
+	Callback    Callback
+}
+
+// CanAnswer reports whether the Telegram user may answer: the exclusive assignee restricts answers to them,
+// approvers restrict answers to the listed users (and the assignee).
+func (r Request) CanAnswer(userID int64, username string) bool {
+	if !r.Assignee.CanAnswer(userID, username) {
+		return false
+	}
+	if len(r.Approvers) == 0 || r.Assignee.Matches(userID, username) {
+		return true
+	}
+	return username != "" && slices.ContainsFunc(r.Approvers, func(approver string) bool {
+		return strings.EqualFold(approver, username)
+	})
+}
+
+// NamespacedID prefixes correlation id with tenant so tenants may reuse ids.
+func NamespacedID(tenant, correlationID string) string {
+	if tenant == "" {
+		return correlationID
+	}
+	return tenant + "/" + correlationID
+}
+
+// ClientCorrelationID returns correlation id as sent by the client, without tenant namespace.
+func (r Request) ClientCorrelationID() string {
+	if r.Tenant == "" {
+		return r.CorrelationID
+	}
+	return strings.TrimPrefix(r.CorrelationID, r.Tenant+"/")
+}
+
+// LogAttrs returns slog key/value pairs identifying the execution in log lines.
+func (r Request) LogAttrs() []any {
+	return []any{"correlation_id", r.CorrelationID, "tool", r.Tool.Name, "chat_id", r.ChatID}
+}
+
+// OptionValue is machine-readable data of a structured option ({label, value, metadata}).
+type OptionValue struct {
+	Value    any
+	Metadata map[string]any
+}
+
+// OptionValue returns data of the structured option; ok is false for plain string options.
+func (r Request) OptionValue(index int) (OptionValue, bool) {
+	if index < 0 || index >= len(r.OptionValues) {
+		return OptionValue{}, false
+	}
+	value := r.OptionValues[index]
+	return value, value.Value != nil || value.Metadata != nil
+}
+
+// OptionNote returns the note of the option or empty string.
+func (r Request) OptionNote(index int) string {
+	if index < 0 || index >= len(r.OptionNotes) {
+		return ""
+	}
+	return strings.TrimSpace(r.OptionNotes[index])
 }
 
 // Result represents the execution result.
 type Result struct {
 	Status Status
-	Output any
+	Output Output
 	Note   string
+	// CallbackNote is a machine-readable note added to the callback payload (e.g. "expired_during_downtime").
+	CallbackNote string
 }
 
 // Execution stores state for a single execution request.
 type Execution struct {
-	Request      Request
-	CreatedAt    time.Time
-	MessageID    int
-	MessageText  string
-	AwaitingText bool
+	Request         Request
+	CreatedAt       time.Time
+	MessageID       int
+	MessageText     string
+	MessageEntities []telego.MessageEntity
+	DetailsText     string
+	DetailsEntities []telego.MessageEntity
+	DetailsShown    bool
+	Prompt          *PromptState
+	HeldAnswer      *HeldAnswer
+	Transcription   *Transcription
+	PollID          string
+	PollMessageID   int
+	WebAppToken     string
+	// Pinned is set when the prompt was pinned in the chat (urgent priority).
+	Pinned    bool
+	pollVotes map[int64]int
+	// stopTimeout stops the timeout timer; Resolve calls it.
+	stopTimeout func()
+}
+
+// PromptState tracks custom-input prompt of a single execution.
+type PromptState struct {
+	// MessageID is the ForceReply prompt message id (0 until sent).
+	MessageID int
+	// StartedAt is when custom input was requested.
+	StartedAt time.Time
+	// Composer is the user composing the answer, shown on the execution message while input is pending.
+	Composer string
+	// Parts collects messages of a multi-message answer.
+	Parts []AnswerPart
+	// StatusMessageID is the message with collected parts count and Submit button.
+	StatusMessageID int
+}
+
+// AnswerPart is a single user message of a multi-message answer.
+type AnswerPart struct {
+	MessageID int
+	Text      string
+}
+
+// HeldAnswer is a custom text answer waiting out the edit grace period.
+type HeldAnswer struct {
+	// MessageID is the user message carrying the answer.
+	MessageID int
+	// Text is the latest (possibly edited) answer text.
+	Text string
+}
+
+// Transcription is a voice answer waiting for user confirmation.
+type Transcription struct {
+	// Text is the recognized answer.
+	Text string
+	// InputMode is the answer source (voice or audio).
+	InputMode string
+	// MessageID is the confirmation message with Use/Re-record/Edit buttons.
+	MessageID int
+}
+
+// DisplayText returns message text currently shown in Telegram.
+func (e *Execution) DisplayText() string {
+	if e.DetailsShown && e.DetailsText != "" {
+		return e.DetailsText
+	}
+	return e.MessageText
+}
+
+// DisplayEntities returns entities of message text currently shown in Telegram (entities markup only).
+func (e *Execution) DisplayEntities() []telego.MessageEntity {
+	if e.DetailsShown && e.DetailsText != "" {
+		return e.DetailsEntities
+	}
+	return e.MessageEntities
 }
 
 // Registry stores active execution requests.
 type Registry struct {
-	mu                sync.Mutex
-	executions        map[string]*Execution
-	promptMessageID   int
-	promptCorrelation string
+	mu         sync.Mutex
+	executions map[string]*Execution
+	polls      map[string]string
+	answers    map[string]AnswerRecord
+	results    map[string]StatusRecord
+	retention  time.Duration
 }
 
-// ErrAlreadyExists is returned when correlation id already exists.
-var ErrAlreadyExists = errors.New("execution already exists")
+var (
+	// ErrAlreadyExists is returned when correlation id already exists.
+	ErrAlreadyExists = errors.New("execution already exists")
+	// ErrPendingLimit rejects executions over the tenant max_pending quota.
+	ErrPendingLimit = errors.New("tenant pending prompts limit reached")
+)
 
 // NewRegistry creates a new execution registry.
 func NewRegistry() *Registry {
-	return &Registry{executions: make(map[string]*Execution)}
+	return &Registry{executions: make(map[string]*Execution), polls: make(map[string]string), answers: make(map[string]AnswerRecord), results: make(map[string]StatusRecord)}
 }
 
 // Add registers a new execution request.
 func (r *Registry) Add(req Request) (*Execution, error) {
+	return r.AddLimited(req, 0)
+}
+
+// AddLimited registers a new execution request unless its tenant already has maxPending pending
+// executions (0 is unlimited); the check and the add share the lock so concurrent submissions can't race past it.
+func (r *Registry) AddLimited(req Request, maxPending int) (*Execution, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if _, exists := r.executions[req.CorrelationID]; exists {
 		return nil, ErrAlreadyExists
 	}
+	if maxPending > 0 && r.countLocked(req.Tenant) >= maxPending {
+		return nil, ErrPendingLimit
+	}
 	exec := &Execution{Request: req, CreatedAt: time.Now()}
+	if len(req.Form) > 0 {
+		// Unguessable token keeps form pages private to the chat that received the button.
+		exec.WebAppToken = newToken()
+	}
 	r.executions[req.CorrelationID] = exec
 	return exec, nil
 }
 
+// FindByWebAppToken returns pending execution owning the Mini App form token.
+func (r *Registry) FindByWebAppToken(token string) *Execution {
+	if token == "" {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, exec := range r.executions {
+		if exec.WebAppToken == token {
+			return exec
+		}
+	}
+	return nil
+}
+
+func newToken() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
 // Get returns execution by correlation id.
 func (r *Registry) Get(correlationID string) *Execution {
 	r.mu.Lock()
@@ -102,76 +515,371 @@ func (r *Registry) Get(correlationID string) *Execution {
 }
 
 // SetMessage stores Telegram message metadata for execution.
-func (r *Registry) SetMessage(correlationID string, messageID int, messageText string) {
+func (r *Registry) SetMessage(correlationID string, messageID int, messageText string, entities []telego.MessageEntity) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if exec, ok := r.executions[correlationID]; ok {
 		exec.MessageID = messageID
 		exec.MessageText = messageText
+		exec.MessageEntities = entities
 	}
 }
 
-// StartCustomInput marks execution as waiting for custom text and returns previous prompt to delete.
-func (r *Registry) StartCustomInput(correlationID string) (int, bool) {
+// MoveMessage replaces the Telegram message id of a re-sent prompt and returns the previous one.
+func (r *Registry) MoveMessage(correlationID string, messageID int) (int, bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	exec, ok := r.executions[correlationID]
 	if !ok {
 		return 0, false
 	}
-	var previousPrompt int
-	if r.promptCorrelation != "" && r.promptCorrelation != correlationID {
-		if prevExec, exists := r.executions[r.promptCorrelation]; exists {
-			prevExec.AwaitingText = false
-		}
-		previousPrompt = r.promptMessageID
+	previous := exec.MessageID
+	exec.MessageID = messageID
+	return previous, true
+}
+
+// SetDetails stores expanded message text shown by the details toggle.
+func (r *Registry) SetDetails(correlationID, detailsText string, entities []telego.MessageEntity) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if exec, ok := r.executions[correlationID]; ok {
+		exec.DetailsText = detailsText
+		exec.DetailsEntities = entities
 	}
-	exec.AwaitingText = true
-	r.promptCorrelation = correlationID
-	r.promptMessageID = 0
+}
+
+// ToggleDetails flips details visibility of pending execution.
+func (r *Registry) ToggleDetails(correlationID string) (*Execution, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[correlationID]
+	if !ok || exec.DetailsText == "" {
+		return nil, false
+	}
+	exec.DetailsShown = !exec.DetailsShown
+	return exec, true
+}
+
+// StartCustomInput marks execution as waiting for custom text and returns its previous prompt to delete.
+// Other executions keep awaiting their own custom answers.
+func (r *Registry) StartCustomInput(correlationID, composer string) (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[correlationID]
+	if !ok {
+		return 0, false
+	}
+	previousPrompt := 0
+	if exec.Prompt != nil {
+		previousPrompt = exec.Prompt.MessageID
+	}
+	exec.Prompt = &PromptState{StartedAt: time.Now(), Composer: composer}
 	return previousPrompt, true
 }
 
-// SetPromptMessage stores active custom-input prompt message id.
+// SetPromptMessage stores custom-input prompt message id of execution.
 func (r *Registry) SetPromptMessage(correlationID string, messageID int) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if r.promptCorrelation == correlationID {
-		r.promptMessageID = messageID
+	if exec, ok := r.executions[correlationID]; ok && exec.Prompt != nil {
+		exec.Prompt.MessageID = messageID
 	}
 }
 
-// ClearPrompt removes active custom-input prompt if correlation id matches.
+// ClearPrompt stops waiting for custom input of execution and returns prompt message id to delete.
 func (r *Registry) ClearPrompt(correlationID string) int {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if r.promptCorrelation != correlationID {
+	exec, ok := r.executions[correlationID]
+	if !ok || exec.Prompt == nil {
 		return 0
 	}
-	if exec, ok := r.executions[correlationID]; ok {
-		exec.AwaitingText = false
-	}
-	removed := r.promptMessageID
-	r.promptMessageID = 0
-	r.promptCorrelation = ""
+	removed := exec.Prompt.MessageID
+	exec.Prompt = nil
 	return removed
 }
 
-// CurrentPrompt returns execution awaiting custom input and prompt message id.
-func (r *Registry) CurrentPrompt() (*Execution, int) {
+// HoldAnswer stores custom answer until the edit grace period passes.
+func (r *Registry) HoldAnswer(correlationID string, messageID int, text string) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if r.promptCorrelation == "" {
-		return nil, 0
+	exec, ok := r.executions[correlationID]
+	if !ok {
+		return false
+	}
+	exec.HeldAnswer = &HeldAnswer{MessageID: messageID, Text: text}
+	return true
+}
+
+// EditHeldAnswer replaces held answer text for the edited user message.
+func (r *Registry) EditHeldAnswer(chatID int64, messageID int, text string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, exec := range r.executions {
+		if exec.Request.ChatID == chatID && exec.HeldAnswer != nil && exec.HeldAnswer.MessageID == messageID {
+			exec.HeldAnswer.Text = text
+			return true
+		}
+	}
+	return false
+}
+
+// TakeHeldAnswer returns and clears held answer text of execution.
+func (r *Registry) TakeHeldAnswer(correlationID string, messageID int) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[correlationID]
+	if !ok || exec.HeldAnswer == nil || exec.HeldAnswer.MessageID != messageID {
+		return "", false
+	}
+	text := exec.HeldAnswer.Text
+	exec.HeldAnswer = nil
+	return text, true
+}
+
+// SetTranscription stores voice answer awaiting confirmation and returns previous confirmation message id.
+func (r *Registry) SetTranscription(correlationID, text, inputMode string, messageID int) (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[correlationID]
+	if !ok {
+		return 0, false
+	}
+	previous := 0
+	if exec.Transcription != nil {
+		previous = exec.Transcription.MessageID
+	}
+	exec.Transcription = &Transcription{Text: text, InputMode: inputMode, MessageID: messageID}
+	return previous, true
+}
+
+// TakeTranscription returns and clears voice answer awaiting confirmation.
+func (r *Registry) TakeTranscription(correlationID string) (Transcription, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[correlationID]
+	if !ok || exec.Transcription == nil {
+		return Transcription{}, false
+	}
+	transcription := *exec.Transcription
+	exec.Transcription = nil
+	return transcription, true
+}
+
+// FindByPrompt returns execution awaiting custom input for the prompt (or parts status) message id.
+func (r *Registry) FindByPrompt(chatID int64, messageID int) *Execution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, exec := range r.executions {
+		if exec.Request.ChatID == chatID && exec.Prompt != nil && (exec.Prompt.MessageID == messageID || exec.Prompt.StatusMessageID == messageID) {
+			return exec
+		}
+	}
+	return nil
+}
+
+// AppendAnswerPart adds message to multi-message answer and returns parts count and status message id.
+func (r *Registry) AppendAnswerPart(correlationID string, messageID int, text string) (int, int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[correlationID]
+	if !ok || exec.Prompt == nil {
+		return 0, 0, false
+	}
+	exec.Prompt.Parts = append(exec.Prompt.Parts, AnswerPart{MessageID: messageID, Text: text})
+	return len(exec.Prompt.Parts), exec.Prompt.StatusMessageID, true
+}
+
+// SetAnswerStatusMessage stores message showing multi-message answer progress.
+func (r *Registry) SetAnswerStatusMessage(correlationID string, messageID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if exec, ok := r.executions[correlationID]; ok && exec.Prompt != nil {
+		exec.Prompt.StatusMessageID = messageID
 	}
-	exec := r.executions[r.promptCorrelation]
-	if exec == nil || !exec.AwaitingText {
+}
+
+// EditAnswerPart replaces text of collected answer part for the edited user message.
+func (r *Registry) EditAnswerPart(chatID int64, messageID int, text string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, exec := range r.executions {
+		if exec.Request.ChatID != chatID || exec.Prompt == nil {
+			continue
+		}
+		for idx := range exec.Prompt.Parts {
+			if exec.Prompt.Parts[idx].MessageID == messageID {
+				exec.Prompt.Parts[idx].Text = text
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AnswerParts returns collected answer texts and status message id of execution.
+func (r *Registry) AnswerParts(correlationID string) ([]string, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[correlationID]
+	if !ok || exec.Prompt == nil {
 		return nil, 0
 	}
-	return exec, r.promptMessageID
+	texts := make([]string, 0, len(exec.Prompt.Parts))
+	for _, part := range exec.Prompt.Parts {
+		texts = append(texts, part.Text)
+	}
+	return texts, exec.Prompt.StatusMessageID
+}
+
+// AwaitingCustomInput returns executions awaiting custom input, most recently started first.
+func (r *Registry) AwaitingCustomInput(chatID int64) []*Execution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var awaiting []*Execution
+	for _, exec := range r.executions {
+		if exec.Request.ChatID == chatID && exec.Prompt != nil {
+			awaiting = append(awaiting, exec)
+		}
+	}
+	sort.Slice(awaiting, func(i, j int) bool {
+		return awaiting[i].Prompt.StartedAt.After(awaiting[j].Prompt.StartedAt)
+	})
+	return awaiting
 }
 
-// Resolve removes execution and clears prompt if needed.
+// SetPoll stores poll presenting execution options.
+func (r *Registry) SetPoll(correlationID, pollID string, messageID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if exec, ok := r.executions[correlationID]; ok {
+		exec.PollID = pollID
+		exec.PollMessageID = messageID
+		exec.pollVotes = make(map[int64]int)
+		r.polls[pollID] = correlationID
+	}
+}
+
+// SetPinned marks the prompt as pinned; it returns false when execution is already resolved.
+func (r *Registry) SetPinned(correlationID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[correlationID]
+	if ok {
+		exec.Pinned = true
+	}
+	return ok
+}
+
+// RecordPollAnswer registers user vote and returns execution with option index once it reaches quorum.
+// Empty optionIDs retract the previous vote.
+func (r *Registry) RecordPollAnswer(pollID string, userID int64, optionIDs []int) (*Execution, int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[r.polls[pollID]]
+	if !ok {
+		return nil, 0, false
+	}
+	if len(optionIDs) == 0 {
+		delete(exec.pollVotes, userID)
+		return exec, 0, false
+	}
+	option := optionIDs[0]
+	if option < 0 || option >= len(exec.Request.Options) {
+		return exec, 0, false
+	}
+	exec.pollVotes[userID] = option
+	votes := 0
+	for _, voted := range exec.pollVotes {
+		if voted == option {
+			votes++
+		}
+	}
+	return exec, option, votes >= max(exec.Request.PollQuorum, 1)
+}
+
+// FindByPoll returns pending execution by its poll id.
+func (r *Registry) FindByPoll(pollID string) *Execution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.executions[r.polls[pollID]]
+}
+
+// FindByMessage returns pending execution by its prompt message id.
+func (r *Registry) FindByMessage(chatID int64, messageID int) *Execution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, exec := range r.executions {
+		if exec.Request.ChatID == chatID && exec.MessageID == messageID {
+			return exec
+		}
+	}
+	return nil
+}
+
+// MigrateChat moves pending executions of the group chat to the supergroup it was upgraded to.
+func (r *Registry) MigrateChat(fromChatID, toChatID int64) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	moved := 0
+	for _, exec := range r.executions {
+		if exec.Request.ChatID == fromChatID {
+			exec.Request.ChatID = toChatID
+			moved++
+		}
+	}
+	return moved
+}
+
+// Latest returns the most recently sent pending execution with given answer mode.
+func (r *Registry) Latest(chatID int64, answerMode string) *Execution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *Execution
+	for _, exec := range r.executions {
+		if exec.Request.ChatID != chatID || exec.Request.AnswerMode != answerMode || exec.MessageID == 0 {
+			continue
+		}
+		if latest == nil || exec.CreatedAt.After(latest.CreatedAt) {
+			latest = exec
+		}
+	}
+	return latest
+}
+
+// LatestWithHotkeys returns the most recently created prompted execution of the chat with option hotkeys.
+func (r *Registry) LatestWithHotkeys(chatID int64) *Execution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *Execution
+	for _, exec := range r.executions {
+		if exec.Request.ChatID != chatID || len(exec.Request.Keyboard.Hotkeys) == 0 || exec.MessageID == 0 {
+			continue
+		}
+		if latest == nil || exec.CreatedAt.After(latest.CreatedAt) {
+			latest = exec
+		}
+	}
+	return latest
+}
+
+// SetTimeout keeps the function stopping the timeout timer of the pending execution, replacing (and calling) the
+// previous one; Resolve calls it. It reports false, without keeping stop, when the execution is not pending.
+func (r *Registry) SetTimeout(correlationID string, stop func()) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[correlationID]
+	if !ok {
+		return false
+	}
+	if exec.stopTimeout != nil {
+		exec.stopTimeout()
+	}
+	exec.stopTimeout = stop
+	return true
+}
+
+// Resolve removes execution, stops its timeout timer and clears prompt if needed.
 func (r *Registry) Resolve(correlationID string) (*Execution, int, bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -180,11 +888,155 @@ func (r *Registry) Resolve(correlationID string) (*Execution, int, bool) {
 		return nil, 0, false
 	}
 	delete(r.executions, correlationID)
+	if exec.PollID != "" {
+		delete(r.polls, exec.PollID)
+	}
+	if exec.stopTimeout != nil {
+		exec.stopTimeout()
+		exec.stopTimeout = nil
+	}
 	promptID := 0
-	if r.promptCorrelation == correlationID {
-		promptID = r.promptMessageID
-		r.promptMessageID = 0
-		r.promptCorrelation = ""
+	if exec.Prompt != nil {
+		promptID = exec.Prompt.MessageID
 	}
 	return exec, promptID, true
 }
+
+// Group returns correlation IDs of pending executions of the tenant group, oldest first.
+func (r *Registry) Group(tenant, groupID string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var members []*Execution
+	for _, exec := range r.executions {
+		if exec.Request.Tenant == tenant && exec.Request.GroupID == groupID {
+			members = append(members, exec)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].CreatedAt.Before(members[j].CreatedAt)
+	})
+	ids := make([]string, 0, len(members))
+	for _, exec := range members {
+		ids = append(ids, exec.Request.CorrelationID)
+	}
+	return ids
+}
+
+// Pending returns correlation IDs of all pending executions.
+func (r *Registry) Pending() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.executions))
+	for id := range r.executions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// InChat returns correlation IDs of pending executions posted to the chat.
+func (r *Registry) InChat(chatID int64) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []string
+	for id, exec := range r.executions {
+		if exec.Request.ChatID == chatID {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Count returns the number of pending executions of the tenant.
+func (r *Registry) Count(tenant string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.countLocked(tenant)
+}
+
+func (r *Registry) countLocked(tenant string) int {
+	count := 0
+	for _, exec := range r.executions {
+		if exec.Request.Tenant == tenant {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary describes a pending execution in listings.
+type Summary struct {
+	CorrelationID string            `json:"correlation_id"`
+	Tenant        string            `json:"tenant,omitempty"`
+	Tool          string            `json:"tool"`
+	Question      string            `json:"question"`
+	GroupID       string            `json:"group_id,omitempty"`
+	Priority      string            `json:"priority,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	MessageID     int               `json:"message_id,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// List returns pending executions having all selector labels, oldest first.
+func (r *Registry) List(selector map[string]string) []Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matched []*Execution
+	for _, exec := range r.executions {
+		if MatchLabels(exec.Request.Labels, selector) {
+			matched = append(matched, exec)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.Before(matched[j].CreatedAt)
+	})
+	out := make([]Summary, 0, len(matched))
+	for _, exec := range matched {
+		out = append(out, summaryOf(exec))
+	}
+	return out
+}
+
+func summaryOf(exec *Execution) Summary {
+	return Summary{
+		CorrelationID: exec.Request.ClientCorrelationID(),
+		Tenant:        exec.Request.Tenant,
+		Tool:          exec.Request.Tool.Name,
+		Question:      exec.Request.Question,
+		GroupID:       exec.Request.GroupID,
+		Priority:      exec.Request.Priority,
+		Labels:        exec.Request.Labels,
+		MessageID:     exec.MessageID,
+		CreatedAt:     exec.CreatedAt,
+	}
+}
+
+// MatchLabels reports whether labels contain every selector pair.
+func MatchLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if actual, ok := labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Stats describes pending executions snapshot.
+type Stats struct {
+	// Pending is the number of unresolved executions.
+	Pending int
+	// OldestCreatedAt is the creation time of the oldest pending execution.
+	OldestCreatedAt time.Time
+}
+
+// Stats returns pending executions snapshot.
+func (r *Registry) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := Stats{Pending: len(r.executions)}
+	for _, exec := range r.executions {
+		if stats.OldestCreatedAt.IsZero() || exec.CreatedAt.Before(stats.OldestCreatedAt) {
+			stats.OldestCreatedAt = exec.CreatedAt
+		}
+	}
+	return stats
+}
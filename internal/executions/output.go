@@ -0,0 +1,160 @@
+package executions
+
+import "encoding/json"
+
+// Output is the typed result of an execution: the "result" field of callbacks, the status endpoint and /execute
+// responses. Variants marshal to the JSON callbacks always carried, so consumers see no difference.
+type Output interface {
+	json.Marshaler
+	// String returns the human-readable output used in notes; it is empty for form submissions.
+	String() string
+}
+
+// OptionSelected is a predefined option chosen by button, poll, reply or a matching free-form answer.
+type OptionSelected struct {
+	Question  string
+	Option    string
+	Index     int
+	InputMode string
+	// Value and Metadata are data of structured options.
+	Value    any
+	Metadata map[string]any
+	// RawAnswer and MatchConfidence are set when a free-form answer was matched to the option.
+	RawAnswer       string
+	MatchConfidence float64
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o OptionSelected) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Question        string         `json:"question"`
+		SelectedOption  string         `json:"selected_option"`
+		SelectedIndex   int            `json:"selected_index"`
+		Custom          bool           `json:"custom"`
+		InputMode       string         `json:"input_mode"`
+		SelectedValue   any            `json:"selected_value,omitempty"`
+		SelectedMeta    map[string]any `json:"selected_metadata,omitempty"`
+		RawAnswer       string         `json:"raw_answer,omitempty"`
+		MatchConfidence *float64       `json:"match_confidence,omitempty"`
+	}{
+		Question:       o.Question,
+		SelectedOption: o.Option,
+		SelectedIndex:  o.Index,
+		InputMode:      o.InputMode,
+		SelectedValue:  o.Value,
+		SelectedMeta:   o.Metadata,
+		RawAnswer:      o.RawAnswer,
+	}
+	if o.RawAnswer != "" {
+		out.MatchConfidence = &o.MatchConfidence
+	}
+	return json.Marshal(out)
+}
+
+func (o OptionSelected) String() string { return o.Option }
+
+// CustomAnswer is a free-form answer that matched no option.
+type CustomAnswer struct {
+	Question  string
+	Answer    string
+	InputMode string
+	// Interpretation is the answer normalized to the output schema of the request.
+	Interpretation map[string]any
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o CustomAnswer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Question       string         `json:"question"`
+		SelectedOption string         `json:"selected_option"`
+		SelectedIndex  *int           `json:"selected_index"`
+		Custom         bool           `json:"custom"`
+		InputMode      string         `json:"input_mode"`
+		Interpretation map[string]any `json:"interpretation,omitempty"`
+	}{
+		Question:       o.Question,
+		SelectedOption: o.Answer,
+		Custom:         true,
+		InputMode:      o.InputMode,
+		Interpretation: o.Interpretation,
+	})
+}
+
+func (o CustomAnswer) String() string { return o.Answer }
+
+// FormSubmitted is a Mini App form submission.
+type FormSubmitted struct {
+	Question  string
+	Values    map[string]any
+	InputMode string
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o FormSubmitted) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Question       string         `json:"question"`
+		SelectedOption *string        `json:"selected_option"`
+		SelectedIndex  *int           `json:"selected_index"`
+		Custom         bool           `json:"custom"`
+		Form           map[string]any `json:"form"`
+		InputMode      string         `json:"input_mode"`
+	}{
+		Question:  o.Question,
+		Form:      o.Values,
+		InputMode: o.InputMode,
+	})
+}
+
+func (o FormSubmitted) String() string { return "" }
+
+// Timeout is the output of an execution nobody answered before the deadline.
+type Timeout struct{}
+
+// MarshalJSON implements json.Marshaler.
+func (o Timeout) MarshalJSON() ([]byte, error) { return json.Marshal(o.String()) }
+
+func (Timeout) String() string { return "execution timeout" }
+
+// Cancelled is the output of an execution cancelled through the API or with its group.
+type Cancelled struct{}
+
+// MarshalJSON implements json.Marshaler.
+func (o Cancelled) MarshalJSON() ([]byte, error) { return json.Marshal(o.String()) }
+
+func (Cancelled) String() string { return "execution cancelled" }
+
+// Shutdown is the output of an execution dropped when the service stopped without persisted state.
+type Shutdown struct{}
+
+// MarshalJSON implements json.Marshaler.
+func (o Shutdown) MarshalJSON() ([]byte, error) { return json.Marshal(o.String()) }
+
+func (Shutdown) String() string { return "service shutdown" }
+
+// ChatUnavailable is the output of an execution whose chat the bot lost access to.
+type ChatUnavailable struct {
+	Reason string
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o ChatUnavailable) MarshalJSON() ([]byte, error) { return json.Marshal(o.String()) }
+
+func (o ChatUnavailable) String() string { return "chat unavailable: " + o.Reason }
+
+// Queued is the output of an accepted execution waiting for an answer.
+type Queued struct{}
+
+// MarshalJSON implements json.Marshaler.
+func (o Queued) MarshalJSON() ([]byte, error) { return json.Marshal(o.String()) }
+
+func (Queued) String() string { return "queued" }
+
+// Error is the output of an execution that failed; Message is reported as is.
+type Error struct {
+	Message string
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o Error) MarshalJSON() ([]byte, error) { return json.Marshal(o.Message) }
+
+func (o Error) String() string { return o.Message }
@@ -0,0 +1,94 @@
+package executions
+
+import (
+	"sort"
+	"time"
+)
+
+// StatusRecord is the state of an execution reported by the status endpoint: pending, or resolved within retention.
+type StatusRecord struct {
+	Summary
+	// Status is pending until the execution resolves, then the callback status.
+	Status Status `json:"status"`
+	// Output is the callback result of a resolved execution.
+	Output Output `json:"output,omitempty"`
+	// Answer is the selected option or custom answer text.
+	Answer string `json:"answer,omitempty"`
+	// Responder is the user who answered ("@username" or the full name).
+	Responder string `json:"responder,omitempty"`
+	// ResolvedAt is set once the execution resolves.
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	// CallbackURL, CallbackEncoding and CallbackBody keep the callback delivery of a resolved execution for
+	// redelivery.
+	CallbackURL      string `json:"-"`
+	CallbackEncoding string `json:"-"`
+	CallbackBody     []byte `json:"-"`
+}
+
+// SetRetention sets how long resolved executions stay queryable with Status (0 disables retention).
+func (r *Registry) SetRetention(retention time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retention = retention
+}
+
+// Retain keeps the result and callback body of a resolved execution for the retention period, so upstream can
+// query it or have the callback redelivered after a missed delivery. It returns the record even when retention is off.
+func (r *Registry) Retain(exec *Execution, result Result, callbackBody []byte) StatusRecord {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record := StatusRecord{
+		Summary:          summaryOf(exec),
+		Status:           result.Status,
+		Output:           result.Output,
+		ResolvedAt:       &now,
+		CallbackURL:      exec.Request.Callback.URL,
+		CallbackEncoding: exec.Request.Callback.AcceptEncoding,
+		CallbackBody:     callbackBody,
+	}
+	if answer, ok := r.answers[exec.Request.CorrelationID]; ok {
+		record.Answer = answer.Answer
+		record.Responder = answer.By
+	}
+	if r.retention <= 0 {
+		return record
+	}
+	for id, retained := range r.results {
+		if now.Sub(*retained.ResolvedAt) > r.retention {
+			delete(r.results, id)
+		}
+	}
+	r.results[exec.Request.CorrelationID] = record
+	return record
+}
+
+// Status returns the pending execution or the retained result of a resolved one.
+func (r *Registry) Status(correlationID string) (StatusRecord, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if exec, ok := r.executions[correlationID]; ok {
+		return StatusRecord{Summary: summaryOf(exec), Status: StatusPending}, true
+	}
+	record, ok := r.results[correlationID]
+	if !ok || time.Since(*record.ResolvedAt) > r.retention {
+		return StatusRecord{}, false
+	}
+	return record, true
+}
+
+// Recent returns retained results of resolved executions, most recently resolved first.
+func (r *Registry) Recent() []StatusRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]StatusRecord, 0, len(r.results))
+	for _, record := range r.results {
+		if time.Since(*record.ResolvedAt) <= r.retention {
+			out = append(out, record)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ResolvedAt.After(*out[j].ResolvedAt)
+	})
+	return out
+}
@@ -0,0 +1,75 @@
+package executions
+
+import (
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+// Snapshot is the persisted state of a pending execution: enough to answer it and time it out after a restart.
+// Request.Deadline is absolute, so the remaining time does not depend on how long the service was down.
+type Snapshot struct {
+	Request         Request                `json:"request"`
+	CreatedAt       time.Time              `json:"created_at"`
+	MessageID       int                    `json:"message_id"`
+	MessageText     string                 `json:"message_text,omitempty"`
+	MessageEntities []telego.MessageEntity `json:"message_entities,omitempty"`
+	DetailsText     string                 `json:"details_text,omitempty"`
+	DetailsEntities []telego.MessageEntity `json:"details_entities,omitempty"`
+	PollID          string                 `json:"poll_id,omitempty"`
+	PollMessageID   int                    `json:"poll_message_id,omitempty"`
+	WebAppToken     string                 `json:"web_app_token,omitempty"`
+	Pinned          bool                   `json:"pinned,omitempty"`
+}
+
+// Snapshot returns the persisted state of the pending execution.
+func (r *Registry) Snapshot(correlationID string) (Snapshot, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec, ok := r.executions[correlationID]
+	if !ok {
+		return Snapshot{}, false
+	}
+	return Snapshot{
+		Request:         exec.Request,
+		CreatedAt:       exec.CreatedAt,
+		MessageID:       exec.MessageID,
+		MessageText:     exec.MessageText,
+		MessageEntities: exec.MessageEntities,
+		DetailsText:     exec.DetailsText,
+		DetailsEntities: exec.DetailsEntities,
+		PollID:          exec.PollID,
+		PollMessageID:   exec.PollMessageID,
+		WebAppToken:     exec.WebAppToken,
+		Pinned:          exec.Pinned,
+	}, true
+}
+
+// Restore registers a pending execution saved before restart; poll votes and pending custom input start over.
+func (r *Registry) Restore(snapshot Snapshot) (*Execution, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	correlationID := snapshot.Request.CorrelationID
+	if _, exists := r.executions[correlationID]; exists {
+		return nil, ErrAlreadyExists
+	}
+	exec := &Execution{
+		Request:         snapshot.Request,
+		CreatedAt:       snapshot.CreatedAt,
+		MessageID:       snapshot.MessageID,
+		MessageText:     snapshot.MessageText,
+		MessageEntities: snapshot.MessageEntities,
+		DetailsText:     snapshot.DetailsText,
+		DetailsEntities: snapshot.DetailsEntities,
+		PollID:          snapshot.PollID,
+		PollMessageID:   snapshot.PollMessageID,
+		WebAppToken:     snapshot.WebAppToken,
+		Pinned:          snapshot.Pinned,
+	}
+	if exec.PollID != "" {
+		exec.pollVotes = make(map[int64]int)
+		r.polls[exec.PollID] = correlationID
+	}
+	r.executions[correlationID] = exec
+	return exec, nil
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	applog "github.com/codex-k8s/telegram-executor/internal/log"
+)
+
+// maxRequestIDLength bounds client-provided X-Request-ID values.
+const maxRequestIDLength = 64
+
+type accessKey struct{}
+
+// accessEntry collects request details known only to handlers, such as the authenticated tenant.
+type accessEntry struct {
+	tenant string
+}
+
+// noteTenant records the tenant of the request for the access log.
+func noteTenant(r *http.Request, tenant string) {
+	if entry, ok := r.Context().Value(accessKey{}).(*accessEntry); ok {
+		entry.tenant = tenant
+	}
+}
+
+// SetAccessLog enables access logs: failed requests (status 400 and above) are always logged, others with
+// probability sampleRate. Call it before serving.
+func (s *Server) SetAccessLog(sampleRate float64) {
+	next := s.server.Handler
+	s.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		requestID := r.Header.Get("X-Request-ID")
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+		entry := &accessEntry{}
+		ctx := context.WithValue(applog.WithAttrs(r.Context(), "request_id", requestID), accessKey{}, entry)
+		r = r.WithContext(ctx)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		if recorder.status < http.StatusBadRequest && rand.Float64() >= sampleRate {
+			return
+		}
+		path := r.URL.Path
+		if shown, ok := s.redactedPatterns[r.Pattern]; ok {
+			path = shown
+		}
+		log := s.log.InfoContext
+		if recorder.status >= http.StatusInternalServerError {
+			log = s.log.WarnContext
+		}
+		log(ctx, "HTTP request",
+			"method", r.Method,
+			"path", path,
+			"status", recorder.status,
+			"duration_ms", time.Since(started).Milliseconds(),
+			"bytes", recorder.bytes,
+			"tenant", entry.tenant,
+		)
+	})
+}
+
+// RedactPattern logs requests routed to pattern with shown instead of their path, hiding secrets in paths.
+func (s *Server) RedactPattern(pattern, shown string) {
+	s.redactedPatterns[pattern] = shown
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, char := range id {
+		if char < '!' || char > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 8)
+	_, _ = crand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// statusRecorder captures status code and body size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+	wrote  bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wrote {
+		r.status, r.wrote = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	r.wrote = true
+	written, err := r.ResponseWriter.Write(data)
+	r.bytes += written
+	return written, err
+}
+
+// Flush keeps /events streaming through the recorder.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"net/netip"
+)
+
+// HandleProfiling registers net/http/pprof handlers under /debug/pprof/; only the admin listener serves them.
+func (s *Server) HandleProfiling() {
+	s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// RestrictNetworks rejects requests from clients outside the networks with 403; call it before serving.
+func (s *Server) RestrictNetworks(networks []netip.Prefix) {
+	if len(networks) == 0 {
+		return
+	}
+	next := s.server.Handler
+	s.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !clientAllowed(r.RemoteAddr, networks) {
+			s.log.Warn("Admin request from disallowed network", "remote_addr", r.RemoteAddr, "path", r.URL.Path)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func clientAllowed(remoteAddr string, networks []netip.Prefix) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, network := range networks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
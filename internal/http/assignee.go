@@ -0,0 +1,54 @@
+package http
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+// usernamePattern matches Telegram usernames (5-32 characters).
+var usernamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{4,31}$`)
+
+// parseAssignee reads spec.assignee:
+//
+//	assignee:
+//	  username: alice      # or "@alice"
+//	  user_id: 123456789   # for users without username
+//	  name: Alice          # mention text for user_id
+//	  exclusive: true      # only the assignee may answer
+func parseAssignee(spec map[string]any) (executions.Assignee, error) {
+	raw, ok := spec["assignee"]
+	if !ok || raw == nil {
+		return executions.Assignee{}, nil
+	}
+	data, ok := raw.(map[string]any)
+	if !ok {
+		return executions.Assignee{}, fmt.Errorf("spec.assignee must be object")
+	}
+	var assignee executions.Assignee
+	if username, ok := extractString(data, "username"); ok {
+		username = strings.TrimPrefix(username, "@")
+		if !usernamePattern.MatchString(username) {
+			return executions.Assignee{}, fmt.Errorf("spec.assignee.username must be a Telegram username")
+		}
+		assignee.Username = username
+	}
+	if _, ok := data["user_id"]; ok {
+		userID, ok := extractInt(data, "user_id")
+		if !ok || userID <= 0 {
+			return executions.Assignee{}, fmt.Errorf("spec.assignee.user_id must be a positive integer")
+		}
+		assignee.UserID = int64(userID)
+	}
+	if assignee.IsZero() {
+		return executions.Assignee{}, fmt.Errorf("spec.assignee requires username or user_id")
+	}
+	assignee.Name, _ = extractString(data, "name")
+	if assignee.Username == "" && assignee.Name == "" {
+		return executions.Assignee{}, fmt.Errorf("spec.assignee.name is required with user_id")
+	}
+	assignee.Exclusive, _ = extractBool(data, "exclusive")
+	return assignee, nil
+}
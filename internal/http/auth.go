@@ -0,0 +1,23 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+)
+
+// authenticate resolves tenant of the API request. When tenants are configured a valid key is required;
+// otherwise it writes 401 and returns false. Without tenants every request is allowed with an empty tenant.
+func authenticate(w http.ResponseWriter, r *http.Request, set *tenants.Set) (tenants.Tenant, bool) {
+	if !set.Enabled() {
+		return tenants.Tenant{}, true
+	}
+	tenant, ok := set.Authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="telegram-executor"`)
+		http.Error(w, "invalid or missing api key", http.StatusUnauthorized)
+		return tenants.Tenant{}, false
+	}
+	noteTenant(r, tenant.ID)
+	return tenant, true
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+// normalizeCallbackEvents trims and deduplicates callback.events and rejects unknown events.
+func normalizeCallbackEvents(events []string) ([]string, error) {
+	out := make([]string, 0, len(events))
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if !slices.Contains(executions.CallbackEvents, event) {
+			return nil, fmt.Errorf("callback.events: unknown event %q (supported: %s)", event, strings.Join(executions.CallbackEvents, ", "))
+		}
+		if !slices.Contains(out, event) {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
+// normalizeCallbackEncoding picks the first encoding of callback.accept_encoding the executor can produce; other
+// encodings are ignored and bodies are sent uncompressed when none is supported.
+func normalizeCallbackEncoding(acceptEncoding string) string {
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		encoding, _, _ := strings.Cut(item, ";")
+		switch encoding = strings.ToLower(strings.TrimSpace(encoding)); encoding {
+		case executions.CallbackEncodingGzip, executions.CallbackEncodingDeflate:
+			return encoding
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// corsMaxAge lets browsers cache preflight results, in seconds.
+const corsMaxAge = 600
+
+// CORSConfig lists origins, request headers and methods browsers may use for cross-origin API calls.
+type CORSConfig struct {
+	// AllowedOrigins are exact origins such as https://tools.example.com; "*" allows any origin without credentials.
+	AllowedOrigins []string
+	AllowedHeaders []string
+	AllowedMethods []string
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for origin and whether credentials are allowed: listed
+// origins are echoed with credentials, while "*" is sent literally so that no origin can read credentialed
+// responses. Empty means the origin is not allowed.
+func (c CORSConfig) allowOrigin(origin string) (string, bool) {
+	switch {
+	case slices.Contains(c.AllowedOrigins, origin):
+		return origin, true
+	case slices.Contains(c.AllowedOrigins, "*"):
+		return "*", false
+	default:
+		return "", false
+	}
+}
+
+// SetCORS answers preflight requests and adds CORS headers to responses for allowed origins; requests from other
+// origins are served without them, so browsers block reading the response. Call it before serving.
+func (s *Server) SetCORS(cfg CORSConfig) {
+	if len(cfg.AllowedOrigins) == 0 {
+		return
+	}
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	next := s.server.Handler
+	s.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		allowed, credentials := cfg.allowOrigin(origin)
+		if allowed == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", allowed)
+		if credentials {
+			// Dashboard-style tools authenticate with HTTP Basic, which browsers send only to credentialed requests.
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
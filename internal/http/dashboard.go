@@ -0,0 +1,239 @@
+package http
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+)
+
+// DashboardPath serves the embedded operator dashboard.
+const DashboardPath = "/ui"
+
+// DashboardHandler serves a single-page dashboard listing pending and recent executions with filters, cancel and
+// force-resolve actions. The page calls the /executions API and refreshes on /events; with tenants configured
+// the browser authenticates with HTTP Basic, the tenant API key being the password.
+type DashboardHandler struct {
+	tenants *tenants.Set
+}
+
+// NewDashboardHandler creates a new dashboard handler.
+func NewDashboardHandler(tenantSet *tenants.Set) *DashboardHandler {
+	return &DashboardHandler{tenants: tenantSet}
+}
+
+// ServeHTTP handles /ui requests.
+func (h *DashboardHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if h.tenants.Enabled() {
+		tenant, ok := h.tenants.Authenticate(r)
+		if !ok {
+			// Basic challenge makes the browser ask for credentials and reuse them for API calls of the page.
+			w.Header().Set("WWW-Authenticate", `Basic realm="telegram-executor"`)
+			http.Error(w, "invalid or missing api key", http.StatusUnauthorized)
+			return
+		}
+		noteTenant(r, tenant.ID)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = io.WriteString(w, dashboardPage)
+}
+
+const dashboardPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>telegram-executor</title>
+<style>
+body { font-family: -apple-system, system-ui, sans-serif; margin: 16px; color: #222; }
+header { display: flex; gap: 8px; align-items: center; flex-wrap: wrap; margin-bottom: 12px; }
+header h1 { font-size: 18px; margin: 0 16px 0 0; }
+input, select, button { font-size: 14px; padding: 4px 8px; }
+table { width: 100%; border-collapse: collapse; font-size: 14px; }
+th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #ddd; vertical-align: top; }
+th { background: #f5f5f5; }
+td.question { max-width: 480px; white-space: pre-wrap; word-break: break-word; }
+.status { font-weight: 600; }
+.status.pending { color: #b26a00; }
+.status.success { color: #2e7d32; }
+.status.error, .status.timeout, .status.cancelled, .status.shutdown, .status.chat_unavailable { color: #c62828; }
+#live { font-size: 12px; color: #888; }
+#live.on { color: #2e7d32; }
+#error { color: #c62828; }
+</style>
+</head>
+<body>
+<header>
+<h1>telegram-executor</h1>
+<select id="status">
+<option value="all">All</option>
+<option value="pending">Pending</option>
+<option value="resolved">Resolved</option>
+</select>
+<input id="tool" placeholder="Tool">
+<input id="label" placeholder="Label key=value">
+<input id="search" placeholder="Search">
+<button id="refresh">Refresh</button>
+<button id="test">Send test prompt</button>
+<span id="test-status"></span>
+<span id="live">● offline</span>
+<span id="error"></span>
+</header>
+<table>
+<thead>
+<tr><th>Status</th><th>Correlation ID</th><th>Tool</th><th>Question</th><th>Answer</th><th>Created</th><th>Resolved</th><th></th></tr>
+</thead>
+<tbody id="rows"></tbody>
+</table>
+<script>
+const rowsEl = document.getElementById("rows");
+const errorEl = document.getElementById("error");
+const filters = ["status", "tool", "label", "search"].map(function (id) { return document.getElementById(id); });
+let records = [];
+
+function cell(row, text, className) {
+  const td = document.createElement("td");
+  td.textContent = text || "";
+  if (className) { td.className = className; }
+  row.appendChild(td);
+  return td;
+}
+
+function time(value) {
+  return value ? new Date(value).toLocaleString() : "";
+}
+
+function render() {
+  const tool = filters[1].value.trim().toLowerCase();
+  const search = filters[3].value.trim().toLowerCase();
+  rowsEl.textContent = "";
+  for (const record of records) {
+    if (tool && !(record.tool || "").toLowerCase().includes(tool)) { continue; }
+    if (search && !JSON.stringify(record).toLowerCase().includes(search)) { continue; }
+    const row = document.createElement("tr");
+    cell(row, record.status, "status " + record.status);
+    cell(row, record.correlation_id);
+    cell(row, record.tool);
+    cell(row, record.question, "question");
+    cell(row, record.answer ? record.answer + (record.responder ? " (" + record.responder + ")" : "") : "");
+    cell(row, time(record.created_at));
+    cell(row, time(record.resolved_at));
+    const actions = cell(row, "");
+    if (record.status === "pending") {
+      actions.appendChild(button("Resolve", function () { resolve(record.correlation_id); }));
+      actions.appendChild(button("Cancel", function () { cancel(record.correlation_id); }));
+    }
+    rowsEl.appendChild(row);
+  }
+}
+
+function button(text, onClick) {
+  const el = document.createElement("button");
+  el.textContent = text;
+  el.addEventListener("click", onClick);
+  return el;
+}
+
+async function call(method, path, body) {
+  const response = await fetch(path, {
+    method: method,
+    credentials: "same-origin",
+    headers: body ? {"Content-Type": "application/json"} : {},
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  const payload = await response.json().catch(function () { return {}; });
+  if (!response.ok) {
+    throw new Error(payload.result || response.statusText);
+  }
+  return payload.result;
+}
+
+async function load() {
+  const params = new URLSearchParams({status: filters[0].value});
+  const label = filters[2].value.trim();
+  if (label) { params.set("label", label); }
+  try {
+    records = await call("GET", "/executions?" + params.toString());
+    errorEl.textContent = "";
+  } catch (err) {
+    errorEl.textContent = err.message;
+  }
+  render();
+}
+
+async function cancel(id) {
+  if (!confirm("Cancel " + id + "?")) { return; }
+  try { await call("DELETE", "/executions/" + encodeURIComponent(id)); } catch (err) { errorEl.textContent = err.message; }
+  load();
+}
+
+async function resolve(id) {
+  const answer = prompt("Answer for " + id + " (an option or a custom answer):");
+  if (!answer) { return; }
+  try { await call("POST", "/executions/" + encodeURIComponent(id) + "/resolve", {answer: answer}); } catch (err) { errorEl.textContent = err.message; }
+  load();
+}
+
+// sendTestPrompt posts a canned question to the chat and follows it until the loopback callback arrives.
+async function sendTestPrompt() {
+  const statusEl = document.getElementById("test-status");
+  let id;
+  try {
+    const response = await fetch("/test-prompt", {method: "POST", credentials: "same-origin"});
+    const payload = await response.json();
+    if (!response.ok) { throw new Error(payload.result || response.statusText); }
+    id = payload.correlation_id;
+  } catch (err) {
+    statusEl.textContent = "Test prompt failed: " + err.message;
+    return;
+  }
+  statusEl.textContent = "Test prompt " + id + " sent, answer it in the chat…";
+  const poll = setInterval(async function () {
+    try {
+      const result = await call("GET", "/test-prompt/" + encodeURIComponent(id));
+      if (result.callback_received) {
+        statusEl.textContent = "✅ Test prompt answered by " + (result.responder || "someone") + ", callback received";
+        clearInterval(poll);
+      } else if (result.status !== "pending") {
+        statusEl.textContent = "Test prompt resolved (" + result.status + "), waiting for callback…";
+      }
+    } catch (err) {
+      statusEl.textContent = "Test prompt: " + err.message;
+      clearInterval(poll);
+    }
+  }, 3000);
+}
+
+let pendingLoad = null;
+function scheduleLoad() {
+  if (pendingLoad) { return; }
+  pendingLoad = setTimeout(function () { pendingLoad = null; load(); }, 300);
+}
+
+function connect() {
+  const live = document.getElementById("live");
+  const source = new EventSource("/events", {withCredentials: true});
+  const types = ["execution_submitted", "prompt_sent", "prompt_bumped", "option_selected", "custom_answer",
+    "timed_out", "cancelled", "chat_unavailable", "finalize_fallback", "callback_delivered", "callback_failed"];
+  for (const type of types) { source.addEventListener(type, scheduleLoad); }
+  source.onopen = function () { live.textContent = "● live"; live.className = "on"; };
+  source.onerror = function () { live.textContent = "● offline"; live.className = ""; };
+}
+
+filters[0].addEventListener("change", load);
+filters[2].addEventListener("change", load);
+filters[1].addEventListener("input", render);
+filters[3].addEventListener("input", render);
+document.getElementById("refresh").addEventListener("click", load);
+document.getElementById("test").addEventListener("click", sendTestPrompt);
+load();
+connect();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var errUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// requestBody returns the request body decoded according to Content-Encoding (gzip or deflate) and limited to
+// limit bytes after decompression.
+func requestBody(r *http.Request, limit int64) (io.ReadCloser, error) {
+	var decoded io.ReadCloser
+	var err error
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		decoded = r.Body
+	case "gzip", "x-gzip":
+		decoded, err = gzip.NewReader(r.Body)
+	case "deflate":
+		decoded, err = zlib.NewReader(r.Body)
+	default:
+		return nil, fmt.Errorf("%w %q", errUnsupportedEncoding, encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s body: %w", r.Header.Get("Content-Encoding"), err)
+	}
+	return limitedBody{Reader: io.LimitReader(decoded, limit), Closer: decoded}, nil
+}
+
+type limitedBody struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/events"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+)
+
+const (
+	eventsBuffer    = 64
+	eventsKeepAlive = 15 * time.Second
+)
+
+// EventsHandler streams execution lifecycle events via Server-Sent Events. With tenants configured a tenant only
+// receives events of its own executions, with correlation ids as it sent them.
+type EventsHandler struct {
+	bus     *events.Bus
+	tenants *tenants.Set
+	log     *slog.Logger
+}
+
+// NewEventsHandler creates a new SSE handler.
+func NewEventsHandler(bus *events.Bus, tenantSet *tenants.Set, log *slog.Logger) *EventsHandler {
+	return &EventsHandler{bus: bus, tenants: tenantSet, log: log}
+}
+
+// ServeHTTP handles /events requests.
+func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	tenant, ok := authenticate(w, r, h.tenants)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	stream := make(chan events.Event, eventsBuffer)
+	unsubscribe := h.bus.Subscribe(func(event events.Event) {
+		if h.tenants.Enabled() {
+			correlationID, ok := strings.CutPrefix(event.CorrelationID, tenant.ID+"/")
+			if !ok {
+				return
+			}
+			event.CorrelationID = correlationID
+		}
+		select {
+		case stream <- event:
+		default:
+			h.log.Warn("SSE event dropped: client too slow", "event", string(event.Type))
+		}
+	})
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event := <-stream:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -1,28 +1,38 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/codex-k8s/telegram-executor/internal/config"
 	"github.com/codex-k8s/telegram-executor/internal/executions"
 	"github.com/codex-k8s/telegram-executor/internal/telegram"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+	"github.com/codex-k8s/telegram-executor/internal/tools"
 )
 
+// maxExecuteBody limits the decompressed /execute payload.
+const maxExecuteBody = 8 << 20
+
 // ExecuteHandler handles execution requests from yaml-mcp-server.
 type ExecuteHandler struct {
-	svc *telegram.Service
-	cfg config.Config
-	log *slog.Logger
+	svc     *telegram.Service
+	cfg     config.Config
+	tenants *tenants.Set
+	tools   *tools.Registry
+	log     *slog.Logger
 }
 
 // NewExecuteHandler creates a new execution handler.
-func NewExecuteHandler(svc *telegram.Service, cfg config.Config, log *slog.Logger) *ExecuteHandler {
-	return &ExecuteHandler{svc: svc, cfg: cfg, log: log}
+func NewExecuteHandler(svc *telegram.Service, cfg config.Config, tenantSet *tenants.Set, toolRegistry *tools.Registry, log *slog.Logger) *ExecuteHandler {
+	return &ExecuteHandler{svc: svc, cfg: cfg, tenants: tenantSet, tools: toolRegistry, log: log}
 }
 
 // ExecuteRequest defines input payload for /execute.
@@ -32,9 +42,14 @@ type ExecuteRequest struct {
 	Arguments     map[string]any       `json:"arguments"`
 	Spec          map[string]any       `json:"spec,omitempty"`
 	Lang          string               `json:"lang,omitempty"`
+	STTLang       string               `json:"stt_lang,omitempty"`
 	Markup        string               `json:"markup,omitempty"`
 	Callback      *executions.Callback `json:"callback,omitempty"`
 	TimeoutSec    int                  `json:"timeout_sec,omitempty"`
+	// Deadline is an absolute RFC 3339 answer deadline, an alternative to TimeoutSec that survives retries.
+	Deadline string            `json:"deadline,omitempty"`
+	GroupID  string            `json:"group_id,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
 }
 
 // ExecuteResponse defines output payload for /execute.
@@ -50,52 +65,185 @@ func (h *ExecuteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	var req ExecuteRequest
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&req); err != nil {
-		h.respond(w, http.StatusBadRequest, executions.StatusError, "invalid json payload")
+	tenant, ok := authenticate(w, r, h.tenants)
+	if !ok {
 		return
 	}
+	var req ExecuteRequest
+	statusCode, resp := reply(http.StatusBadRequest, executions.StatusError, "invalid json payload")
+	body, err := requestBody(r, maxExecuteBody)
+	switch {
+	case errors.Is(err, errUnsupportedEncoding):
+		statusCode, resp = reply(http.StatusUnsupportedMediaType, executions.StatusError, err.Error())
+	case err != nil:
+		statusCode, resp = reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	case json.NewDecoder(body).Decode(&req) == nil:
+		statusCode, resp = h.Submit(r.Context(), tenant, req)
+	}
+	if body != nil {
+		_ = body.Close()
+	}
+	h.write(w, statusCode, resp)
+}
+
+// Submit validates the /execute request of the tenant and submits its execution, returning the HTTP status code
+// and response payload. Schedules start their runs through it as well.
+func (h *ExecuteHandler) Submit(ctx context.Context, tenant tenants.Tenant, req ExecuteRequest) (int, ExecuteResponse) {
 	if strings.TrimSpace(req.CorrelationID) == "" {
-		h.respond(w, http.StatusBadRequest, executions.StatusError, "correlation_id is required")
-		return
+		return reply(http.StatusBadRequest, executions.StatusError, "correlation_id is required")
 	}
 	if strings.TrimSpace(req.Tool.Name) == "" {
-		h.respond(w, http.StatusBadRequest, executions.StatusError, "tool.name is required")
-		return
+		return reply(http.StatusBadRequest, executions.StatusError, "tool.name is required")
 	}
 	if req.Arguments == nil {
 		req.Arguments = map[string]any{}
 	}
-	if strings.TrimSpace(req.Markup) == "" {
-		req.Markup = "markdown"
+	req.Markup = strings.ToLower(strings.TrimSpace(req.Markup))
+	if req.Markup == "" || req.Markup == executions.MarkupMarkdownV2 {
+		req.Markup = executions.MarkupMarkdown
 	}
-	switch strings.ToLower(strings.TrimSpace(req.Markup)) {
-	case "markdown", "html":
+	switch req.Markup {
+	case executions.MarkupMarkdown, executions.MarkupMarkdownV1, executions.MarkupHTML, executions.MarkupEntities:
 	default:
-		h.respond(w, http.StatusBadRequest, executions.StatusError, "markup must be markdown or html")
-		return
+		return reply(http.StatusBadRequest, executions.StatusError, "markup must be markdown, markdown_v1, markdown_v2, html or entities")
+	}
+	langAuto := strings.TrimSpace(req.Lang) == ""
+	if langAuto && tenant.Lang != "" {
+		req.Lang = tenant.Lang
+	}
+	req.Lang = h.normalizeLang(req.Lang)
+	sttLang, err := parseSTTLang(req.STTLang)
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	req.GroupID = strings.TrimSpace(req.GroupID)
+	if len(req.GroupID) > maxGroupIDLength {
+		return reply(http.StatusBadRequest, executions.StatusError, fmt.Sprintf("group_id must be at most %d characters", maxGroupIDLength))
+	}
+	if err := validateLabels(req.Labels); err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
 	}
-	req.Lang = normalizeLang(req.Lang, h.cfg.Lang)
 	if req.Callback == nil || strings.TrimSpace(req.Callback.URL) == "" {
-		h.respond(w, http.StatusBadRequest, executions.StatusError, "callback.url is required for async execution")
-		return
+		return reply(http.StatusBadRequest, executions.StatusError, "callback.url is required for async execution")
+	}
+	if !tenant.CallbackAllowed(req.Callback.URL) {
+		return reply(http.StatusForbidden, executions.StatusError, "callback.url is not allowed for tenant")
+	}
+	if req.Callback.Events, err = normalizeCallbackEvents(req.Callback.Events); err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	req.Callback.AcceptEncoding = normalizeCallbackEncoding(req.Callback.AcceptEncoding)
+	deadline, err := parseDeadline(req.Deadline, req.TimeoutSec, time.Now())
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
 	}
 
-	question, contextValue, options, allowCustom, err := parseFeedbackArgs(req.Arguments, req.Spec)
+	profile, _ := h.tools.Get(req.Tool.Name)
+	overflow, err := parseOverflow(req.Spec)
 	if err != nil {
-		h.respond(w, http.StatusBadRequest, executions.StatusError, err.Error())
-		return
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	arguments, fullTexts := req.Arguments, map[string]string(nil)
+	if overflow == overflowTruncate {
+		arguments, fullTexts = truncateOverflow(req.Arguments, h.svc.Messages(req.Lang))
+	}
+	question, contextValue, options, allowCustom, err := parseFeedbackArgs(arguments, req.Spec, profile)
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
 	}
 
+	render, err := parseRenderProfile(req.Spec)
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	keyboard, err := parseKeyboardLayout(req.Spec, len(options))
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	answerMode, err := parseAnswerMode(req.Spec)
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	pollQuorum, err := parsePollQuorum(req.Spec)
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	reactions, err := parseReactions(req.Spec, len(options))
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	form, err := parseForm(req.Spec)
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	if len(form) > 0 {
+		// web_app_data is only delivered for Mini Apps opened from a reply keyboard.
+		if _, explicit := extractString(req.Spec, "answer_mode"); explicit && answerMode != executions.AnswerModeReplyKeyboard {
+			return reply(http.StatusBadRequest, executions.StatusError, "form requires answer_mode reply_keyboard")
+		}
+		if h.cfg.WebAppURL == "" {
+			return reply(http.StatusBadRequest, executions.StatusError, "form requires TG_EXECUTOR_WEBAPP_URL to be configured")
+		}
+		answerMode = executions.AnswerModeReplyKeyboard
+	}
+	if answerMode == executions.AnswerModeReplyKeyboard && render.CollapseParams {
+		return reply(http.StatusBadRequest, executions.StatusError, "render.collapse_params requires answer_mode buttons")
+	}
+
+	multiMessage, _ := extractBool(req.Spec, "multi_message_answer")
+	outputSchema, err := parseOutputSchema(req.Spec)
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	strs, err := parseStrings(req.Spec)
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	priority, err := parsePriority(req.Spec)
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	assignee, err := parseAssignee(req.Spec)
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	optionNotes, err := parseOptionNotes(req.Spec, len(options))
+	if err != nil {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
+	optionValues, objectNotes := parseOptionValues(req.Arguments)
+	optionNotes = mergeOptionNotes(optionNotes, objectNotes)
+
 	timeout := h.cfg.ExecutionTimeout
+	if tenant.Timeout > 0 {
+		timeout = tenant.Timeout
+	}
+	if profile.Timeout > 0 {
+		timeout = profile.Timeout
+	}
 	if req.TimeoutSec > 0 {
 		timeout = time.Duration(req.TimeoutSec) * time.Second
 	}
+	if !deadline.IsZero() {
+		timeout = time.Until(deadline)
+	}
+
+	reservation, err := h.tenants.Admit(tenant, time.Now())
+	if err != nil {
+		h.log.Warn("Tenant quota exceeded", "tenant", tenant.ID, "error", err, "correlation_id", req.CorrelationID)
+		return reply(http.StatusTooManyRequests, executions.StatusError, err.Error())
+	}
+
+	// Tenant chats are isolated: tool profiles route only requests without a tenant.
+	chatID := tenant.ChatID
+	if chatID == 0 {
+		chatID = profile.ChatID
+	}
 
-	ctx := r.Context()
 	res, err := h.svc.SubmitExecution(ctx, executions.Request{
-		CorrelationID: req.CorrelationID,
+		CorrelationID: executions.NamespacedID(tenant.ID, req.CorrelationID),
+		Tenant:        tenant.ID,
+		ChatID:        chatID,
 		Tool:          req.Tool,
 		Arguments:     req.Arguments,
 		Spec:          req.Spec,
@@ -103,46 +251,82 @@ func (h *ExecuteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Context:       contextValue,
 		Options:       options,
 		AllowCustom:   allowCustom,
+		OptionNotes:   optionNotes,
+		OptionValues:  optionValues,
 		Lang:          req.Lang,
+		LangAuto:      langAuto,
+		STTLang:       sttLang,
 		Markup:        req.Markup,
+		Render:        render,
+		Keyboard:      keyboard,
+		AnswerMode:    answerMode,
+		PollQuorum:    pollQuorum,
+		Reactions:     reactions,
+		Form:          form,
+		MultiMessage:  multiMessage,
+		OutputSchema:  outputSchema,
+		Strings:       strs,
+		Priority:      priority,
+		GroupID:       req.GroupID,
+		Labels:        req.Labels,
+		Assignee:      assignee,
+		Approvers:     profile.Approvers,
+		Template:      profile.Template,
 		Callback:      *req.Callback,
+		Deadline:      deadline,
+		FullTexts:     fullTexts,
 	}, timeout, h.cfg.TimeoutMessage)
+	if res.Status != executions.StatusPending {
+		// Failed submissions don't use up the daily quota.
+		reservation.Release(errors.Is(err, executions.ErrPendingLimit))
+	}
+	if errors.Is(err, executions.ErrPendingLimit) {
+		h.log.Warn("Tenant quota exceeded", "tenant", tenant.ID, "error", err, "correlation_id", req.CorrelationID)
+		return reply(http.StatusTooManyRequests, executions.StatusError, err.Error())
+	}
+	if errors.Is(err, telegram.ErrInvalidKeyboard) {
+		return reply(http.StatusBadRequest, executions.StatusError, err.Error())
+	}
 	if err != nil {
-		h.log.Error("Execution request failed", "error", err)
+		h.log.ErrorContext(ctx, "Execution request failed", "error", err, "correlation_id", req.CorrelationID)
 		if res.Status == "" {
-			h.respond(w, http.StatusInternalServerError, executions.StatusError, "execution failed")
-			return
+			return reply(http.StatusInternalServerError, executions.StatusError, "execution failed")
 		}
 	}
 
-	h.respond(w, http.StatusAccepted, res.Status, res.Output, req.CorrelationID)
+	return reply(http.StatusAccepted, res.Status, res.Output, req.CorrelationID)
 }
 
-func (h *ExecuteHandler) respond(w http.ResponseWriter, statusCode int, status executions.Status, result any, correlationID ...string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+// reply builds the status code and payload of an /execute response.
+func reply(statusCode int, status executions.Status, result any, correlationID ...string) (int, ExecuteResponse) {
 	resp := ExecuteResponse{Status: string(status), Result: result}
 	if len(correlationID) > 0 {
 		resp.CorrelationID = correlationID[0]
 	}
+	return statusCode, resp
+}
+
+func (h *ExecuteHandler) write(w http.ResponseWriter, statusCode int, resp ExecuteResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
-func parseFeedbackArgs(arguments map[string]any, spec map[string]any) (question, contextValue string, options []string, allowCustom bool, err error) {
+func parseFeedbackArgs(arguments map[string]any, spec map[string]any, profile tools.Profile) (question, contextValue string, options []string, allowCustom bool, err error) {
 	question, ok := extractString(arguments, "question")
 	if !ok {
 		return "", "", nil, false, fmt.Errorf("question is required")
 	}
-	if len([]rune(question)) < 10 || len([]rune(question)) > 1000 {
-		return "", "", nil, false, fmt.Errorf("question must be 10-1000 characters")
+	if len([]rune(question)) < 10 || len([]rune(question)) > maxQuestionLength {
+		return "", "", nil, false, fmt.Errorf("question must be 10-%d characters", maxQuestionLength)
 	}
 
 	contextValue, _ = extractString(arguments, "context")
-	if len([]rune(contextValue)) > 2000 {
-		return "", "", nil, false, fmt.Errorf("context must be <= 2000 characters")
+	if len([]rune(contextValue)) > maxContextLength {
+		return "", "", nil, false, fmt.Errorf("context must be <= %d characters", maxContextLength)
 	}
 
-	minOptions, maxOptions := optionLimitsFromSpec(spec)
+	minOptions, maxOptions := optionLimitsFromSpec(spec, profile)
 	options, err = extractOptions(arguments, minOptions, maxOptions)
 	if err != nil {
 		return "", "", nil, false, err
@@ -158,9 +342,17 @@ func parseFeedbackArgs(arguments map[string]any, spec map[string]any) (question,
 	return question, contextValue, options, allowCustom, nil
 }
 
-func optionLimitsFromSpec(spec map[string]any) (int, int) {
+// optionLimitsFromSpec returns option count limits: spec overrides the tool profile, which overrides built-in 2-5.
+func optionLimitsFromSpec(spec map[string]any, profile tools.Profile) (int, int) {
 	minOptions := 2
 	maxOptions := 5
+	if profile.OptionsMin > 0 {
+		minOptions = profile.OptionsMin
+	}
+	if profile.OptionsMax > 0 {
+		maxOptions = profile.OptionsMax
+	}
+	maxOptions = max(maxOptions, minOptions)
 	if value, ok := extractInt(spec, "options_min"); ok && value > 0 {
 		minOptions = value
 	}
@@ -184,9 +376,9 @@ func extractOptions(arguments map[string]any, minOptions, maxOptions int) ([]str
 	}
 	out := make([]string, 0, len(items))
 	for idx, item := range items {
-		value, ok := item.(string)
-		if !ok {
-			return nil, fmt.Errorf("options[%d] must be string", idx)
+		value, err := optionLabel(idx, item)
+		if err != nil {
+			return nil, err
 		}
 		value = strings.TrimSpace(value)
 		if value == "" {
@@ -256,17 +448,48 @@ func extractInt(data map[string]any, key string) (int, bool) {
 	}
 }
 
-func normalizeLang(value, fallback string) string {
+// parseDeadline validates the absolute deadline; it must be in the future and excludes timeout_sec.
+func parseDeadline(value string, timeoutSec int, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if timeoutSec > 0 {
+		return time.Time{}, fmt.Errorf("deadline and timeout_sec are mutually exclusive")
+	}
+	deadline, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("deadline must be an RFC 3339 timestamp")
+	}
+	if !deadline.After(now) {
+		return time.Time{}, fmt.Errorf("deadline must be in the future")
+	}
+	return deadline, nil
+}
+
+var sttLangPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,4})?$`)
+
+// parseSTTLang validates per-request transcription language ("auto" or language code like "de", "pt-br").
+func parseSTTLang(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == executions.STTLangAuto {
+		return value, nil
+	}
+	if !sttLangPattern.MatchString(value) {
+		return "", fmt.Errorf("stt_lang must be %q or a language code", executions.STTLangAuto)
+	}
+	return value, nil
+}
+
+// normalizeLang maps requested language ("de", "pt-BR") to an available locale,
+// falling back to the chat preference or the configured default.
+func (h *ExecuteHandler) normalizeLang(value string) string {
 	value = strings.TrimSpace(strings.ToLower(value))
-	switch value {
-	case "ru", "en":
+	if h.svc.HasLang(value) {
 		return value
 	}
-	fallback = strings.TrimSpace(strings.ToLower(fallback))
-	switch fallback {
-	case "ru", "en":
-		return fallback
-	default:
-		return "en"
+	if base, _, ok := strings.Cut(value, "-"); ok && h.svc.HasLang(base) {
+		return base
 	}
+	return h.svc.DefaultLang()
 }
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/telegram"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/handlers"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+)
+
+// maxResolveBody limits POST /executions/{id}/resolve payload.
+const maxResolveBody = 64 << 10
+
+var errInvalidResolve = errors.New("invalid resolve request")
+
+// ExecutionHandler serves a single execution: GET /executions/{id} returns its status (pending, or resolved within
+// TG_EXECUTOR_RESULT_RETENTION), DELETE /executions/{id} cancels it, POST /executions/{id}/resolve resolves it with
+// an operator's answer, POST /executions/{id}/bump re-sends its prompt at the bottom of the chat and
+// POST /executions/{id}/redeliver re-sends the callback of a resolved execution.
+type ExecutionHandler struct {
+	svc      *telegram.Service
+	registry *executions.Registry
+	tenants  *tenants.Set
+}
+
+// NewExecutionHandler creates a new single execution handler.
+func NewExecutionHandler(svc *telegram.Service, registry *executions.Registry, tenantSet *tenants.Set) *ExecutionHandler {
+	return &ExecutionHandler{svc: svc, registry: registry, tenants: tenantSet}
+}
+
+// ResolveRequest defines input payload for POST /executions/{id}/resolve.
+type ResolveRequest struct {
+	// Answer is an option (selected when it matches one) or a custom answer.
+	Answer string `json:"answer"`
+}
+
+// ServeHTTP handles /executions/{id}, /executions/{id}/resolve, /executions/{id}/bump and
+// /executions/{id}/redeliver requests.
+func (h *ExecutionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	correlationID, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/executions/"), "/")
+	if strings.TrimSpace(correlationID) == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	method := http.MethodPost
+	switch action {
+	case "":
+		method = http.MethodGet
+		if r.Method == http.MethodDelete {
+			method = http.MethodDelete
+		}
+	case "resolve", "bump", "redeliver":
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method != method {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	tenant, ok := authenticate(w, r, h.tenants)
+	if !ok {
+		return
+	}
+	switch {
+	case method == http.MethodDelete:
+		h.respondAction(w, correlationID, "cancelled", h.svc.Cancel(r.Context(), tenant.ID, correlationID))
+		return
+	case action == "resolve":
+		var req ResolveRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxResolveBody)).Decode(&req); err != nil {
+			h.respondAction(w, correlationID, "", fmt.Errorf("%w: %v", errInvalidResolve, err))
+			return
+		}
+		h.respondAction(w, correlationID, "resolved", h.svc.ForceResolve(r.Context(), tenant.ID, correlationID, req.Answer))
+		return
+	case action == "bump":
+		h.respondAction(w, correlationID, "bumped", h.svc.Bump(r.Context(), tenant.ID, correlationID))
+		return
+	case action == "redeliver":
+		h.respondAction(w, correlationID, "redelivered", h.svc.Redeliver(r.Context(), tenant.ID, correlationID))
+		return
+	}
+	record, found := h.registry.Status(executions.NamespacedID(tenant.ID, correlationID))
+	w.Header().Set("Content-Type", "application/json")
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusError), Result: "execution not found", CorrelationID: correlationID})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: record})
+}
+
+// respondAction reports the outcome of an execution action, mapping handler errors to HTTP statuses.
+func (h *ExecutionHandler) respondAction(w http.ResponseWriter, correlationID, done string, err error) {
+	status := http.StatusOK
+	response := ExecuteResponse{Status: string(executions.StatusSuccess), Result: done, CorrelationID: correlationID}
+	if err != nil {
+		switch {
+		case errors.Is(err, handlers.ErrExecutionNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errInvalidResolve), errors.Is(err, handlers.ErrEmptyAnswer):
+			status = http.StatusBadRequest
+		case errors.Is(err, handlers.ErrBumpPoll), errors.Is(err, handlers.ErrNotResolved), errors.Is(err, handlers.ErrNoCallback):
+			status = http.StatusConflict
+		default:
+			status = http.StatusBadGateway
+		}
+		response.Status = string(executions.StatusError)
+		response.Result = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+)
+
+// ExecutionsHandler lists pending executions via GET /executions?label=key=value; status=pending|resolved|all
+// lists status records including recently resolved executions.
+type ExecutionsHandler struct {
+	registry *executions.Registry
+	tenants  *tenants.Set
+}
+
+// NewExecutionsHandler creates a new pending executions handler.
+func NewExecutionsHandler(registry *executions.Registry, tenantSet *tenants.Set) *ExecutionsHandler {
+	return &ExecutionsHandler{registry: registry, tenants: tenantSet}
+}
+
+// ServeHTTP handles /executions requests.
+func (h *ExecutionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	tenant, ok := authenticate(w, r, h.tenants)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	selector, err := parseLabelSelector(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusError), Result: err.Error()})
+		return
+	}
+	// Tenants see only their own executions.
+	pending := slices.DeleteFunc(h.registry.List(selector), func(summary executions.Summary) bool {
+		return summary.Tenant != tenant.ID
+	})
+	var result any = pending
+	switch state := r.URL.Query().Get("status"); state {
+	case "":
+	case "pending", "resolved", "all":
+		result = h.records(tenant.ID, selector, pending, state)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusError), Result: "status must be pending, resolved or all"})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{
+		Status: string(executions.StatusSuccess),
+		Result: result,
+	})
+}
+
+// records lists pending executions (oldest first) and/or results retained for TG_EXECUTOR_RESULT_RETENTION
+// (most recently resolved first) as status records.
+func (h *ExecutionsHandler) records(tenant string, selector map[string]string, pending []executions.Summary, state string) []executions.StatusRecord {
+	out := []executions.StatusRecord{}
+	if state != "resolved" {
+		for _, summary := range pending {
+			out = append(out, executions.StatusRecord{Summary: summary, Status: executions.StatusPending})
+		}
+	}
+	if state != "pending" {
+		for _, record := range h.registry.Recent() {
+			if record.Tenant == tenant && executions.MatchLabels(record.Labels, selector) {
+				out = append(out, record)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+const (
+	maxFormFields       = 20
+	maxFormFieldOptions = 20
+)
+
+var formFieldName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// parseForm reads Mini App form definition from spec:
+//
+//	form:
+//	  fields:
+//	    - {name: env, label: Environment, type: select, options: [stage, prod], required: true}
+//	    - {name: date, label: Release date, type: date}
+func parseForm(spec map[string]any) ([]executions.FormField, error) {
+	raw, ok := spec["form"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	form, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("form must be object")
+	}
+	items, ok := form["fields"].([]any)
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("form.fields must be non-empty array")
+	}
+	if len(items) > maxFormFields {
+		return nil, fmt.Errorf("form.fields must have at most %d items", maxFormFields)
+	}
+	fields := make([]executions.FormField, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for idx, item := range items {
+		data, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("form.fields[%d] must be object", idx)
+		}
+		field := executions.FormField{Type: executions.FieldText}
+		field.Name, _ = extractString(data, "name")
+		if !formFieldName.MatchString(field.Name) {
+			return nil, fmt.Errorf("form.fields[%d].name must match %s", idx, formFieldName.String())
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("form.fields[%d].name %q is duplicated", idx, field.Name)
+		}
+		seen[field.Name] = true
+		field.Label, _ = extractString(data, "label")
+		if field.Label == "" {
+			field.Label = field.Name
+		}
+		if value, ok := extractString(data, "type"); ok {
+			field.Type = strings.ToLower(value)
+		}
+		field.Required, _ = extractBool(data, "required")
+		switch field.Type {
+		case executions.FieldText, executions.FieldTextarea, executions.FieldNumber, executions.FieldDate, executions.FieldCheckbox:
+		case executions.FieldSelect:
+			options, err := extractOptions(data, 1, maxFormFieldOptions)
+			if err != nil {
+				return nil, fmt.Errorf("form.fields[%d]: %w", idx, err)
+			}
+			field.Options = options
+		default:
+			return nil, fmt.Errorf("form.fields[%d].type must be text, textarea, number, select, date or checkbox", idx)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/telegram"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+)
+
+// maxGroupIDLength caps group_id of /execute requests.
+const maxGroupIDLength = 128
+
+// GroupsHandler cancels execution groups via DELETE /groups/{id}.
+type GroupsHandler struct {
+	svc     *telegram.Service
+	tenants *tenants.Set
+}
+
+// NewGroupsHandler creates a new groups handler.
+func NewGroupsHandler(svc *telegram.Service, tenantSet *tenants.Set) *GroupsHandler {
+	return &GroupsHandler{svc: svc, tenants: tenantSet}
+}
+
+// GroupCancelResponse defines output payload for DELETE /groups/{id}.
+type GroupCancelResponse struct {
+	GroupID   string `json:"group_id"`
+	Cancelled int    `json:"cancelled"`
+}
+
+// ServeHTTP handles /groups/{id} requests.
+func (h *GroupsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	tenant, ok := authenticate(w, r, h.tenants)
+	if !ok {
+		return
+	}
+	groupID := strings.TrimSpace(strings.TrimPrefix(r.URL.Path, "/groups/"))
+	if groupID == "" || strings.Contains(groupID, "/") || len(groupID) > maxGroupIDLength {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	cancelled := h.svc.CancelGroup(r.Context(), tenant.ID, groupID)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{
+		Status: string(executions.StatusSuccess),
+		Result: GroupCancelResponse{GroupID: groupID, Cancelled: cancelled},
+	})
+}
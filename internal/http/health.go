@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+const readinessCheckTimeout = 5 * time.Second
+
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+type readinessResponse struct {
+	Status  string                    `json:"status"`
+	Checks  map[string]readinessState `json:"checks,omitempty"`
+	Pending *pendingState             `json:"pending,omitempty"`
+}
+
+type readinessState struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type pendingState struct {
+	Count        int   `json:"count"`
+	OldestAgeSec int64 `json:"oldest_age_sec"`
+}
+
+// AddReadinessCheck registers a named dependency check evaluated by /readyz.
+func (s *Server) AddReadinessCheck(name string, check func(ctx context.Context) error) {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	s.checks = append(s.checks, readinessCheck{name: name, check: check})
+}
+
+// SetPendingStats registers pending executions stats reported by /readyz.
+func (s *Server) SetPendingStats(stats func() executions.Stats) {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	s.pendingStats = stats
+}
+
+func (s *Server) registerHealth() {
+	s.mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	s.mux.HandleFunc("/readyz", s.handleReady)
+}
+
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if !s.ready.Load() {
+		writeReadiness(w, http.StatusServiceUnavailable, readinessResponse{Status: "not ready"})
+		return
+	}
+
+	s.healthMu.Lock()
+	checks := append([]readinessCheck(nil), s.checks...)
+	pendingStats := s.pendingStats
+	s.healthMu.Unlock()
+
+	resp := readinessResponse{Status: "ok"}
+	statusCode := http.StatusOK
+	if len(checks) > 0 {
+		resp.Checks = make(map[string]readinessState, len(checks))
+	}
+	for _, c := range checks {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+		err := c.check(ctx)
+		cancel()
+		if err != nil {
+			s.log.Warn("Readiness check failed", "check", c.name, "error", err)
+			resp.Checks[c.name] = readinessState{Status: "fail", Error: err.Error()}
+			resp.Status = "not ready"
+			statusCode = http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[c.name] = readinessState{Status: "ok"}
+	}
+
+	if pendingStats != nil {
+		stats := pendingStats()
+		pending := &pendingState{Count: stats.Pending}
+		if !stats.OldestCreatedAt.IsZero() {
+			pending.OldestAgeSec = int64(time.Since(stats.OldestCreatedAt).Seconds())
+		}
+		resp.Pending = pending
+	}
+
+	writeReadiness(w, statusCode, resp)
+}
+
+func writeReadiness(w http.ResponseWriter, statusCode int, resp readinessResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(resp)
+}
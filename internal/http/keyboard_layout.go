@@ -0,0 +1,190 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+const (
+	defaultGridColumns = 2
+	maxKeyboardColumns = 8
+	maxOptionEmojiLen  = 8
+	maxHotkeyLen       = 16
+	maxPollQuorum      = 100
+)
+
+// parseKeyboardLayout reads keyboard settings from spec:
+//
+//	options_layout: grid   # list (default) or grid
+//	keyboard_columns: 3    # implies grid
+//	option_emoji: ["✅", "❌", "⏭"]
+//	option_numbering: false  # drop "1. " label prefixes
+//	option_hotkeys: true     # typing "2" answers; or per-option keys ["y", "n", "s"]
+func parseKeyboardLayout(spec map[string]any, optionsCount int) (executions.KeyboardLayout, error) {
+	layout := executions.KeyboardLayout{Columns: 1}
+
+	mode := executions.LayoutList
+	if value, ok := extractString(spec, "options_layout"); ok {
+		mode = strings.ToLower(value)
+	}
+	columns, hasColumns := extractInt(spec, "keyboard_columns")
+	switch mode {
+	case executions.LayoutList:
+		if hasColumns && columns > 1 {
+			mode = executions.LayoutGrid
+		}
+	case executions.LayoutGrid:
+	default:
+		return layout, fmt.Errorf("options_layout must be list or grid")
+	}
+	if mode == executions.LayoutGrid {
+		if !hasColumns {
+			columns = defaultGridColumns
+		}
+		if columns < 1 || columns > maxKeyboardColumns {
+			return layout, fmt.Errorf("keyboard_columns must be 1-%d", maxKeyboardColumns)
+		}
+		layout.Columns = columns
+	}
+
+	if numbering, ok := extractBool(spec, "option_numbering"); ok {
+		layout.HideNumbers = !numbering
+	}
+	hotkeys, err := parseOptionHotkeys(spec, optionsCount)
+	if err != nil {
+		return layout, err
+	}
+	layout.Hotkeys = hotkeys
+
+	raw, ok := spec["option_emoji"]
+	if !ok || raw == nil {
+		return layout, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return layout, fmt.Errorf("option_emoji must be array")
+	}
+	if len(items) > optionsCount {
+		return layout, fmt.Errorf("option_emoji must have at most %d items", optionsCount)
+	}
+	layout.OptionEmoji = make([]string, 0, len(items))
+	for idx, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return layout, fmt.Errorf("option_emoji[%d] must be string", idx)
+		}
+		value = strings.TrimSpace(value)
+		if len([]rune(value)) > maxOptionEmojiLen {
+			return layout, fmt.Errorf("option_emoji[%d] must be <= %d characters", idx, maxOptionEmojiLen)
+		}
+		layout.OptionEmoji = append(layout.OptionEmoji, value)
+	}
+	return layout, nil
+}
+
+// parseOptionHotkeys reads option_hotkeys from spec: true numbers options from "1", an array sets a key per option.
+func parseOptionHotkeys(spec map[string]any, optionsCount int) ([]string, error) {
+	raw, ok := spec["option_hotkeys"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	switch value := raw.(type) {
+	case bool:
+		if !value {
+			return nil, nil
+		}
+		hotkeys := make([]string, 0, optionsCount)
+		for idx := range optionsCount {
+			hotkeys = append(hotkeys, strconv.Itoa(idx+1))
+		}
+		return hotkeys, nil
+	case []any:
+		if len(value) != optionsCount {
+			return nil, fmt.Errorf("option_hotkeys must have %d items", optionsCount)
+		}
+		hotkeys := make([]string, 0, len(value))
+		seen := make(map[string]struct{}, len(value))
+		for idx, item := range value {
+			key, ok := item.(string)
+			key = strings.TrimSpace(key)
+			if !ok || key == "" || len([]rune(key)) > maxHotkeyLen || strings.ContainsFunc(key, unicode.IsSpace) {
+				return nil, fmt.Errorf("option_hotkeys[%d] must be a word of 1-%d characters", idx, maxHotkeyLen)
+			}
+			if _, dup := seen[strings.ToLower(key)]; dup {
+				return nil, fmt.Errorf("option_hotkeys[%d] duplicates %q", idx, key)
+			}
+			seen[strings.ToLower(key)] = struct{}{}
+			hotkeys = append(hotkeys, key)
+		}
+		return hotkeys, nil
+	default:
+		return nil, fmt.Errorf("option_hotkeys must be boolean or array")
+	}
+}
+
+// parseAnswerMode reads answer_mode from spec (buttons by default).
+func parseAnswerMode(spec map[string]any) (string, error) {
+	value, ok := extractString(spec, "answer_mode")
+	if !ok {
+		return executions.AnswerModeButtons, nil
+	}
+	switch mode := strings.ToLower(value); mode {
+	case executions.AnswerModeButtons, executions.AnswerModeReplyKeyboard, executions.AnswerModePoll:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("answer_mode must be buttons, reply_keyboard or poll")
+	}
+}
+
+// parsePollQuorum reads poll_quorum from spec: votes for one option required to resolve (1 by default).
+func parsePollQuorum(spec map[string]any) (int, error) {
+	quorum, ok := extractInt(spec, "poll_quorum")
+	if !ok {
+		return 1, nil
+	}
+	if quorum < 1 || quorum > maxPollQuorum {
+		return 0, fmt.Errorf("poll_quorum must be 1-%d", maxPollQuorum)
+	}
+	return quorum, nil
+}
+
+// parseReactions reads reaction shortcuts from spec:
+//
+//	reactions: true                    # 👍 selects first option, 👎 second (exactly 2 options)
+//	reactions: {"👍": 0, "🔥": 2}      # emoji to option index
+func parseReactions(spec map[string]any, optionsCount int) (map[string]int, error) {
+	raw, ok := spec["reactions"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	switch value := raw.(type) {
+	case bool:
+		if !value {
+			return nil, nil
+		}
+		if optionsCount != 2 {
+			return nil, fmt.Errorf("reactions: true requires exactly 2 options")
+		}
+		return map[string]int{"👍": 0, "👎": 1}, nil
+	case map[string]any:
+		reactions := make(map[string]int, len(value))
+		for emoji, rawIndex := range value {
+			emoji = strings.TrimSpace(emoji)
+			if emoji == "" {
+				return nil, fmt.Errorf("reactions keys must be emoji")
+			}
+			index, ok := rawIndex.(float64)
+			if !ok || index != float64(int(index)) || index < 0 || int(index) >= optionsCount {
+				return nil, fmt.Errorf("reactions[%s] must be option index 0-%d", emoji, optionsCount-1)
+			}
+			reactions[emoji] = int(index)
+		}
+		return reactions, nil
+	default:
+		return nil, fmt.Errorf("reactions must be boolean or object")
+	}
+}
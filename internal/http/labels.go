@@ -0,0 +1,53 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	maxLabels           = 16
+	maxLabelValueLength = 128
+)
+
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]{0,62}$`)
+
+// validateLabels checks request labels: up to 16 keys like "project" or "app.kubernetes.io-name"
+// with values up to 128 characters.
+func validateLabels(labels map[string]string) error {
+	if len(labels) > maxLabels {
+		return fmt.Errorf("labels must have at most %d keys", maxLabels)
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("labels: invalid key %q", key)
+		}
+		if len([]rune(labels[key])) > maxLabelValueLength {
+			return fmt.Errorf("labels.%s must be at most %d characters", key, maxLabelValueLength)
+		}
+	}
+	return nil
+}
+
+// parseLabelSelector reads repeated ?label=key=value query parameters (comma-separated pairs are accepted too).
+func parseLabelSelector(query url.Values) (map[string]string, error) {
+	selector := map[string]string{}
+	for _, raw := range query["label"] {
+		for _, pair := range strings.Split(raw, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || !labelKeyPattern.MatchString(key) {
+				return nil, fmt.Errorf("label selector must be key=value, got %q", pair)
+			}
+			selector[key] = value
+		}
+	}
+	return selector, nil
+}
@@ -0,0 +1,38 @@
+package http
+
+import "fmt"
+
+// maxOptionNoteLength caps a per-option note; notes are also shown in the 200-character callback toast.
+const maxOptionNoteLength = 500
+
+// parseOptionNotes reads spec.option_notes, notes aligned with options and shown once the option is selected:
+//
+//	option_notes: ["This will restart 14 pods", "", "Nothing changes"]
+func parseOptionNotes(spec map[string]any, optionsCount int) ([]string, error) {
+	raw, ok := spec["option_notes"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("spec.option_notes must be array")
+	}
+	if len(items) > optionsCount {
+		return nil, fmt.Errorf("spec.option_notes must have at most %d items", optionsCount)
+	}
+	notes := make([]string, len(items))
+	for idx, item := range items {
+		if item == nil {
+			continue
+		}
+		note, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("spec.option_notes[%d] must be string", idx)
+		}
+		if len([]rune(note)) > maxOptionNoteLength {
+			return nil, fmt.Errorf("spec.option_notes[%d] must be at most %d characters", idx, maxOptionNoteLength)
+		}
+		notes[idx] = note
+	}
+	return notes, nil
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"fmt"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+// optionLabel returns label of a plain string option or of a structured one:
+//
+//	options:
+//	  - "Skip"
+//	  - {label: "Canary for 10% traffic", value: "canary-10", metadata: {weight: 10}, note: "Routes 10% of users"}
+func optionLabel(idx int, item any) (string, error) {
+	switch value := item.(type) {
+	case string:
+		return value, nil
+	case map[string]any:
+		label, ok := value["label"].(string)
+		if !ok {
+			return "", fmt.Errorf("options[%d].label must be string", idx)
+		}
+		if raw, ok := value["metadata"]; ok && raw != nil {
+			if _, ok := raw.(map[string]any); !ok {
+				return "", fmt.Errorf("options[%d].metadata must be object", idx)
+			}
+		}
+		if raw, ok := value["note"]; ok && raw != nil {
+			note, ok := raw.(string)
+			if !ok {
+				return "", fmt.Errorf("options[%d].note must be string", idx)
+			}
+			if len([]rune(note)) > maxOptionNoteLength {
+				return "", fmt.Errorf("options[%d].note must be at most %d characters", idx, maxOptionNoteLength)
+			}
+		}
+		return label, nil
+	default:
+		return "", fmt.Errorf("options[%d] must be string or object", idx)
+	}
+}
+
+// parseOptionValues collects value, metadata and note of structured options validated by extractOptions.
+// Both slices are nil when all options are plain strings.
+func parseOptionValues(arguments map[string]any) ([]executions.OptionValue, []string) {
+	items, _ := arguments["options"].([]any)
+	var values []executions.OptionValue
+	var notes []string
+	for idx, item := range items {
+		option, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if values == nil {
+			values = make([]executions.OptionValue, len(items))
+			notes = make([]string, len(items))
+		}
+		metadata, _ := option["metadata"].(map[string]any)
+		values[idx] = executions.OptionValue{Value: option["value"], Metadata: metadata}
+		notes[idx], _ = option["note"].(string)
+	}
+	return values, notes
+}
+
+// mergeOptionNotes fills options without spec.option_notes entry with notes of structured options.
+func mergeOptionNotes(specNotes, optionNotes []string) []string {
+	if len(optionNotes) == 0 {
+		return specNotes
+	}
+	merged := make([]string, len(optionNotes))
+	copy(merged, optionNotes)
+	for idx, note := range specNotes {
+		if note != "" {
+			merged[idx] = note
+		}
+	}
+	return merged
+}
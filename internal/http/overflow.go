@@ -0,0 +1,82 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+)
+
+const (
+	maxQuestionLength = 1000
+	maxContextLength  = 2000
+)
+
+// Overflow modes accepted in spec.overflow.
+const (
+	// overflowReject rejects requests with a too long question or context.
+	overflowReject = "reject"
+	// overflowTruncate cuts the text with a marker and attaches the full text as a document.
+	overflowTruncate = "truncate"
+)
+
+// parseOverflow reads spec.overflow: reject (default) or truncate.
+func parseOverflow(spec map[string]any) (string, error) {
+	value, ok := extractString(spec, "overflow")
+	if !ok {
+		return overflowReject, nil
+	}
+	switch mode := strings.ToLower(value); mode {
+	case overflowReject, overflowTruncate:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("overflow must be reject or truncate")
+	}
+}
+
+// truncateOverflow returns a copy of arguments with question and context cut to their length limits and the
+// full texts of cut arguments keyed by argument name.
+func truncateOverflow(arguments map[string]any, msg i18n.Messages) (map[string]any, map[string]string) {
+	var out map[string]any
+	var full map[string]string
+	for _, field := range []struct {
+		key   string
+		limit int
+	}{{"question", maxQuestionLength}, {"context", maxContextLength}} {
+		value, ok := extractString(arguments, field.key)
+		if !ok || len([]rune(value)) <= field.limit {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]any, len(arguments))
+			for key, arg := range arguments {
+				out[key] = arg
+			}
+			full = make(map[string]string)
+		}
+		out[field.key] = truncateWithMarker(value, field.limit, msg)
+		full[field.key] = value
+	}
+	if out == nil {
+		return arguments, nil
+	}
+	return out, full
+}
+
+// truncateWithMarker cuts value to limit runes including the "…truncated, N chars omitted" marker.
+func truncateWithMarker(value string, limit int, msg i18n.Messages) string {
+	runes := []rune(value)
+	marker := func(omitted int) string {
+		template := msg.TruncatedMarker
+		if template == "" {
+			template = "…truncated, {omitted} chars omitted"
+		}
+		return msg.Format(template, i18n.Vars{"omitted": omitted})
+	}
+	keep := limit
+	// Marker length depends on the omitted count; shrink until both fit.
+	for keep > 0 && keep+len([]rune(marker(len(runes)-keep))) > limit {
+		keep--
+	}
+	return strings.TrimRight(string(runes[:keep]), " \n") + marker(len(runes)-keep)
+}
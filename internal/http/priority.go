@@ -0,0 +1,22 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+// parsePriority reads spec.priority: low, normal (default), high or urgent.
+func parsePriority(spec map[string]any) (string, error) {
+	value, ok := extractString(spec, "priority")
+	if !ok {
+		return executions.PriorityNormal, nil
+	}
+	switch priority := strings.ToLower(value); priority {
+	case executions.PriorityLow, executions.PriorityNormal, executions.PriorityHigh, executions.PriorityUrgent:
+		return priority, nil
+	default:
+		return "", fmt.Errorf("priority must be low, normal, high or urgent")
+	}
+}
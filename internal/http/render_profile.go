@@ -0,0 +1,106 @@
+package http
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+var knownSections = []string{
+	executions.SectionQuestion,
+	executions.SectionContext,
+	executions.SectionOptions,
+	executions.SectionTool,
+	executions.SectionParams,
+	executions.SectionAction,
+}
+
+// parseRenderProfile reads spec.render:
+//
+//	render:
+//	  sections: [tool, question, context, options, params, action]
+//	  show_params: true
+//	  show_tool: true
+//	  emoji: false
+//	  title_emoji: "🚀"
+//	  collapse_params: true
+//	  attach_diffs: true
+//	  markdown_input: true
+func parseRenderProfile(spec map[string]any) (executions.RenderProfile, error) {
+	profile := executions.RenderProfile{Sections: slices.Clone(executions.DefaultSections)}
+	raw, ok := spec["render"]
+	if !ok || raw == nil {
+		return profile, nil
+	}
+	render, ok := raw.(map[string]any)
+	if !ok {
+		return profile, fmt.Errorf("spec.render must be object")
+	}
+
+	if rawSections, ok := render["sections"]; ok && rawSections != nil {
+		items, ok := rawSections.([]any)
+		if !ok {
+			return profile, fmt.Errorf("spec.render.sections must be array")
+		}
+		sections := make([]string, 0, len(items))
+		for idx, item := range items {
+			value, ok := item.(string)
+			if !ok {
+				return profile, fmt.Errorf("spec.render.sections[%d] must be string", idx)
+			}
+			value = strings.ToLower(strings.TrimSpace(value))
+			if !slices.Contains(knownSections, value) {
+				return profile, fmt.Errorf("spec.render.sections[%d]: unknown section %q", idx, value)
+			}
+			if slices.Contains(sections, value) {
+				return profile, fmt.Errorf("spec.render.sections[%d]: duplicate section %q", idx, value)
+			}
+			sections = append(sections, value)
+		}
+		if !slices.Contains(sections, executions.SectionQuestion) {
+			return profile, fmt.Errorf("spec.render.sections must include %q", executions.SectionQuestion)
+		}
+		profile.Sections = sections
+	}
+
+	if value, ok := extractBool(render, "show_tool"); ok {
+		profile.Sections = toggleSection(profile.Sections, executions.SectionTool, value, 0)
+	}
+	if value, ok := extractBool(render, "show_params"); ok {
+		profile.Sections = toggleSection(profile.Sections, executions.SectionParams, value, slices.Index(profile.Sections, executions.SectionAction))
+	}
+	if value, ok := extractBool(render, "emoji"); ok {
+		profile.HideEmoji = !value
+	}
+	if value, ok := extractString(render, "title_emoji"); ok {
+		profile.TitleEmoji = value
+	}
+	if value, ok := extractBool(render, "collapse_params"); ok {
+		profile.CollapseParams = value
+	}
+	if value, ok := extractBool(render, "attach_diffs"); ok {
+		profile.AttachDiffs = value
+	}
+	if value, ok := extractBool(render, "markdown_input"); ok {
+		profile.MarkdownInput = value
+	}
+	return profile, nil
+}
+
+// toggleSection adds section at position (or to the end when position < 0) or removes it.
+func toggleSection(sections []string, section string, enabled bool, position int) []string {
+	idx := slices.Index(sections, section)
+	switch {
+	case enabled && idx < 0:
+		if position < 0 || position > len(sections) {
+			position = len(sections)
+		}
+		return slices.Insert(sections, position, section)
+	case !enabled && idx >= 0:
+		return slices.Delete(sections, idx, idx+1)
+	default:
+		return sections
+	}
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/schedules"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+)
+
+// maxScheduleBody limits POST /schedules payload.
+const maxScheduleBody = 1 << 20
+
+// SchedulesHandler manages recurring check-ins: POST /schedules creates one, GET /schedules lists them,
+// GET and DELETE /schedules/{id} read and remove one. Every run is submitted like an /execute request.
+type SchedulesHandler struct {
+	schedules *schedules.Registry
+	execute   *ExecuteHandler
+	tenants   *tenants.Set
+	log       *slog.Logger
+}
+
+// NewSchedulesHandler creates a new schedules handler submitting runs through execute.
+func NewSchedulesHandler(scheduleRegistry *schedules.Registry, execute *ExecuteHandler, tenantSet *tenants.Set, log *slog.Logger) *SchedulesHandler {
+	return &SchedulesHandler{schedules: scheduleRegistry, execute: execute, tenants: tenantSet, log: log}
+}
+
+// ScheduleRequest defines input payload for POST /schedules.
+type ScheduleRequest struct {
+	// ID names the schedule; a random one is generated when empty.
+	ID string `json:"id,omitempty"`
+	// Cron is a five-field cron expression evaluated in Timezone (TG_EXECUTOR_TIMEZONE by default).
+	Cron     string `json:"cron"`
+	Timezone string `json:"timezone,omitempty"`
+	// Execute is the /execute payload of every run; correlation_id is set per run.
+	Execute ExecuteRequest `json:"execute"`
+}
+
+// ScheduleView defines output payload of a schedule.
+type ScheduleView struct {
+	schedules.Schedule
+	NextRun *time.Time `json:"next_run,omitempty"`
+}
+
+func scheduleViewOf(schedule schedules.Schedule, now time.Time) ScheduleView {
+	view := ScheduleView{Schedule: schedule}
+	if next := schedule.Next(now); !next.IsZero() {
+		view.NextRun = &next
+	}
+	return view
+}
+
+// ServeHTTP handles /schedules and /schedules/{id} requests.
+func (h *SchedulesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := url.PathUnescape(strings.TrimPrefix(strings.TrimPrefix(r.URL.EscapedPath(), "/schedules"), "/"))
+	if err != nil || strings.Contains(id, "/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch {
+	case id == "" && r.Method != http.MethodGet && r.Method != http.MethodPost,
+		id != "" && r.Method != http.MethodGet && r.Method != http.MethodDelete:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	tenant, ok := authenticate(w, r, h.tenants)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	now := time.Now()
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		h.create(w, r, tenant)
+	case id == "":
+		list := h.schedules.List(tenant.ID)
+		out := make([]ScheduleView, 0, len(list))
+		for _, schedule := range list {
+			out = append(out, scheduleViewOf(schedule, now))
+		}
+		_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: out})
+	case r.Method == http.MethodGet:
+		schedule, ok := h.schedules.Get(tenant.ID, id)
+		if !ok {
+			h.respondError(w, http.StatusNotFound, schedules.ErrNotFound.Error())
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: scheduleViewOf(schedule, now)})
+	default:
+		if err := h.schedules.Delete(tenant.ID, id); err != nil {
+			h.respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.log.InfoContext(r.Context(), "Schedule removed", "schedule", id, "tenant", tenant.ID)
+		_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: "deleted"})
+	}
+}
+
+func (h *SchedulesHandler) create(w http.ResponseWriter, r *http.Request, tenant tenants.Tenant) {
+	var req ScheduleRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxScheduleBody)).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	if err := h.validate(tenant, &req.Execute); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errCallbackNotAllowed) {
+			status = http.StatusForbidden
+		}
+		h.respondError(w, status, err.Error())
+		return
+	}
+	payload, err := json.Marshal(req.Execute)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid execute payload")
+		return
+	}
+	id := strings.TrimSpace(req.ID)
+	if id == "" {
+		id = newScheduleID()
+	}
+	_, exists := h.schedules.Get(tenant.ID, id)
+	schedule, err := h.schedules.Put(schedules.Schedule{
+		ID:       id,
+		Tenant:   tenant.ID,
+		Cron:     req.Cron,
+		Timezone: req.Timezone,
+		Execute:  payload,
+	})
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.log.InfoContext(r.Context(), "Schedule registered", "schedule", id, "tenant", tenant.ID, "cron", schedule.Cron, "timezone", schedule.Timezone)
+	if !exists {
+		w.WriteHeader(http.StatusCreated)
+	}
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: scheduleViewOf(schedule, time.Now())})
+}
+
+var errCallbackNotAllowed = errors.New("execute.callback.url is not allowed for tenant")
+
+// validate checks the execute payload of a schedule the way /execute does for fields that do not change between
+// runs, so a broken schedule is rejected when created rather than on every run.
+func (h *SchedulesHandler) validate(tenant tenants.Tenant, req *ExecuteRequest) error {
+	req.CorrelationID = ""
+	if strings.TrimSpace(req.Tool.Name) == "" {
+		return fmt.Errorf("execute.tool.name is required")
+	}
+	if req.Callback == nil || strings.TrimSpace(req.Callback.URL) == "" {
+		return fmt.Errorf("execute.callback.url is required")
+	}
+	if !tenant.CallbackAllowed(req.Callback.URL) {
+		return errCallbackNotAllowed
+	}
+	var err error
+	if req.Callback.Events, err = normalizeCallbackEvents(req.Callback.Events); err != nil {
+		return fmt.Errorf("execute: %w", err)
+	}
+	if req.Deadline != "" {
+		return fmt.Errorf("execute.deadline is not supported for schedules, use timeout_sec")
+	}
+	profile, _ := h.execute.tools.Get(req.Tool.Name)
+	if _, _, _, _, err := parseFeedbackArgs(req.Arguments, req.Spec, profile); err != nil {
+		return fmt.Errorf("execute: %w", err)
+	}
+	return nil
+}
+
+// Submit starts a run of the schedule as an /execute request of its tenant.
+func (h *SchedulesHandler) Submit(ctx context.Context, schedule schedules.Schedule, correlationID string) error {
+	tenant, ok := h.tenants.Get(schedule.Tenant)
+	if !ok {
+		return fmt.Errorf("tenant %q is not configured", schedule.Tenant)
+	}
+	var req ExecuteRequest
+	if err := json.Unmarshal(schedule.Execute, &req); err != nil {
+		return fmt.Errorf("decode execute payload: %w", err)
+	}
+	req.CorrelationID = correlationID
+	statusCode, resp := h.execute.Submit(ctx, tenant, req)
+	if statusCode != http.StatusAccepted || resp.Status == string(executions.StatusError) {
+		return fmt.Errorf("execute returned %d: %v", statusCode, resp.Result)
+	}
+	return nil
+}
+
+func (h *SchedulesHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusError), Result: message})
+}
+
+func newScheduleID() string {
+	buf := make([]byte, 6)
+	_, _ = rand.Read(buf)
+	return "sched-" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,28 @@
+package http
+
+import "fmt"
+
+// parseOutputSchema reads spec.output_schema, a JSON schema object used to normalize custom answers:
+//
+//	output_schema:
+//	  type: object
+//	  properties:
+//	    approved: {type: boolean}
+//	    comment: {type: string}
+func parseOutputSchema(spec map[string]any) (map[string]any, error) {
+	raw, ok := spec["output_schema"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	schema, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("output_schema must be object")
+	}
+	if kind, _ := extractString(schema, "type"); kind != "object" {
+		return nil, fmt.Errorf("output_schema.type must be object")
+	}
+	if _, ok := schema["properties"].(map[string]any); !ok {
+		return nil, fmt.Errorf("output_schema.properties must be object")
+	}
+	return schema, nil
+}
@@ -4,29 +4,43 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/reporting"
 )
 
 // Server wraps HTTP server with readiness checks.
 type Server struct {
-	server *http.Server
-	mux    *http.ServeMux
-	ready  atomic.Bool
-	log    *slog.Logger
+	server   *http.Server
+	mux      *http.ServeMux
+	ready    atomic.Bool
+	reporter reporting.Reporter
+	log      *slog.Logger
+
+	// redactedPatterns maps route patterns to paths shown in access logs.
+	redactedPatterns map[string]string
+
+	healthMu     sync.Mutex
+	checks       []readinessCheck
+	pendingStats func() executions.Stats
 }
 
 // New creates a new HTTP server.
-func New(addr string, log *slog.Logger) *Server {
+func New(addr string, reporter reporting.Reporter, log *slog.Logger) *Server {
 	mux := http.NewServeMux()
 	s := &Server{
-		mux: mux,
-		server: &http.Server{
-			Addr:              addr,
-			Handler:           mux,
-			ReadHeaderTimeout: 5 * time.Second,
-		},
-		log: log,
+		mux:              mux,
+		reporter:         reporter,
+		log:              log,
+		redactedPatterns: make(map[string]string),
+	}
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.recoverPanics(mux),
+		ReadHeaderTimeout: 5 * time.Second,
 	}
 	s.registerHealth()
 	return s
@@ -53,18 +67,21 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
-func (s *Server) registerHealth() {
-	s.mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
-	})
-	s.mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
-		if !s.ready.Load() {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			_, _ = w.Write([]byte("not ready"))
-			return
-		}
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
+func (s *Server) recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			err := reporting.PanicError(recovered)
+			s.log.Error("Panic in HTTP handler", "error", err, "path", r.URL.Path)
+			s.reporter.Report(r.Context(), err, reporting.Tags(reporting.TagComponent, "http", reporting.TagOperation, r.URL.Path))
+			w.WriteHeader(http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
 	})
 }
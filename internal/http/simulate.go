@@ -0,0 +1,209 @@
+package http
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/config"
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/telegram"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/handlers"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/updates"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// SimulatePath prefixes endpoints injecting synthetic Telegram updates in simulation mode.
+	SimulatePath = "/simulate"
+	// maxSimulateBody limits /simulate payloads.
+	maxSimulateBody = 1 << 20
+	// simulatedUserID is the sender of simulated updates without from.id.
+	simulatedUserID = 1000
+)
+
+// SimulateHandler injects synthetic Telegram updates when TG_EXECUTOR_SIMULATE is set: POST /simulate/message
+// sends a text message to the chat and POST /simulate/callback presses an inline button. Updates are handled
+// asynchronously like Telegram ones, so the result is observed through the callback or GET /executions/{id}.
+type SimulateHandler struct {
+	svc      *telegram.Service
+	registry *executions.Registry
+	cfg      config.Config
+	tenants  *tenants.Set
+	log      *slog.Logger
+}
+
+// NewSimulateHandler creates a new simulate handler.
+func NewSimulateHandler(svc *telegram.Service, registry *executions.Registry, cfg config.Config, tenantSet *tenants.Set, log *slog.Logger) *SimulateHandler {
+	return &SimulateHandler{svc: svc, registry: registry, cfg: cfg, tenants: tenantSet, log: log}
+}
+
+// SimulatedUser is the sender of a simulated update.
+type SimulatedUser struct {
+	ID        int64  `json:"id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+}
+
+// SimulateMessageRequest defines input payload for POST /simulate/message.
+type SimulateMessageRequest struct {
+	// ChatID defaults to the chat of CorrelationID, then to the tenant chat.
+	ChatID int64         `json:"chat_id,omitempty"`
+	From   SimulatedUser `json:"from"`
+	Text   string        `json:"text"`
+	// ReplyToMessageID makes the message a reply; CorrelationID replies to the prompt of the pending execution.
+	ReplyToMessageID int    `json:"reply_to_message_id,omitempty"`
+	CorrelationID    string `json:"correlation_id,omitempty"`
+}
+
+// SimulateCallbackRequest defines input payload for POST /simulate/callback.
+type SimulateCallbackRequest struct {
+	// ChatID defaults to the chat of CorrelationID, then to the tenant chat.
+	ChatID int64         `json:"chat_id,omitempty"`
+	From   SimulatedUser `json:"from"`
+	// MessageID and Data describe the pressed button. With CorrelationID they default to the prompt of the
+	// pending execution and the button of Option.
+	MessageID     int    `json:"message_id,omitempty"`
+	Data          string `json:"data,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Option is the 0-based index of the option button pressed when Data is empty.
+	Option *int `json:"option,omitempty"`
+}
+
+// SimulatedUpdate defines output payload of /simulate requests.
+type SimulatedUpdate struct {
+	// MessageID is the id of the simulated message, or of the message whose button was pressed.
+	MessageID int `json:"message_id"`
+}
+
+// ServeHTTP handles /simulate/message and /simulate/callback requests.
+func (h *SimulateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := strings.Trim(strings.TrimPrefix(r.URL.Path, SimulatePath), "/")
+	if action != "message" && action != "callback" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	tenant, ok := authenticate(w, r, h.tenants)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if action == "message" {
+		h.message(w, r, tenant)
+	} else {
+		h.callback(w, r, tenant)
+	}
+}
+
+func (h *SimulateHandler) message(w http.ResponseWriter, r *http.Request, tenant tenants.Tenant) {
+	var req SimulateMessageRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxSimulateBody)).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		h.respondError(w, http.StatusBadRequest, "text is required")
+		return
+	}
+	if !h.chatAllowed(req.ChatID, tenant) {
+		h.respondError(w, http.StatusForbidden, "chat_id is not allowed for tenant")
+		return
+	}
+	chatID, replyTo := req.ChatID, req.ReplyToMessageID
+	if req.CorrelationID != "" {
+		exec := h.registry.Get(executions.NamespacedID(tenant.ID, req.CorrelationID))
+		if exec == nil {
+			h.respondError(w, http.StatusNotFound, "execution not found")
+			return
+		}
+		chatID = cmp.Or(chatID, exec.Request.ChatID)
+		replyTo = cmp.Or(replyTo, exec.MessageID)
+	}
+	messageID, err := h.svc.SimulateMessage(h.chatID(chatID, tenant), h.user(req.From), req.Text, replyTo)
+	if err != nil {
+		h.respondInjectError(w, err)
+		return
+	}
+	h.log.DebugContext(r.Context(), "Simulated message injected", "message_id", messageID, "correlation_id", req.CorrelationID)
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: SimulatedUpdate{MessageID: messageID}})
+}
+
+func (h *SimulateHandler) callback(w http.ResponseWriter, r *http.Request, tenant tenants.Tenant) {
+	var req SimulateCallbackRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxSimulateBody)).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	if !h.chatAllowed(req.ChatID, tenant) {
+		h.respondError(w, http.StatusForbidden, "chat_id is not allowed for tenant")
+		return
+	}
+	chatID, messageID, data := req.ChatID, req.MessageID, req.Data
+	if req.CorrelationID != "" {
+		exec := h.registry.Get(executions.NamespacedID(tenant.ID, req.CorrelationID))
+		if exec == nil {
+			h.respondError(w, http.StatusNotFound, "execution not found")
+			return
+		}
+		chatID = cmp.Or(chatID, exec.Request.ChatID)
+		messageID = cmp.Or(messageID, exec.MessageID)
+		if data == "" && req.Option != nil {
+			data = handlers.OptionCallbackData(exec.Request.CorrelationID, *req.Option)
+		}
+	}
+	if data == "" || messageID <= 0 {
+		h.respondError(w, http.StatusBadRequest, "data and message_id, or correlation_id and option are required")
+		return
+	}
+	if err := h.svc.SimulateCallback(h.chatID(chatID, tenant), h.user(req.From), messageID, data); err != nil {
+		h.respondInjectError(w, err)
+		return
+	}
+	h.log.DebugContext(r.Context(), "Simulated callback injected", "message_id", messageID, "correlation_id", req.CorrelationID)
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: SimulatedUpdate{MessageID: messageID}})
+}
+
+// chatID returns the requested chat, falling back to the tenant chat and then the default one.
+func (h *SimulateHandler) chatID(chatID int64, tenant tenants.Tenant) int64 {
+	return cmp.Or(cmp.Or(chatID, tenant.ChatID), h.cfg.ChatID)
+}
+
+// chatAllowed reports whether updates may be injected into the requested chat: with tenants only the tenant chat
+// is accepted, so a tenant cannot answer prompts of other tenants.
+func (h *SimulateHandler) chatAllowed(chatID int64, tenant tenants.Tenant) bool {
+	return chatID == 0 || !h.tenants.Enabled() || chatID == tenant.ChatID
+}
+
+// user returns the sender of a simulated update with defaults for missing fields.
+func (h *SimulateHandler) user(from SimulatedUser) telego.User {
+	user := telego.User{ID: cmp.Or(from.ID, simulatedUserID), Username: from.Username, FirstName: from.FirstName}
+	if user.FirstName == "" {
+		user.FirstName = "Simulated"
+	}
+	return user
+}
+
+func (h *SimulateHandler) respondInjectError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, telegram.ErrNotSimulated):
+		h.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, updates.ErrQueueFull):
+		h.respondError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		h.respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *SimulateHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusError), Result: message})
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+)
+
+const maxStringOverrideLength = 1024
+
+// parseStrings reads spec.strings, per-request overrides of i18n keys:
+//
+//	strings:
+//	  execution_title: "🚀 Release approval"
+//	  selected_note: "Decision"
+func parseStrings(spec map[string]any) (map[string]string, error) {
+	raw, ok := spec["strings"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	data, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("strings must be object")
+	}
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	overrides := make(map[string]string, len(data))
+	for _, key := range keys {
+		if !i18n.IsKey(key) {
+			return nil, fmt.Errorf("strings.%s is not a known message key", key)
+		}
+		value, ok := data[key].(string)
+		if !ok {
+			return nil, fmt.Errorf("strings.%s must be string", key)
+		}
+		if len([]rune(value)) > maxStringOverrideLength {
+			return nil, fmt.Errorf("strings.%s must be at most %d characters", key, maxStringOverrideLength)
+		}
+		overrides[key] = value
+	}
+	return overrides, nil
+}
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/config"
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/telegram"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+)
+
+const (
+	// TestPromptPath sends onboarding test prompts; its callback subpath receives their loopback callbacks.
+	TestPromptPath = "/test-prompt"
+	// testPromptTool is the tool name of test prompts in callbacks, events and metrics.
+	testPromptTool = "test_prompt"
+	// testPromptTimeout is how long a test prompt waits for an answer.
+	testPromptTimeout = 10 * time.Minute
+	// testPromptKeep is how long received loopback callbacks are remembered.
+	testPromptKeep = time.Hour
+	// maxTestCallbackBody limits loopback callback payload.
+	maxTestCallbackBody = 1 << 20
+)
+
+// TestPromptHandler verifies end-to-end connectivity during onboarding: POST /test-prompt sends a canned question
+// to the chat with the callback pointing back to this service, GET /test-prompt/{id} reports whether the prompt
+// was answered and the callback made it back.
+type TestPromptHandler struct {
+	svc      *telegram.Service
+	registry *executions.Registry
+	cfg      config.Config
+	tenants  *tenants.Set
+	log      *slog.Logger
+
+	mu       sync.Mutex
+	received map[string]time.Time
+}
+
+// NewTestPromptHandler creates a new test prompt handler.
+func NewTestPromptHandler(svc *telegram.Service, registry *executions.Registry, cfg config.Config, tenantSet *tenants.Set, log *slog.Logger) *TestPromptHandler {
+	return &TestPromptHandler{svc: svc, registry: registry, cfg: cfg, tenants: tenantSet, log: log, received: make(map[string]time.Time)}
+}
+
+// TestPromptStatus defines output payload for GET /test-prompt/{id}.
+type TestPromptStatus struct {
+	CorrelationID string `json:"correlation_id"`
+	// Status is pending until the prompt is answered, then the callback status.
+	Status executions.Status `json:"status"`
+	// Answer and Responder describe the answer given in the chat.
+	Answer    string `json:"answer,omitempty"`
+	Responder string `json:"responder,omitempty"`
+	// CallbackReceived reports that the loopback callback reached the service.
+	CallbackReceived   bool       `json:"callback_received"`
+	CallbackReceivedAt *time.Time `json:"callback_received_at,omitempty"`
+}
+
+// ServeHTTP handles /test-prompt, /test-prompt/callback and /test-prompt/{id} requests.
+func (h *TestPromptHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, TestPromptPath), "/")
+	switch {
+	case rest == "" && r.Method == http.MethodPost:
+		h.send(w, r)
+	case rest == "callback" && r.Method == http.MethodPost:
+		h.receive(w, r)
+	case rest != "" && rest != "callback" && !strings.Contains(rest, "/") && r.Method == http.MethodGet:
+		h.status(w, r, rest)
+	case rest == "" || rest == "callback":
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (h *TestPromptHandler) send(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := authenticate(w, r, h.tenants)
+	if !ok {
+		return
+	}
+	lang := tenant.Lang
+	if lang == "" {
+		lang = h.svc.DefaultLang()
+	}
+	msg := h.svc.Messages(lang)
+	id := newTestPromptID()
+	res, err := h.svc.SubmitExecution(r.Context(), executions.Request{
+		CorrelationID: executions.NamespacedID(tenant.ID, id),
+		Tenant:        tenant.ID,
+		ChatID:        tenant.ChatID,
+		Tool:          executions.Tool{Name: testPromptTool},
+		Arguments:     map[string]any{},
+		Question:      msg.TestPromptQuestion,
+		Options:       []string{msg.TestPromptOption},
+		Lang:          lang,
+		Markup:        executions.MarkupMarkdown,
+		Callback:      executions.Callback{URL: h.loopbackURL()},
+	}, testPromptTimeout, h.cfg.TimeoutMessage)
+	status := http.StatusAccepted
+	if err != nil || res.Status == executions.StatusError {
+		h.log.ErrorContext(r.Context(), "Test prompt failed", "error", err, "correlation_id", id)
+		status = http.StatusBadGateway
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(res.Status), Result: res.Output, CorrelationID: id})
+}
+
+// receive records a loopback callback of a test prompt.
+func (h *TestPromptHandler) receive(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		CorrelationID string `json:"correlation_id"`
+		Tool          string `json:"tool"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxTestCallbackBody)).Decode(&payload); err != nil || payload.Tool != testPromptTool {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	h.mu.Lock()
+	for id, at := range h.received {
+		if now.Sub(at) > testPromptKeep {
+			delete(h.received, id)
+		}
+	}
+	h.received[payload.CorrelationID] = now
+	h.mu.Unlock()
+	h.log.InfoContext(r.Context(), "Test prompt callback received", "correlation_id", payload.CorrelationID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TestPromptHandler) status(w http.ResponseWriter, r *http.Request, id string) {
+	tenant, ok := authenticate(w, r, h.tenants)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	record, found := h.registry.Status(executions.NamespacedID(tenant.ID, id))
+	if !found || record.Tool != testPromptTool {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusError), Result: "test prompt not found", CorrelationID: id})
+		return
+	}
+	result := TestPromptStatus{CorrelationID: id, Status: record.Status, Answer: record.Answer, Responder: record.Responder}
+	h.mu.Lock()
+	if at, ok := h.received[id]; ok {
+		result.CallbackReceived = true
+		result.CallbackReceivedAt = &at
+	}
+	h.mu.Unlock()
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: result})
+}
+
+// loopbackURL is the callback URL of this service as reachable from itself, on the listener serving test prompts.
+func (h *TestPromptHandler) loopbackURL() string {
+	host, port := strings.TrimSpace(h.cfg.HTTPHost), h.cfg.HTTPPort
+	if h.cfg.AdminEnabled() {
+		host, port = strings.TrimSpace(h.cfg.AdminHTTPHost), h.cfg.AdminHTTPPort
+	}
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	return fmt.Sprintf("http://%s%s/callback", net.JoinHostPort(host, fmt.Sprint(port)), TestPromptPath)
+}
+
+func newTestPromptID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return "test-" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+	"github.com/codex-k8s/telegram-executor/internal/tools"
+)
+
+// maxToolProfileBody limits PUT /tools/{name} payload.
+const maxToolProfileBody = 64 << 10
+
+// ToolsHandler manages tool profiles: GET /tools lists them, GET, PUT and DELETE /tools/{name} read, register
+// and remove the profile of a tool.
+type ToolsHandler struct {
+	tools   *tools.Registry
+	tenants *tenants.Set
+	log     *slog.Logger
+}
+
+// NewToolsHandler creates a new tool profiles handler.
+func NewToolsHandler(toolRegistry *tools.Registry, tenantSet *tenants.Set, log *slog.Logger) *ToolsHandler {
+	return &ToolsHandler{tools: toolRegistry, tenants: tenantSet, log: log}
+}
+
+// ToolProfile defines the JSON payload of a tool profile.
+type ToolProfile struct {
+	Name       string   `json:"name"`
+	TimeoutSec int      `json:"timeout_sec,omitempty"`
+	OptionsMin int      `json:"options_min,omitempty"`
+	OptionsMax int      `json:"options_max,omitempty"`
+	Template   string   `json:"template,omitempty"`
+	ChatID     int64    `json:"chat_id,omitempty"`
+	Approvers  []string `json:"approvers,omitempty"`
+}
+
+func toolProfileOf(profile tools.Profile) ToolProfile {
+	return ToolProfile{
+		Name:       profile.Name,
+		TimeoutSec: int(profile.Timeout / time.Second),
+		OptionsMin: profile.OptionsMin,
+		OptionsMax: profile.OptionsMax,
+		Template:   profile.Template,
+		ChatID:     profile.ChatID,
+		Approvers:  profile.Approvers,
+	}
+}
+
+// ServeHTTP handles /tools and /tools/{name} requests.
+func (h *ToolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, err := url.PathUnescape(strings.TrimPrefix(strings.TrimPrefix(r.URL.EscapedPath(), "/tools"), "/"))
+	if err != nil || strings.Contains(name, "/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch {
+	case name == "" && r.Method != http.MethodGet,
+		name != "" && r.Method != http.MethodGet && r.Method != http.MethodPut && r.Method != http.MethodDelete:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := authenticate(w, r, h.tenants); !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case name == "":
+		profiles := h.tools.List()
+		out := make([]ToolProfile, 0, len(profiles))
+		for _, profile := range profiles {
+			out = append(out, toolProfileOf(profile))
+		}
+		_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: out})
+	case r.Method == http.MethodGet:
+		profile, ok := h.tools.Get(name)
+		if !ok {
+			h.respondError(w, http.StatusNotFound, "tool profile not found")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: toolProfileOf(profile)})
+	case r.Method == http.MethodDelete:
+		if !h.tools.Delete(name) {
+			h.respondError(w, http.StatusNotFound, "tool profile not found")
+			return
+		}
+		h.log.InfoContext(r.Context(), "Tool profile removed", "tool", name)
+		_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: "deleted"})
+	default:
+		h.put(w, r, name)
+	}
+}
+
+func (h *ToolsHandler) put(w http.ResponseWriter, r *http.Request, name string) {
+	var req ToolProfile
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxToolProfileBody)).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid json payload")
+		return
+	}
+	if req.TimeoutSec < 0 {
+		h.respondError(w, http.StatusBadRequest, "timeout_sec must not be negative")
+		return
+	}
+	profile := tools.Profile{
+		Name:       name,
+		Timeout:    time.Duration(req.TimeoutSec) * time.Second,
+		OptionsMin: req.OptionsMin,
+		OptionsMax: req.OptionsMax,
+		Template:   req.Template,
+		ChatID:     req.ChatID,
+		Approvers:  req.Approvers,
+	}
+	created, err := h.tools.Put(profile)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.log.InfoContext(r.Context(), "Tool profile registered", "tool", name, "created", created)
+	stored, _ := h.tools.Get(name)
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusSuccess), Result: toolProfileOf(stored)})
+}
+
+func (h *ToolsHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{Status: string(executions.StatusError), Result: message})
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+)
+
+// UsageHandler reports tenant quota consumption via GET /usage.
+type UsageHandler struct {
+	registry *executions.Registry
+	tenants  *tenants.Set
+}
+
+// NewUsageHandler creates a new usage handler.
+func NewUsageHandler(registry *executions.Registry, tenantSet *tenants.Set) *UsageHandler {
+	return &UsageHandler{registry: registry, tenants: tenantSet}
+}
+
+// ServeHTTP handles /usage requests.
+func (h *UsageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	tenant, ok := authenticate(w, r, h.tenants)
+	if !ok {
+		return
+	}
+	usage := slices.DeleteFunc(h.tenants.Usage(h.registry.Count, time.Now()), func(item tenants.Usage) bool {
+		return item.Tenant != tenant.ID
+	})
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ExecuteResponse{
+		Status: string(executions.StatusSuccess),
+		Result: usage,
+	})
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/config"
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+)
+
+// WebAppHandler serves Mini App forms for pending executions.
+type WebAppHandler struct {
+	registry *executions.Registry
+	messages func(lang string) i18n.Messages
+	log      *slog.Logger
+}
+
+// NewWebAppHandler creates a new Mini App form handler.
+func NewWebAppHandler(registry *executions.Registry, messages func(lang string) i18n.Messages, log *slog.Logger) *WebAppHandler {
+	return &WebAppHandler{registry: registry, messages: messages, log: log}
+}
+
+type webAppPage struct {
+	Lang        string
+	Token       string
+	Question    string
+	Fields      []executions.FormField
+	SubmitLabel string
+}
+
+// ServeHTTP handles /webapp/<token> requests.
+func (h *WebAppHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(r.URL.Path, config.WebAppPath)
+	exec := h.registry.FindByWebAppToken(token)
+	if exec == nil {
+		http.Error(w, "form not found or already submitted", http.StatusNotFound)
+		return
+	}
+	page := webAppPage{
+		Lang:        exec.Request.Lang,
+		Token:       token,
+		Question:    exec.Request.Question,
+		Fields:      exec.Request.Form,
+		SubmitLabel: h.messages(exec.Request.Lang).WithOverrides(exec.Request.Strings).FormSubmitButton,
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := webAppTemplate.Execute(w, page); err != nil {
+		h.log.Error("Failed to render webapp form", "error", err, "correlation_id", exec.Request.CorrelationID)
+	}
+}
+
+var webAppTemplate = template.Must(template.New("webapp").Parse(`<!DOCTYPE html>
+<html lang="{{ .Lang }}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<script src="https://telegram.org/js/telegram-web-app.js"></script>
+<style>
+body { font-family: -apple-system, system-ui, sans-serif; margin: 16px; background: var(--tg-theme-bg-color, #fff); color: var(--tg-theme-text-color, #000); }
+label { display: block; margin: 12px 0 4px; color: var(--tg-theme-hint-color, #555); }
+input, select, textarea { width: 100%; box-sizing: border-box; padding: 8px; font-size: 16px; }
+input[type=checkbox] { width: auto; }
+button { margin-top: 16px; width: 100%; padding: 10px; font-size: 16px; background: var(--tg-theme-button-color, #2481cc); color: var(--tg-theme-button-text-color, #fff); border: 0; border-radius: 6px; }
+</style>
+</head>
+<body>
+<p>{{ .Question }}</p>
+<form id="form">
+{{- range .Fields }}
+<label for="{{ .Name }}">{{ .Label }}{{ if .Required }} *{{ end }}</label>
+{{- if eq .Type "textarea" }}
+<textarea id="{{ .Name }}" name="{{ .Name }}" rows="4"{{ if .Required }} required{{ end }}></textarea>
+{{- else if eq .Type "select" }}
+<select id="{{ .Name }}" name="{{ .Name }}"{{ if .Required }} required{{ end }}>
+<option value=""></option>
+{{- range .Options }}
+<option value="{{ . }}">{{ . }}</option>
+{{- end }}
+</select>
+{{- else if eq .Type "checkbox" }}
+<input id="{{ .Name }}" name="{{ .Name }}" type="checkbox">
+{{- else }}
+<input id="{{ .Name }}" name="{{ .Name }}" type="{{ .Type }}"{{ if eq .Type "number" }} step="any"{{ end }}{{ if .Required }} required{{ end }}>
+{{- end }}
+{{- end }}
+<button type="submit">{{ .SubmitLabel }}</button>
+</form>
+<script>
+const tg = window.Telegram.WebApp;
+const form = document.getElementById("form");
+function submitForm() {
+  if (!form.reportValidity()) { return; }
+  const values = {};
+  for (const el of form.elements) {
+    if (!el.name) { continue; }
+    values[el.name] = el.type === "checkbox" ? el.checked : el.value;
+  }
+  tg.sendData(JSON.stringify({token: {{ .Token }}, values: values}));
+}
+form.addEventListener("submit", function (event) { event.preventDefault(); submitForm(); });
+tg.ready();
+tg.expand();
+</script>
+</body>
+</html>
+`))
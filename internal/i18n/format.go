@@ -0,0 +1,169 @@
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Vars are named arguments of a message template.
+type Vars map[string]any
+
+// Format renders message template with named placeholders:
+//
+//	{name}                                  - value of vars["name"]
+//	{count, plural, one {# file} other {# files}} - plural form for the message language, "#" is the number
+//
+// Plural categories are "=N" (exact match), "zero", "one", "few", "many" and "other".
+// Unknown placeholders are kept as is, so a broken locale file never hides text.
+func (m Messages) Format(template string, vars Vars) string {
+	return formatTemplate(m.Lang, template, vars, "")
+}
+
+// formatTemplate renders template; number replaces "#" inside plural forms.
+func formatTemplate(lang, template string, vars Vars, number string) string {
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		switch c := template[i]; c {
+		case '#':
+			if number != "" {
+				b.WriteString(number)
+			} else {
+				b.WriteByte(c)
+			}
+		case '{':
+			end := matchingBrace(template, i)
+			if end < 0 {
+				b.WriteString(template[i:])
+				return b.String()
+			}
+			b.WriteString(formatPlaceholder(lang, template[i+1:end], vars, template[i:end+1]))
+			i = end
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func formatPlaceholder(lang, body string, vars Vars, raw string) string {
+	name, rest, isPlural := strings.Cut(body, ",")
+	name = strings.TrimSpace(name)
+	value, ok := vars[name]
+	if !ok {
+		return raw
+	}
+	if !isPlural {
+		return fmt.Sprint(value)
+	}
+	kind, forms, ok := strings.Cut(rest, ",")
+	if !ok || strings.TrimSpace(kind) != "plural" {
+		return raw
+	}
+	count, ok := toInt(value)
+	if !ok {
+		return raw
+	}
+	branches := parsePluralForms(forms)
+	form, ok := branches["="+strconv.Itoa(count)]
+	if !ok {
+		form, ok = branches[pluralCategory(lang, count)]
+	}
+	if !ok {
+		form, ok = branches["other"]
+	}
+	if !ok {
+		return raw
+	}
+	return formatTemplate(lang, form, vars, strconv.Itoa(count))
+}
+
+// parsePluralForms splits "one {# file} other {# files}" into category -> form.
+func parsePluralForms(text string) map[string]string {
+	forms := map[string]string{}
+	for {
+		text = strings.TrimSpace(text)
+		open := strings.IndexByte(text, '{')
+		if open <= 0 {
+			return forms
+		}
+		end := matchingBrace(text, open)
+		if end < 0 {
+			return forms
+		}
+		forms[strings.TrimSpace(text[:open])] = text[open+1 : end]
+		text = text[end+1:]
+	}
+}
+
+// matchingBrace returns index of "}" closing "{" at start, or -1.
+func matchingBrace(text string, start int) int {
+	depth := 0
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// pluralCategory implements CLDR cardinal rules for integers of supported language families.
+func pluralCategory(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	base, _, _ := strings.Cut(lang, "-")
+	switch base {
+	case "ru", "uk", "be":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "pl":
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "ja", "ko", "zh", "vi", "th", "id":
+		return "other"
+	case "fr", "pt":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		return "other"
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
+
+func toInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
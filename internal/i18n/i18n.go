@@ -3,35 +3,103 @@ package i18n
 import (
 	"embed"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Messages contains localized strings for the bot.
+// Strings with placeholders are rendered with Format.
 type Messages struct {
-	ExecutionTitle       string `yaml:"execution_title"`
-	ExecutionCorrelation string `yaml:"execution_correlation"`
-	ExecutionTool        string `yaml:"execution_tool"`
-	ExecutionParams      string `yaml:"execution_params"`
-	SectionContext       string `yaml:"section_context"`
-	SectionAction        string `yaml:"section_action"`
-	SectionParams        string `yaml:"section_params"`
-	QuestionLabel        string `yaml:"question_label"`
-	ContextLabel         string `yaml:"context_label"`
-	OptionsLabel         string `yaml:"options_label"`
-	CustomOptionButton   string `yaml:"custom_option_button"`
-	CancelCustomButton   string `yaml:"cancel_custom_button"`
-	DeleteButton         string `yaml:"delete_button"`
-	CustomPrompt         string `yaml:"custom_prompt"`
-	SelectedNote         string `yaml:"selected_note"`
-	TimeoutNote          string `yaml:"timeout_note"`
-	ErrorNote            string `yaml:"error_note"`
-	InvalidAction        string `yaml:"invalid_action"`
-	AlreadyResolved      string `yaml:"already_resolved"`
-	InvalidChat          string `yaml:"invalid_chat"`
-	VoiceDisabled        string `yaml:"voice_disabled"`
-	TranscriptionFailed  string `yaml:"transcription_failed"`
+	// Lang is the language code of the messages; it selects plural rules.
+	Lang                     string `yaml:"-"`
+	ExecutionTitle           string `yaml:"execution_title"`
+	ExecutionCorrelation     string `yaml:"execution_correlation"`
+	ExecutionTool            string `yaml:"execution_tool"`
+	ExecutionParams          string `yaml:"execution_params"`
+	SectionContext           string `yaml:"section_context"`
+	SectionAction            string `yaml:"section_action"`
+	SectionParams            string `yaml:"section_params"`
+	SectionTool              string `yaml:"section_tool"`
+	QuestionLabel            string `yaml:"question_label"`
+	ContextLabel             string `yaml:"context_label"`
+	OptionsLabel             string `yaml:"options_label"`
+	ToolTitleLabel           string `yaml:"tool_title_label"`
+	ToolDescriptionLabel     string `yaml:"tool_description_label"`
+	ToolTagsLabel            string `yaml:"tool_tags_label"`
+	CustomOptionButton       string `yaml:"custom_option_button"`
+	CancelCustomButton       string `yaml:"cancel_custom_button"`
+	DeleteButton             string `yaml:"delete_button"`
+	ShowDetailsButton        string `yaml:"show_details_button"`
+	HideDetailsButton        string `yaml:"hide_details_button"`
+	CustomPrompt             string `yaml:"custom_prompt"`
+	SelectedNote             string `yaml:"selected_note"`
+	TimeoutNote              string `yaml:"timeout_note"`
+	ErrorNote                string `yaml:"error_note"`
+	InvalidAction            string `yaml:"invalid_action"`
+	AlreadyResolved          string `yaml:"already_resolved"`
+	InvalidChat              string `yaml:"invalid_chat"`
+	VoiceDisabled            string `yaml:"voice_disabled"`
+	TranscriptionFailed      string `yaml:"transcription_failed"`
+	StartupAnnouncement      string `yaml:"startup_announcement"`
+	AttachmentCaption        string `yaml:"attachment_caption"`
+	ReplyKeyboardPlaceholder string `yaml:"reply_keyboard_placeholder"`
+	OpenFormButton           string `yaml:"open_form_button"`
+	FormSubmitButton         string `yaml:"form_submit_button"`
+	FormSubmittedNote        string `yaml:"form_submitted_note"`
+	FormInvalid              string `yaml:"form_invalid"`
+	ReplyToPrompt            string `yaml:"reply_to_prompt"`
+	CustomPromptMulti        string `yaml:"custom_prompt_multi"`
+	SubmitAnswerButton       string `yaml:"submit_answer_button"`
+	AnswerPartsStatus        string `yaml:"answer_parts_status"`
+	DocumentUnsupported      string `yaml:"document_unsupported"`
+	DocumentFailed           string `yaml:"document_failed"`
+	VoiceTranscription       string `yaml:"voice_transcription"`
+	VoiceUseButton           string `yaml:"voice_use_button"`
+	VoiceRetryButton         string `yaml:"voice_retry_button"`
+	VoiceEditButton          string `yaml:"voice_edit_button"`
+	VoiceRetryHint           string `yaml:"voice_retry_hint"`
+	VoiceEditPrompt          string `yaml:"voice_edit_prompt"`
+	VoiceTooLong             string `yaml:"voice_too_long"`
+	VoiceTranscribing        string `yaml:"voice_transcribing"`
+	VoiceQueueFull           string `yaml:"voice_queue_full"`
+	LangCurrent              string `yaml:"lang_current"`
+	LangSet                  string `yaml:"lang_set"`
+	LangReset                string `yaml:"lang_reset"`
+	LangUnknown              string `yaml:"lang_unknown"`
+	DeadlineLabel            string `yaml:"deadline_label"`
+	SubmittedLabel           string `yaml:"submitted_label"`
+	TimezoneCurrent          string `yaml:"tz_current"`
+	TimezoneSet              string `yaml:"tz_set"`
+	TimezoneReset            string `yaml:"tz_reset"`
+	TimezoneUnknown          string `yaml:"tz_unknown"`
+	CancelledNote            string `yaml:"cancelled_note"`
+	AssigneeMention          string `yaml:"assignee_mention"`
+	AssigneeOnly             string `yaml:"assignee_only"`
+	FinalizeFallback         string `yaml:"finalize_fallback"`
+	AlreadyAnswered          string `yaml:"already_answered"`
+	AnsweredButton           string `yaml:"answered_button"`
+	ComposingNote            string `yaml:"composing_note"`
+	DecisionLog              string `yaml:"decision_log"`
+	BumpUsage                string `yaml:"bump_usage"`
+	AlertTelegram            string `yaml:"alert_telegram"`
+	AlertCallbacks           string `yaml:"alert_callbacks"`
+	AlertUpdates             string `yaml:"alert_updates"`
+	AlertRecovered           string `yaml:"alert_recovered"`
+	StatusReport             string `yaml:"status_report"`
+	StatusError              string `yaml:"status_error"`
+	StatusNone               string `yaml:"status_none"`
+	StatusSTTAvailable       string `yaml:"status_stt_available"`
+	StatusSTTDisabled        string `yaml:"status_stt_disabled"`
+	TestPromptQuestion       string `yaml:"test_prompt_question"`
+	TestPromptOption         string `yaml:"test_prompt_option"`
+	ApproversOnly            string `yaml:"approvers_only"`
+	TruncatedMarker          string `yaml:"truncated_marker"`
+	AlertFailover            string `yaml:"alert_failover"`
+	ShutdownNote             string `yaml:"shutdown_note"`
 }
 
 // Bundle combines language code and messages.
@@ -40,40 +108,125 @@ type Bundle struct {
 	Lang string
 	// Messages are localized strings.
 	Messages Messages
+	// All contains messages of every available language keyed by language code.
+	All map[string]Messages
 }
 
 //go:embed *.yaml
 var files embed.FS
 
-// Load loads i18n messages for the requested language.
-func Load(lang string) (Bundle, error) {
+// Load loads embedded locales, overlays locale files from dir (when set) and selects the requested language.
+// A file in dir overrides keys of an embedded locale with the same code; a new language falls back to English
+// for missing keys.
+func Load(lang, dir string) (Bundle, error) {
 	lang = strings.ToLower(strings.TrimSpace(lang))
 	if lang == "" {
 		lang = "en"
 	}
 
-	messages, err := loadMessages(lang)
-	if err != nil && lang != "en" {
-		messages, err = loadMessages("en")
-		if err != nil {
+	all, err := loadEmbedded()
+	if err != nil {
+		return Bundle{}, err
+	}
+	if dir != "" {
+		if err := loadDir(dir, all); err != nil {
 			return Bundle{}, err
 		}
+	}
+
+	messages, ok := all[lang]
+	if !ok {
 		lang = "en"
-	} else if err != nil {
-		return Bundle{}, err
+		messages = all[lang]
 	}
+	return Bundle{Lang: lang, Messages: messages, All: all}, nil
+}
 
-	return Bundle{Lang: lang, Messages: messages}, nil
+func loadEmbedded() (map[string]Messages, error) {
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+	all := make(map[string]Messages, len(entries))
+	for _, entry := range entries {
+		data, err := files.ReadFile(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		var msg Messages
+		if err := yaml.Unmarshal(data, &msg); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", entry.Name(), err)
+		}
+		msg.Lang = strings.TrimSuffix(entry.Name(), ".yaml")
+		all[msg.Lang] = msg
+	}
+	if _, ok := all["en"]; !ok {
+		return nil, fmt.Errorf("embedded en locale is missing")
+	}
+	return all, nil
 }
 
-func loadMessages(lang string) (Messages, error) {
-	data, err := files.ReadFile(fmt.Sprintf("%s.yaml", lang))
+func loadDir(dir string, all map[string]Messages) error {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return Messages{}, err
+		return fmt.Errorf("read i18n dir: %w", err)
 	}
-	var msg Messages
-	if err := yaml.Unmarshal(data, &msg); err != nil {
-		return Messages{}, err
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		lang := strings.ToLower(strings.TrimSuffix(entry.Name(), ext))
+		if lang == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		msg, ok := all[lang]
+		if !ok {
+			msg = all["en"]
+		}
+		if err := yaml.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("parse %s: %w", entry.Name(), err)
+		}
+		msg.Lang = lang
+		all[lang] = msg
+	}
+	return nil
+}
+
+// fieldIndex maps yaml keys of Messages to struct field indexes.
+var fieldIndex = func() map[string]int {
+	typ := reflect.TypeOf(Messages{})
+	index := make(map[string]int, typ.NumField())
+	for i := range typ.NumField() {
+		tag := typ.Field(i).Tag.Get("yaml")
+		if tag != "" && tag != "-" {
+			index[tag] = i
+		}
+	}
+	return index
+}()
+
+// IsKey reports whether key (e.g. "selected_note") is a known message key.
+func IsKey(key string) bool {
+	_, ok := fieldIndex[key]
+	return ok
+}
+
+// WithOverrides returns a copy of messages with strings replaced by overrides keyed by message key.
+// Unknown keys are ignored.
+func (m Messages) WithOverrides(overrides map[string]string) Messages {
+	if len(overrides) == 0 {
+		return m
+	}
+	value := reflect.ValueOf(&m).Elem()
+	for key, text := range overrides {
+		if idx, ok := fieldIndex[key]; ok {
+			value.Field(idx).SetString(text)
+		}
 	}
-	return msg, nil
+	return m
 }
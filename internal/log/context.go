@@ -0,0 +1,68 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type attrsKey struct{}
+
+// WithAttrs returns context whose log records get attrs (slog key/value pairs) added,
+// so every line logged while processing one execution carries its correlation_id.
+func WithAttrs(ctx context.Context, args ...any) context.Context {
+	record := slog.NewRecord(time.Time{}, 0, "", 0)
+	record.Add(args...)
+	attrs := append([]slog.Attr(nil), contextAttrs(ctx)...)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextHandler adds context attrs missing from the record; explicit record attrs win.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := contextAttrs(ctx)
+	if len(attrs) == 0 {
+		return h.Handler.Handle(ctx, record)
+	}
+	record = record.Clone()
+	present := make(map[string]struct{}, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		present[attr.Key] = struct{}{}
+		return true
+	})
+	// Nested WithAttrs may repeat a key; the innermost value wins.
+	last := make(map[string]int, len(attrs))
+	for idx, attr := range attrs {
+		last[attr.Key] = idx
+	}
+	for idx, attr := range attrs {
+		if _, ok := present[attr.Key]; ok || last[attr.Key] != idx {
+			continue
+		}
+		record.AddAttrs(attr)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
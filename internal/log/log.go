@@ -10,7 +10,7 @@ import (
 func New(level string) *slog.Logger {
 	lvl := parseLevel(level)
 	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: lvl})
-	return slog.New(handler)
+	return slog.New(contextHandler{handler})
 }
 
 func parseLevel(level string) slog.Level {
@@ -0,0 +1,2 @@
+// Package metrics implements a minimal Prometheus text exposition registry.
+package metrics
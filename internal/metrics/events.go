@@ -0,0 +1,38 @@
+package metrics
+
+import "github.com/codex-k8s/telegram-executor/internal/events"
+
+// responseBuckets cover human response latency from seconds to hours.
+var responseBuckets = []float64{5, 15, 30, 60, 120, 300, 600, 1800, 3600, 7200}
+
+// EventCollector turns lifecycle events into Prometheus metrics.
+type EventCollector struct {
+	events    *Counter
+	responses *Histogram
+}
+
+// NewEventCollector registers lifecycle metrics in registry.
+func NewEventCollector(registry *Registry) *EventCollector {
+	return &EventCollector{
+		events: registry.Counter(
+			"telegram_executor_events_total",
+			"Execution lifecycle events by type.",
+			"type",
+		),
+		responses: registry.Histogram(
+			"telegram_executor_response_seconds",
+			"Time from submission to resolution by outcome.",
+			responseBuckets,
+			"outcome",
+		),
+	}
+}
+
+// Handle consumes a lifecycle event.
+func (c *EventCollector) Handle(event events.Event) {
+	c.events.Inc(string(event.Type))
+	switch event.Type {
+	case events.TypeOptionSelected, events.TypeCustomAnswer, events.TypeTimedOut:
+		c.responses.Observe(event.ElapsedSec, string(event.Type))
+	}
+}
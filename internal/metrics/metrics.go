@@ -0,0 +1,291 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds metric collectors and renders them in Prometheus text format.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+type collector interface {
+	write(builder *strings.Builder)
+}
+
+// NewRegistry creates an empty metrics registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Handler returns HTTP handler serving metrics in Prometheus text format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		r.mu.Lock()
+		collectors := append([]collector(nil), r.collectors...)
+		r.mu.Unlock()
+		builder := &strings.Builder{}
+		for _, c := range collectors {
+			c.write(builder)
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(builder.String()))
+	})
+}
+
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func (d desc) writeHeader(builder *strings.Builder, kind string) {
+	fmt.Fprintf(builder, "# HELP %s %s\n# TYPE %s %s\n", d.name, d.help, d.name, kind)
+}
+
+func (d desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d", d.name, len(d.labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+func (d desc) labelPairs(key string, extra ...string) string {
+	pairs := make([]string, 0, len(d.labels)+1)
+	if len(d.labels) > 0 {
+		values := strings.Split(key, "\xff")
+		for idx, label := range d.labels {
+			pairs = append(pairs, fmt.Sprintf("%s=%q", label, values[idx]))
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", extra[i], extra[i+1]))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Counter is a monotonically increasing metric with optional labels.
+type Counter struct {
+	desc
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// Counter registers a new counter.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{desc: desc{name: name, help: help, labels: labels}, values: make(map[string]float64)}
+	r.register(c)
+	return c
+}
+
+// Inc increments counter by one.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments counter by value.
+func (c *Counter) Add(value float64, labelValues ...string) {
+	key := c.key(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] += value
+}
+
+func (c *Counter) write(builder *strings.Builder) {
+	c.writeHeader(builder, "counter")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(builder, "%s%s %s\n", c.name, c.labelPairs(key), formatFloat(c.values[key]))
+	}
+}
+
+type counterFunc struct {
+	desc
+	fn func() float64
+}
+
+// CounterFunc registers a counter whose value is read at scrape time from a monotonic source.
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(&counterFunc{desc: desc{name: name, help: help}, fn: fn})
+}
+
+func (c *counterFunc) write(builder *strings.Builder) {
+	c.writeHeader(builder, "counter")
+	fmt.Fprintf(builder, "%s %s\n", c.name, formatFloat(c.fn()))
+}
+
+// Gauge is a metric that can go up and down.
+type Gauge struct {
+	desc
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// Gauge registers a new gauge.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{desc: desc{name: name, help: help, labels: labels}, values: make(map[string]float64)}
+	r.register(g)
+	return g
+}
+
+// Set sets gauge value.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	key := g.key(labelValues)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[key] = value
+}
+
+// Add changes gauge value by delta.
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	key := g.key(labelValues)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[key] += delta
+}
+
+func (g *Gauge) write(builder *strings.Builder) {
+	g.writeHeader(builder, "gauge")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, key := range sortedKeys(g.values) {
+		fmt.Fprintf(builder, "%s%s %s\n", g.name, g.labelPairs(key), formatFloat(g.values[key]))
+	}
+}
+
+type gaugeFunc struct {
+	desc
+	fn func() float64
+}
+
+// GaugeFunc registers a gauge whose value is computed at scrape time.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(&gaugeFunc{desc: desc{name: name, help: help}, fn: fn})
+}
+
+func (g *gaugeFunc) write(builder *strings.Builder) {
+	g.writeHeader(builder, "gauge")
+	fmt.Fprintf(builder, "%s %s\n", g.name, formatFloat(g.fn()))
+}
+
+// Sample is a single labeled value of a function gauge.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+type gaugeVecFunc struct {
+	desc
+	fn func() []Sample
+}
+
+// GaugeVecFunc registers a labeled gauge whose samples are computed at scrape time.
+func (r *Registry) GaugeVecFunc(name, help string, labels []string, fn func() []Sample) {
+	r.register(&gaugeVecFunc{desc: desc{name: name, help: help, labels: labels}, fn: fn})
+}
+
+func (g *gaugeVecFunc) write(builder *strings.Builder) {
+	g.writeHeader(builder, "gauge")
+	values := make(map[string]float64)
+	for _, sample := range g.fn() {
+		values[g.key(sample.LabelValues)] = sample.Value
+	}
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(builder, "%s%s %s\n", g.name, g.labelPairs(key), formatFloat(values[key]))
+	}
+}
+
+// Histogram samples observations into configurable buckets.
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Histogram registers a new histogram with upper-bound buckets.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	h := &Histogram{desc: desc{name: name, help: help, labels: labels}, buckets: sorted, series: make(map[string]*histogramSeries)}
+	r.register(h)
+	return h
+}
+
+// Observe records a single value.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+	for idx, bound := range h.buckets {
+		if value <= bound {
+			series.counts[idx]++
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+func (h *Histogram) write(builder *strings.Builder) {
+	h.writeHeader(builder, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		series := h.series[key]
+		for idx, bound := range h.buckets {
+			fmt.Fprintf(builder, "%s_bucket%s %d\n", h.name, h.labelPairs(key, "le", formatFloat(bound)), series.counts[idx])
+		}
+		fmt.Fprintf(builder, "%s_bucket%s %d\n", h.name, h.labelPairs(key, "le", "+Inf"), series.count)
+		fmt.Fprintf(builder, "%s_sum%s %s\n", h.name, h.labelPairs(key), formatFloat(series.sum))
+		fmt.Fprintf(builder, "%s_count%s %d\n", h.name, h.labelPairs(key), series.count)
+	}
+}
+
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
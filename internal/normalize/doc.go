@@ -0,0 +1,2 @@
+// Package normalize maps free-form custom answers onto structured output using an LLM.
+package normalize
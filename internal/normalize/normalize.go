@@ -0,0 +1,79 @@
+package normalize
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+const systemPrompt = "You convert a human answer to an approval question into JSON matching the provided schema. " +
+	"Interpret the answer faithfully; do not invent facts. Use null for values the answer does not state."
+
+// Normalizer calls OpenAI (or OpenAI-compatible) chat completions with structured output.
+type Normalizer struct {
+	client  openai.Client
+	model   string
+	timeout time.Duration
+	log     *slog.Logger
+}
+
+// New initializes normalizer; baseURL overrides api.openai.com when set.
+func New(apiKey, baseURL, model string, timeout time.Duration, log *slog.Logger) *Normalizer {
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+	return &Normalizer{client: openai.NewClient(opts...), model: model, timeout: timeout, log: log}
+}
+
+// Normalize interprets answer to question with options according to JSON schema.
+func (n *Normalizer) Normalize(ctx context.Context, question string, options []string, answer string, schema map[string]any) (map[string]any, error) {
+	normalizeCtx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Question: %s\n", question)
+	if len(options) > 0 {
+		prompt.WriteString("Offered options:\n")
+		for idx, opt := range options {
+			fmt.Fprintf(&prompt, "%d. %s\n", idx+1, opt)
+		}
+	}
+	fmt.Fprintf(&prompt, "Answer: %s", answer)
+
+	resp, err := n.client.Chat.Completions.New(normalizeCtx, openai.ChatCompletionNewParams{
+		Model: n.model,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(systemPrompt),
+			openai.UserMessage(prompt.String()),
+		},
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   "answer",
+					Schema: schema,
+				},
+			},
+		},
+	})
+	if err != nil {
+		n.log.Error("Answer normalization failed", "error", err)
+		return nil, err
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		return nil, errors.New("empty normalization result")
+	}
+	var result map[string]any
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &result); err != nil {
+		return nil, fmt.Errorf("decode normalization result: %w", err)
+	}
+	return result, nil
+}
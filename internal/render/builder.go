@@ -0,0 +1,129 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// Telegram limits of inline keyboards.
+const (
+	// MaxCallbackDataBytes is the size limit of button callback data.
+	MaxCallbackDataBytes = 64
+	// MaxRowButtons is the number of buttons Telegram accepts in one row.
+	MaxRowButtons = 8
+	// MaxKeyboardButtons is the number of buttons Telegram accepts in one keyboard.
+	MaxKeyboardButtons = 100
+)
+
+// ErrInvalidKeyboard reports a keyboard Telegram would reject.
+var ErrInvalidKeyboard = errors.New("invalid keyboard")
+
+// KeyboardBuilder assembles inline keyboards: grid buttons wrap after the column count and get labels narrowed
+// to fit it, while Telegram limits are checked as buttons are added, so Build fails before anything is sent.
+type KeyboardBuilder struct {
+	columns int
+	rows    [][]telego.InlineKeyboardButton
+	row     []telego.InlineKeyboardButton
+	buttons int
+	err     error
+}
+
+// NewKeyboardBuilder creates a builder laying grid buttons out in columns.
+func NewKeyboardBuilder(columns int) *KeyboardBuilder {
+	return &KeyboardBuilder{columns: max(columns, 1)}
+}
+
+// Button adds a grid button; label is shortened to the width of a column and prefix (numbering, emoji) is kept.
+func (b *KeyboardBuilder) Button(prefix, label, callbackData string) *KeyboardBuilder {
+	if b.columns > MaxRowButtons {
+		b.fail(fmt.Errorf("%w: %d buttons per row, Telegram allows %d", ErrInvalidKeyboard, b.columns, MaxRowButtons))
+	}
+	b.row = append(b.row, b.button(prefix+shortenButtonLabel(label, buttonLabelWidth(b.columns)), callbackData))
+	if len(b.row) == b.columns {
+		b.flush()
+	}
+	return b
+}
+
+// Row adds a full-width button on a row of its own after the grid buttons added so far.
+func (b *KeyboardBuilder) Row(label, callbackData string) *KeyboardBuilder {
+	b.flush()
+	b.rows = append(b.rows, tu.InlineKeyboardRow(b.button(label, callbackData)))
+	return b
+}
+
+// Reserve checks callback data of a button that replaces one of the keyboard later, such as the cancel button
+// of custom input, so the keyboard is not accepted when its follow-up cannot be sent.
+func (b *KeyboardBuilder) Reserve(callbackData string) *KeyboardBuilder {
+	b.checkCallbackData(callbackData)
+	return b
+}
+
+// Build returns the keyboard or the first limit it breaks.
+func (b *KeyboardBuilder) Build() (*telego.InlineKeyboardMarkup, error) {
+	b.flush()
+	if b.err != nil {
+		return nil, b.err
+	}
+	return tu.InlineKeyboard(b.rows...), nil
+}
+
+func (b *KeyboardBuilder) button(label, callbackData string) telego.InlineKeyboardButton {
+	b.buttons++
+	if b.buttons == MaxKeyboardButtons+1 {
+		b.fail(fmt.Errorf("%w: more than %d buttons", ErrInvalidKeyboard, MaxKeyboardButtons))
+	}
+	b.checkCallbackData(callbackData)
+	return tu.InlineKeyboardButton(label).WithCallbackData(callbackData)
+}
+
+func (b *KeyboardBuilder) checkCallbackData(callbackData string) {
+	if len(callbackData) > MaxCallbackDataBytes {
+		b.fail(fmt.Errorf("%w: callback data %q is %d bytes, Telegram allows %d; use a shorter correlation_id",
+			ErrInvalidKeyboard, callbackData, len(callbackData), MaxCallbackDataBytes))
+	}
+}
+
+func (b *KeyboardBuilder) flush() {
+	if len(b.row) > 0 {
+		b.rows = append(b.rows, tu.InlineKeyboardRow(b.row...))
+		b.row = nil
+	}
+}
+
+func (b *KeyboardBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// buttonLabelWidth narrows option labels when several buttons share a row.
+func buttonLabelWidth(columns int) int {
+	const fullWidth, minWidth = 42, 12
+	return max(fullWidth/columns, minWidth)
+}
+
+func shortenButtonLabel(value string, maxRunes int) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "-"
+	}
+	if maxRunes <= 0 {
+		return value
+	}
+	runes := []rune(value)
+	if len(runes) <= maxRunes {
+		return value
+	}
+	if maxRunes <= 1 {
+		return string(runes[:maxRunes])
+	}
+	if maxRunes <= 3 {
+		return string(runes[:maxRunes])
+	}
+	return string(runes[:maxRunes-3]) + "..."
+}
@@ -0,0 +1,54 @@
+package render
+
+import (
+	"sort"
+	"strings"
+)
+
+// DiffArgument is a string argument that looks like a unified diff or patch.
+type DiffArgument struct {
+	Key   string
+	Value string
+}
+
+// LooksLikeDiff detects unified diffs and +/- patches in multi-line strings.
+func LooksLikeDiff(value string) bool {
+	if !strings.Contains(value, "\n") {
+		return false
+	}
+	if strings.HasPrefix(value, "diff --git ") || strings.HasPrefix(value, "@@ ") || strings.Contains(value, "\n@@ ") {
+		return true
+	}
+	if (strings.HasPrefix(value, "--- ") || strings.Contains(value, "\n--- ")) && strings.Contains(value, "\n+++ ") {
+		return true
+	}
+	added, removed, total := 0, 0, 0
+	for _, line := range strings.Split(value, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		total++
+		switch line[0] {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added > 0 && removed > 0 && (added+removed)*2 >= total
+}
+
+// SplitDiffArguments separates diff-like top-level string arguments from the rest.
+func SplitDiffArguments(arguments map[string]any) (map[string]any, []DiffArgument) {
+	rest := make(map[string]any, len(arguments))
+	var diffs []DiffArgument
+	for key, value := range arguments {
+		if text, ok := value.(string); ok && LooksLikeDiff(text) {
+			diffs = append(diffs, DiffArgument{Key: key, Value: strings.TrimRight(text, "\n")})
+			continue
+		}
+		rest[key] = value
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return rest, diffs
+}
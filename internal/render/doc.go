@@ -0,0 +1,3 @@
+// Package render builds Telegram prompts of execution requests: the built-in layout in every supported markup and
+// the answer keyboards. Output depends only on the request and messages, so it can be checked without a bot.
+package render
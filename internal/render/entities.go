@@ -0,0 +1,115 @@
+package render
+
+import (
+	"bytes"
+
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+)
+
+// entitiesExecutionWriter renders plain text and records formatting as MessageEntity offsets,
+// so user-provided text never needs escaping.
+type entitiesExecutionWriter struct {
+	entities []telego.MessageEntity
+	// length is the UTF-16 length of the first counted bytes of the message.
+	length  int
+	counted int
+}
+
+// offset returns the UTF-16 length of the message written so far, counting only text added since the last call.
+func (w *entitiesExecutionWriter) offset(builder *bytes.Buffer) int {
+	w.length += shared.TextLength(string(builder.Bytes()[w.counted:]))
+	w.counted = builder.Len()
+	return w.length
+}
+
+func (w *entitiesExecutionWriter) write(builder *bytes.Buffer, entity, language, value string) {
+	if entity == "" || value == "" {
+		builder.WriteString(value)
+		return
+	}
+	offset := w.offset(builder)
+	builder.WriteString(value)
+	w.entities = append(w.entities, telego.MessageEntity{
+		Type:     entity,
+		Offset:   offset,
+		Length:   shared.TextLength(value),
+		Language: language,
+	})
+}
+
+func (w *entitiesExecutionWriter) WriteTitle(builder *bytes.Buffer, title string) {
+	w.write(builder, telego.EntityTypeBold, "", title)
+	builder.WriteString("\n\n")
+}
+
+func (w *entitiesExecutionWriter) WriteSectionHeader(builder *bytes.Buffer, title string) {
+	w.write(builder, telego.EntityTypeBold, "", title)
+	builder.WriteString("\n")
+}
+
+func (w *entitiesExecutionWriter) WriteLabel(builder *bytes.Buffer, label string) {
+	w.write(builder, telego.EntityTypeBold, "", label+":")
+	builder.WriteString("\n")
+}
+
+func (w *entitiesExecutionWriter) WriteLabelValue(builder *bytes.Buffer, label, value string, addEmptyLine bool) {
+	w.write(builder, telego.EntityTypeBold, "", label+":")
+	builder.WriteString(" ")
+	builder.WriteString(value)
+	builder.WriteString("\n")
+	appendOptionalLineBreak(builder, "\n", addEmptyLine)
+}
+
+func (w *entitiesExecutionWriter) WriteLabelSpans(builder *bytes.Buffer, label string, spans []shared.Span) {
+	w.write(builder, telego.EntityTypeBold, "", label+":")
+	builder.WriteString(" ")
+	text, entities := shared.SpansToEntities(spans, w.offset(builder))
+	builder.WriteString(text)
+	w.entities = append(w.entities, entities...)
+	builder.WriteString("\n")
+}
+
+func (w *entitiesExecutionWriter) WriteOptions(builder *bytes.Buffer, label string, options []string) {
+	w.write(builder, telego.EntityTypeBold, "", label+":")
+	builder.WriteString("\n")
+	for idx, option := range options {
+		writeOptionNumber(builder, idx, ") ")
+		builder.WriteString(option)
+		builder.WriteString("\n")
+	}
+}
+
+func (w *entitiesExecutionWriter) WriteCodeValue(builder *bytes.Buffer, label, value string, addEmptyLine bool) {
+	w.write(builder, telego.EntityTypeBold, "", label+":")
+	builder.WriteString(" ")
+	w.write(builder, telego.EntityTypeCode, "", value)
+	builder.WriteString("\n")
+	appendOptionalLineBreak(builder, "\n", addEmptyLine)
+}
+
+func (w *entitiesExecutionWriter) WriteCodeBlock(builder *bytes.Buffer, language, value string) {
+	w.write(builder, telego.EntityTypePre, language, value)
+	builder.WriteString("\n")
+}
+
+func (w *entitiesExecutionWriter) WriteLineBreak(builder *bytes.Buffer) {
+	builder.WriteString("\n")
+}
+
+func (w *entitiesExecutionWriter) WriteMention(builder *bytes.Buffer, before, mention, url, after string) {
+	builder.WriteString(before)
+	if url == "" {
+		w.write(builder, telego.EntityTypeMention, "", mention)
+	} else {
+		w.entities = append(w.entities, telego.MessageEntity{
+			Type:   telego.EntityTypeTextLink,
+			Offset: w.offset(builder),
+			Length: shared.TextLength(mention),
+			URL:    url,
+		})
+		builder.WriteString(mention)
+	}
+	builder.WriteString(after)
+	builder.WriteString("\n\n")
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/handlers"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// Keyboard renders the inline keyboard of the prompt: option buttons, the details toggle of collapsed params and
+// the custom answer button. It fails with ErrInvalidKeyboard when the request breaks Telegram keyboard limits,
+// including the follow-up buttons that later replace its own.
+func Keyboard(msg i18n.Messages, req executions.Request) (*telego.InlineKeyboardMarkup, error) {
+	builder := NewKeyboardBuilder(req.Keyboard.Columns)
+	for idx, option := range req.Options {
+		builder.Button(optionPrefix(req, idx), option, handlers.OptionCallbackData(req.CorrelationID, idx))
+	}
+	if req.Render.CollapseParams {
+		builder.Row(fallbackText(msg.ShowDetailsButton, "Show details"), handlers.CallbackData(handlers.ActionDetails, req.CorrelationID))
+	}
+	if req.AllowCustom {
+		customLabel := strings.TrimSpace(msg.CustomOptionButton)
+		if customLabel == "" {
+			customLabel = "Custom option"
+		}
+		builder.Row(customLabel, handlers.CallbackData(handlers.ActionCustom, req.CorrelationID))
+	}
+	// custom_cancel is the longest action sent with the correlation id after the prompt.
+	builder.Reserve(handlers.CallbackData(handlers.ActionCancelCustom, req.CorrelationID))
+	return builder.Build()
+}
+
+// ReplyKeyboard presents options as a one-time reply keyboard; answers are matched by handlers from text.
+// Requests with form get an extra button opening the Mini App form at formURL.
+func ReplyKeyboard(msg i18n.Messages, req executions.Request, formURL string) *telego.ReplyKeyboardMarkup {
+	columns := max(req.Keyboard.Columns, 1)
+	rows := make([][]telego.KeyboardButton, 0, len(req.Options)/columns+1)
+	var row []telego.KeyboardButton
+	for idx, option := range req.Options {
+		label := optionPrefix(req, idx) + shortenButtonLabel(option, buttonLabelWidth(columns))
+		row = append(row, tu.KeyboardButton(label))
+		if len(row) == columns {
+			rows = append(rows, tu.KeyboardRow(row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, tu.KeyboardRow(row...))
+	}
+	if len(req.Form) > 0 && formURL != "" {
+		label := fallbackText(msg.OpenFormButton, "Open form")
+		rows = append(rows, tu.KeyboardRow(
+			tu.KeyboardButton(label).WithWebApp(&telego.WebAppInfo{URL: formURL}),
+		))
+	}
+	keyboard := tu.Keyboard(rows...).WithOneTimeKeyboard().WithResizeKeyboard()
+	if req.AllowCustom {
+		keyboard = keyboard.WithInputFieldPlaceholder(shortenButtonLabel(msg.ReplyKeyboardPlaceholder, 64))
+	}
+	return keyboard
+}
+
+// optionPrefix numbers option button labels, custom hotkeys taking the place of numbers, and adds the optional
+// emoji.
+func optionPrefix(req executions.Request, idx int) string {
+	prefix := ""
+	switch {
+	case req.Keyboard.HideNumbers:
+	case idx < len(req.Keyboard.Hotkeys):
+		prefix = req.Keyboard.Hotkeys[idx] + ". "
+	default:
+		prefix = fmt.Sprintf("%d. ", idx+1)
+	}
+	if idx < len(req.Keyboard.OptionEmoji) && req.Keyboard.OptionEmoji[idx] != "" {
+		prefix = req.Keyboard.OptionEmoji[idx] + " " + prefix
+	}
+	return prefix
+}
@@ -0,0 +1,574 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+)
+
+// Text is a rendered prompt; Entities are set in entities markup only.
+type Text struct {
+	Text     string
+	Entities []telego.MessageEntity
+}
+
+// Prompt renders the built-in prompt layout of the request in its markup.
+func Prompt(msg i18n.Messages, req executions.Request) Text {
+	switch req.Markup {
+	case executions.MarkupEntities:
+		writer := &entitiesExecutionWriter{}
+		text := renderExecution(msg, req, writer)
+		return Text{Text: text, Entities: writer.entities}
+	case executions.MarkupHTML:
+		return Text{Text: renderExecution(msg, req, htmlExecutionWriter{})}
+	case executions.MarkupMarkdownV1:
+		return Text{Text: renderExecution(msg, req, markdownV1ExecutionWriter{})}
+	default:
+		return Text{Text: renderExecution(msg, req, markdownExecutionWriter{})}
+	}
+}
+
+// sectionGroups maps sections to visual blocks; consecutive sections of one block share a header.
+var sectionGroups = map[string]string{
+	executions.SectionQuestion: executions.SectionContext,
+	executions.SectionContext:  executions.SectionContext,
+	executions.SectionOptions:  executions.SectionContext,
+	executions.SectionTool:     executions.SectionTool,
+	executions.SectionParams:   executions.SectionParams,
+	executions.SectionAction:   executions.SectionAction,
+}
+
+func renderExecution(msg i18n.Messages, req executions.Request, writer executionMessageWriter) string {
+	profile := req.Render
+	sections := profile.Sections
+	if len(sections) == 0 {
+		sections = executions.DefaultSections
+	}
+	labels := executionLabelsFor(msg)
+	header := func(title string) string {
+		if profile.HideEmoji {
+			return stripLeadingEmoji(title)
+		}
+		return title
+	}
+
+	builder := shared.GetBuffer()
+	defer shared.PutBuffer(builder)
+	title := msg.ExecutionTitle
+	switch {
+	case profile.TitleEmoji != "":
+		title = profile.TitleEmoji + " " + stripLeadingEmoji(title)
+	case profile.HideEmoji:
+		title = stripLeadingEmoji(title)
+	}
+	writer.WriteTitle(builder, title)
+	if !req.Deadline.IsZero() {
+		writer.WriteLabelValue(builder, labels.DeadlineLabel, formatDeadline(req.SubmittedAt, req.Deadline), true)
+	}
+	if !req.Assignee.IsZero() {
+		writeAssignee(builder, writer, msg, req.Assignee, profile.HideEmoji)
+	}
+
+	currentGroup := ""
+	for _, section := range sections {
+		if !sectionVisible(section, req) {
+			continue
+		}
+		group := sectionGroups[section]
+		if group != currentGroup {
+			if currentGroup != "" {
+				writer.WriteLineBreak(builder)
+			}
+			switch group {
+			case executions.SectionContext:
+				writer.WriteSectionHeader(builder, header(labels.ContextTitle))
+			case executions.SectionTool:
+				writer.WriteSectionHeader(builder, header(labels.ToolTitle))
+			case executions.SectionParams:
+				writer.WriteSectionHeader(builder, header(labels.ParamsTitle))
+			case executions.SectionAction:
+				writer.WriteSectionHeader(builder, header(labels.ActionTitle))
+			}
+			currentGroup = group
+		}
+
+		switch section {
+		case executions.SectionQuestion:
+			writeUserText(builder, writer, labels.QuestionLabel, req.Question, profile.MarkdownInput)
+		case executions.SectionContext:
+			if LooksLikeDiff(req.Context) {
+				writer.WriteLabel(builder, labels.ContextLabel)
+				writer.WriteCodeBlock(builder, "diff", strings.TrimRight(req.Context, "\n"))
+				continue
+			}
+			writeUserText(builder, writer, labels.ContextLabel, req.Context, profile.MarkdownInput)
+		case executions.SectionOptions:
+			writer.WriteOptions(builder, labels.OptionsLabel, req.Options)
+		case executions.SectionTool:
+			writeToolSection(builder, writer, labels, req.Tool)
+		case executions.SectionParams:
+			rest, diffs := SplitDiffArguments(req.Arguments)
+			if len(rest) > 0 || len(diffs) == 0 {
+				writer.WriteCodeBlock(builder, "json", ArgumentsJSON(rest))
+			}
+			for _, diff := range diffs {
+				writer.WriteLabel(builder, diff.Key)
+				writer.WriteCodeBlock(builder, "diff", diff.Value)
+			}
+		case executions.SectionAction:
+			writer.WriteCodeValue(builder, msg.ExecutionTool, req.Tool.Name, false)
+			writer.WriteCodeValue(builder, msg.ExecutionCorrelation, req.CorrelationID, false)
+			if !req.SubmittedAt.IsZero() {
+				writer.WriteLabelValue(builder, labels.SubmittedLabel, req.SubmittedAt.Format(submittedLayout), false)
+			}
+		}
+	}
+	return builder.String()
+}
+
+// writeUserText writes user-supplied question or context; with markdown input its Markdown becomes formatting.
+func writeUserText(builder *bytes.Buffer, writer executionMessageWriter, label, value string, markdown bool) {
+	if markdown {
+		writer.WriteLabelSpans(builder, label, shared.ParseMarkdown(value))
+		return
+	}
+	writer.WriteLabelValue(builder, label, value, false)
+}
+
+// mentionMarker stands for the mention while the localized assignee line is formatted.
+const mentionMarker = "\x00"
+
+// writeAssignee writes the localized "@alice, please review" line; users without username
+// are mentioned with a tg://user link.
+func writeAssignee(builder *bytes.Buffer, writer executionMessageWriter, msg i18n.Messages, assignee executions.Assignee, hideEmoji bool) {
+	line := msg.Format(fallbackText(msg.AssigneeMention, "👤 {mention}, please review."), i18n.Vars{"mention": mentionMarker})
+	if hideEmoji {
+		line = stripLeadingEmoji(line)
+	}
+	before, after, _ := strings.Cut(line, mentionMarker)
+	text, url := "@"+assignee.Username, ""
+	if assignee.Username == "" {
+		text, url = assignee.Name, fmt.Sprintf("tg://user?id=%d", assignee.UserID)
+	}
+	writer.WriteMention(builder, before, text, url, after)
+}
+
+// WithoutSection removes section from the layout (the default layout when sections are empty).
+func WithoutSection(sections []string, section string) []string {
+	if len(sections) == 0 {
+		sections = executions.DefaultSections
+	}
+	return slices.DeleteFunc(slices.Clone(sections), func(value string) bool { return value == section })
+}
+
+// WithSection ensures section is present, inserting it before another section (or at the end).
+func WithSection(sections []string, section, before string) []string {
+	if len(sections) == 0 {
+		sections = executions.DefaultSections
+	}
+	if slices.Contains(sections, section) {
+		return sections
+	}
+	out := slices.Clone(sections)
+	idx := slices.Index(out, before)
+	if idx < 0 {
+		return append(out, section)
+	}
+	return slices.Insert(out, idx, section)
+}
+
+func sectionVisible(section string, req executions.Request) bool {
+	switch section {
+	case executions.SectionContext:
+		return strings.TrimSpace(req.Context) != ""
+	case executions.SectionOptions:
+		return len(req.Options) > 0
+	case executions.SectionTool:
+		return strings.TrimSpace(req.Tool.Title) != "" || strings.TrimSpace(req.Tool.Description) != "" || len(shared.Hashtags(req.Tool.Tags...)) > 0
+	default:
+		return true
+	}
+}
+
+func writeToolSection(builder *bytes.Buffer, writer executionMessageWriter, labels executionLabels, tool executions.Tool) {
+	if value := strings.TrimSpace(tool.Title); value != "" {
+		writer.WriteLabelValue(builder, labels.ToolNameLabel, value, false)
+	}
+	if value := shortDescription(tool.Description); value != "" {
+		writer.WriteLabelValue(builder, labels.ToolDescLabel, value, false)
+	}
+	if tags := shared.Hashtags(tool.Tags...); len(tags) > 0 {
+		writer.WriteLabelValue(builder, labels.ToolTagsLabel, strings.Join(tags, " "), false)
+	}
+}
+
+// toolDescriptionRunes limits the tool description shown in the prompt.
+const toolDescriptionRunes = 200
+
+// shortDescription keeps the first paragraph of a tool description: MCP tool descriptions are often written
+// for the model and run for pages.
+func shortDescription(description string) string {
+	description = strings.TrimSpace(description)
+	if paragraph, _, ok := strings.Cut(description, "\n\n"); ok {
+		description = strings.TrimSpace(paragraph)
+	}
+	return shared.TruncateRunes(strings.Join(strings.Fields(description), " "), toolDescriptionRunes, "…")
+}
+
+const (
+	submittedLayout    = "2006-01-02 15:04 MST"
+	deadlineLayout     = "15:04 MST"
+	deadlineDateLayout = "Jan 2 15:04 MST"
+)
+
+// formatDeadline renders deadline time, adding the date when it falls on another day than submission.
+func formatDeadline(submitted, deadline time.Time) string {
+	if submitted.Year() == deadline.Year() && submitted.YearDay() == deadline.YearDay() {
+		return deadline.Format(deadlineLayout)
+	}
+	return deadline.Format(deadlineDateLayout)
+}
+
+// ArgumentsJSON renders request arguments as indented JSON.
+func ArgumentsJSON(arguments map[string]any) string {
+	data, err := json.MarshalIndent(arguments, "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// stripLeadingEmoji removes a leading pictogram followed by a space ("🧭 Context" -> "Context").
+func stripLeadingEmoji(value string) string {
+	trimmed := strings.TrimSpace(value)
+	first, rest, ok := strings.Cut(trimmed, " ")
+	if !ok || first == "" {
+		return trimmed
+	}
+	if slices.ContainsFunc([]rune(first), func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+		return trimmed
+	}
+	return strings.TrimSpace(rest)
+}
+
+type executionMessageWriter interface {
+	WriteTitle(builder *bytes.Buffer, title string)
+	WriteSectionHeader(builder *bytes.Buffer, title string)
+	WriteLabel(builder *bytes.Buffer, label string)
+	WriteLabelValue(builder *bytes.Buffer, label, value string, addEmptyLine bool)
+	// WriteLabelSpans writes a label with formatted user text.
+	WriteLabelSpans(builder *bytes.Buffer, label string, spans []shared.Span)
+	WriteOptions(builder *bytes.Buffer, label string, options []string)
+	WriteCodeValue(builder *bytes.Buffer, label, value string, addEmptyLine bool)
+	WriteCodeBlock(builder *bytes.Buffer, language, value string)
+	WriteLineBreak(builder *bytes.Buffer)
+	// WriteMention writes a paragraph with a user mention; empty url keeps an @username mention as text.
+	WriteMention(builder *bytes.Buffer, before, mention, url, after string)
+}
+
+type markdownExecutionWriter struct{}
+
+func (markdownExecutionWriter) WriteTitle(builder *bytes.Buffer, title string) {
+	builder.WriteString("*")
+	shared.WriteMarkdownV2(builder, title)
+	builder.WriteString("*\n\n")
+}
+
+func (markdownExecutionWriter) WriteSectionHeader(builder *bytes.Buffer, title string) {
+	builder.WriteString("*")
+	shared.WriteMarkdownV2(builder, title)
+	builder.WriteString("*\n")
+}
+
+func (markdownExecutionWriter) WriteLabel(builder *bytes.Buffer, label string) {
+	builder.WriteString("*")
+	shared.WriteMarkdownV2(builder, label)
+	builder.WriteString(":*\n")
+}
+
+func (markdownExecutionWriter) WriteLabelValue(builder *bytes.Buffer, label, value string, addEmptyLine bool) {
+	builder.WriteString("*")
+	shared.WriteMarkdownV2(builder, label)
+	builder.WriteString(":* ")
+	shared.WriteMarkdownV2(builder, value)
+	builder.WriteString("\n")
+	appendOptionalLineBreak(builder, "\n", addEmptyLine)
+}
+
+func (markdownExecutionWriter) WriteLabelSpans(builder *bytes.Buffer, label string, spans []shared.Span) {
+	builder.WriteString("*")
+	shared.WriteMarkdownV2(builder, label)
+	builder.WriteString(":* ")
+	shared.WriteSpansMarkdownV2(builder, spans)
+	builder.WriteString("\n")
+}
+
+func (markdownExecutionWriter) WriteOptions(builder *bytes.Buffer, label string, options []string) {
+	builder.WriteString("*")
+	shared.WriteMarkdownV2(builder, label)
+	builder.WriteString(":*\n")
+	for idx, option := range options {
+		writeOptionNumber(builder, idx, "\\) ")
+		shared.WriteMarkdownV2(builder, option)
+		builder.WriteString("\n")
+	}
+}
+
+func (markdownExecutionWriter) WriteCodeValue(builder *bytes.Buffer, label, value string, addEmptyLine bool) {
+	builder.WriteString("*")
+	shared.WriteMarkdownV2(builder, label)
+	builder.WriteString(":* `")
+	shared.WriteMarkdownV2Code(builder, value)
+	builder.WriteString("`\n")
+	appendOptionalLineBreak(builder, "\n", addEmptyLine)
+}
+
+func (markdownExecutionWriter) WriteCodeBlock(builder *bytes.Buffer, language, value string) {
+	builder.WriteString("```")
+	builder.WriteString(language)
+	builder.WriteString("\n")
+	shared.WriteMarkdownV2Code(builder, value)
+	builder.WriteString("\n```\n")
+}
+
+func (markdownExecutionWriter) WriteLineBreak(builder *bytes.Buffer) {
+	builder.WriteString("\n")
+}
+
+func (markdownExecutionWriter) WriteMention(builder *bytes.Buffer, before, mention, url, after string) {
+	shared.WriteMarkdownV2(builder, before)
+	if url == "" {
+		shared.WriteMarkdownV2(builder, mention)
+	} else {
+		builder.WriteString("[")
+		shared.WriteMarkdownV2(builder, mention)
+		builder.WriteString("](" + url + ")")
+	}
+	shared.WriteMarkdownV2(builder, after)
+	builder.WriteString("\n\n")
+}
+
+// markdownV1ExecutionWriter renders legacy Telegram Markdown; text inside entities cannot be escaped,
+// so entity delimiters are stripped from it.
+type markdownV1ExecutionWriter struct{}
+
+func markdownV1Bold(value string) string {
+	return "*" + shared.StripMarkdownV1Entity(value, "*") + "*"
+}
+
+func (markdownV1ExecutionWriter) WriteTitle(builder *bytes.Buffer, title string) {
+	builder.WriteString(markdownV1Bold(title))
+	builder.WriteString("\n\n")
+}
+
+func (markdownV1ExecutionWriter) WriteSectionHeader(builder *bytes.Buffer, title string) {
+	builder.WriteString(markdownV1Bold(title))
+	builder.WriteString("\n")
+}
+
+func (markdownV1ExecutionWriter) WriteLabel(builder *bytes.Buffer, label string) {
+	builder.WriteString(markdownV1Bold(label + ":"))
+	builder.WriteString("\n")
+}
+
+func (markdownV1ExecutionWriter) WriteLabelValue(builder *bytes.Buffer, label, value string, addEmptyLine bool) {
+	builder.WriteString(markdownV1Bold(label + ":"))
+	builder.WriteString(" ")
+	shared.WriteMarkdownV1(builder, value)
+	builder.WriteString("\n")
+	appendOptionalLineBreak(builder, "\n", addEmptyLine)
+}
+
+func (markdownV1ExecutionWriter) WriteLabelSpans(builder *bytes.Buffer, label string, spans []shared.Span) {
+	builder.WriteString(markdownV1Bold(label + ":"))
+	builder.WriteString(" ")
+	shared.WriteSpansMarkdownV1(builder, spans)
+	builder.WriteString("\n")
+}
+
+func (markdownV1ExecutionWriter) WriteOptions(builder *bytes.Buffer, label string, options []string) {
+	builder.WriteString(markdownV1Bold(label + ":"))
+	builder.WriteString("\n")
+	for idx, option := range options {
+		writeOptionNumber(builder, idx, ") ")
+		shared.WriteMarkdownV1(builder, option)
+		builder.WriteString("\n")
+	}
+}
+
+func (markdownV1ExecutionWriter) WriteCodeValue(builder *bytes.Buffer, label, value string, addEmptyLine bool) {
+	builder.WriteString(markdownV1Bold(label + ":"))
+	builder.WriteString(" `")
+	builder.WriteString(shared.StripMarkdownV1Entity(value, "`"))
+	builder.WriteString("`\n")
+	appendOptionalLineBreak(builder, "\n", addEmptyLine)
+}
+
+func (markdownV1ExecutionWriter) WriteCodeBlock(builder *bytes.Buffer, language, value string) {
+	builder.WriteString("```")
+	builder.WriteString(language)
+	builder.WriteString("\n")
+	builder.WriteString(shared.StripMarkdownV1Entity(value, "```"))
+	builder.WriteString("\n```\n")
+}
+
+func (markdownV1ExecutionWriter) WriteLineBreak(builder *bytes.Buffer) {
+	builder.WriteString("\n")
+}
+
+func (markdownV1ExecutionWriter) WriteMention(builder *bytes.Buffer, before, mention, url, after string) {
+	shared.WriteMarkdownV1(builder, before)
+	if url == "" {
+		shared.WriteMarkdownV1(builder, mention)
+	} else {
+		builder.WriteString("[" + shared.StripMarkdownV1Entity(mention, "]") + "](" + url + ")")
+	}
+	shared.WriteMarkdownV1(builder, after)
+	builder.WriteString("\n\n")
+}
+
+// htmlExecutionWriter renders Telegram HTML; Telegram does not support <br>, so plain newlines are used.
+type htmlExecutionWriter struct{}
+
+func (htmlExecutionWriter) WriteTitle(builder *bytes.Buffer, title string) {
+	builder.WriteString("<b>")
+	shared.WriteHTML(builder, title)
+	builder.WriteString("</b>\n\n")
+}
+
+func (htmlExecutionWriter) WriteSectionHeader(builder *bytes.Buffer, title string) {
+	builder.WriteString("<b>")
+	shared.WriteHTML(builder, title)
+	builder.WriteString("</b>\n")
+}
+
+func (htmlExecutionWriter) WriteLabel(builder *bytes.Buffer, label string) {
+	builder.WriteString("<b>")
+	shared.WriteHTML(builder, label)
+	builder.WriteString(":</b>\n")
+}
+
+func (htmlExecutionWriter) WriteLabelValue(builder *bytes.Buffer, label, value string, addEmptyLine bool) {
+	builder.WriteString("<b>")
+	shared.WriteHTML(builder, label)
+	builder.WriteString(":</b> ")
+	shared.WriteHTML(builder, value)
+	builder.WriteString("\n")
+	appendOptionalLineBreak(builder, "\n", addEmptyLine)
+}
+
+func (htmlExecutionWriter) WriteLabelSpans(builder *bytes.Buffer, label string, spans []shared.Span) {
+	builder.WriteString("<b>")
+	shared.WriteHTML(builder, label)
+	builder.WriteString(":</b> ")
+	shared.WriteSpansHTML(builder, spans)
+	builder.WriteString("\n")
+}
+
+func (htmlExecutionWriter) WriteOptions(builder *bytes.Buffer, label string, options []string) {
+	builder.WriteString("<b>")
+	shared.WriteHTML(builder, label)
+	builder.WriteString(":</b>\n")
+	for idx, option := range options {
+		writeOptionNumber(builder, idx, ") ")
+		shared.WriteHTML(builder, option)
+		builder.WriteString("\n")
+	}
+}
+
+func (htmlExecutionWriter) WriteCodeValue(builder *bytes.Buffer, label, value string, addEmptyLine bool) {
+	builder.WriteString("<b>")
+	shared.WriteHTML(builder, label)
+	builder.WriteString(":</b> <code>")
+	shared.WriteHTML(builder, value)
+	builder.WriteString("</code>\n")
+	appendOptionalLineBreak(builder, "\n", addEmptyLine)
+}
+
+func (htmlExecutionWriter) WriteCodeBlock(builder *bytes.Buffer, language, value string) {
+	builder.WriteString(`<pre><code class="language-`)
+	shared.WriteHTML(builder, language)
+	builder.WriteString(`">`)
+	shared.WriteHTML(builder, value)
+	builder.WriteString("</code></pre>\n")
+}
+
+func (htmlExecutionWriter) WriteLineBreak(builder *bytes.Buffer) {
+	builder.WriteString("\n")
+}
+
+func (htmlExecutionWriter) WriteMention(builder *bytes.Buffer, before, mention, url, after string) {
+	shared.WriteHTML(builder, before)
+	if url == "" {
+		shared.WriteHTML(builder, mention)
+	} else {
+		builder.WriteString(`<a href="`)
+		shared.WriteHTML(builder, url)
+		builder.WriteString(`">`)
+		shared.WriteHTML(builder, mention)
+		builder.WriteString("</a>")
+	}
+	shared.WriteHTML(builder, after)
+	builder.WriteString("\n\n")
+}
+
+// writeOptionNumber writes the 1-based option number followed by suffix.
+func writeOptionNumber(builder *bytes.Buffer, idx int, suffix string) {
+	builder.WriteString(strconv.Itoa(idx + 1))
+	builder.WriteString(suffix)
+}
+
+func appendOptionalLineBreak(builder *bytes.Buffer, lineBreak string, enabled bool) {
+	if enabled {
+		builder.WriteString(lineBreak)
+	}
+}
+
+type executionLabels struct {
+	ContextTitle   string
+	ToolTitle      string
+	ParamsTitle    string
+	ActionTitle    string
+	QuestionLabel  string
+	ContextLabel   string
+	OptionsLabel   string
+	ToolNameLabel  string
+	ToolDescLabel  string
+	ToolTagsLabel  string
+	DeadlineLabel  string
+	SubmittedLabel string
+}
+
+func executionLabelsFor(msg i18n.Messages) executionLabels {
+	return executionLabels{
+		ContextTitle:   fallbackText(msg.SectionContext, "Context"),
+		ToolTitle:      fallbackText(msg.SectionTool, "Tool"),
+		ParamsTitle:    fallbackText(msg.SectionParams, "Parameters"),
+		ActionTitle:    fallbackText(msg.SectionAction, "Action"),
+		QuestionLabel:  fallbackText(msg.QuestionLabel, "Question"),
+		ContextLabel:   fallbackText(msg.ContextLabel, "Context"),
+		OptionsLabel:   fallbackText(msg.OptionsLabel, "Options"),
+		ToolNameLabel:  fallbackText(msg.ToolTitleLabel, "Title"),
+		ToolDescLabel:  fallbackText(msg.ToolDescriptionLabel, "Description"),
+		ToolTagsLabel:  fallbackText(msg.ToolTagsLabel, "Tags"),
+		DeadlineLabel:  fallbackText(msg.DeadlineLabel, "Answer before"),
+		SubmittedLabel: fallbackText(msg.SubmittedLabel, "Submitted"),
+	}
+}
+
+func fallbackText(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,124 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/testutil"
+)
+
+// testRequest is a prompt showing every section, with Telegram special characters in every user-supplied field.
+func testRequest(markup string) executions.Request {
+	submitted := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	return executions.Request{
+		CorrelationID: "req_1*2",
+		Tool:          executions.Tool{Name: "deploy_service", Title: "Deploy [billing] service"},
+		Arguments: map[string]any{
+			"image":    "billing-api:v1.2.3",
+			"replicas": 3,
+			"note":     "use `kubectl` & <helm> *carefully*",
+		},
+		Question: "Roll out billing-api_v2 to *prod* (eu-1)?",
+		Context:  "Error rate is <1% & p99 = 120ms [dashboard](https://grafana.example/d/1?a=b_c)",
+		Options:  []string{"Canary 10%", "Blue/green", "Delay_rollout"},
+		Markup:   markup,
+		Render: executions.RenderProfile{Sections: []string{
+			executions.SectionTool, executions.SectionQuestion, executions.SectionContext, executions.SectionOptions,
+			executions.SectionParams, executions.SectionAction,
+		}},
+		Assignee:    executions.Assignee{UserID: 42, Name: "Alice_Ops"},
+		SubmittedAt: submitted,
+		Deadline:    submitted.Add(90 * time.Minute),
+	}
+}
+
+func testMessages(t testing.TB) i18n.Messages {
+	t.Helper()
+	bundle, err := i18n.Load("en", "")
+	if err != nil {
+		t.Fatalf("load messages: %v", err)
+	}
+	return bundle.Messages
+}
+
+func TestPrompt(t *testing.T) {
+	msg := testMessages(t)
+	for _, markup := range []string{executions.MarkupMarkdown, executions.MarkupMarkdownV1, executions.MarkupHTML} {
+		t.Run(markup, func(t *testing.T) {
+			got := Prompt(msg, testRequest(markup))
+			if got.Entities != nil {
+				t.Errorf("entities set in %s markup: %v", markup, got.Entities)
+			}
+			testutil.Golden(t, "prompt_"+markup, []byte(got.Text))
+		})
+	}
+	t.Run(executions.MarkupEntities, func(t *testing.T) {
+		got := Prompt(msg, testRequest(executions.MarkupEntities))
+		if len(got.Entities) == 0 {
+			t.Error("no entities in entities markup")
+		}
+		testutil.Golden(t, "prompt_entities", marshalGolden(t, got))
+	})
+}
+
+func TestKeyboard(t *testing.T) {
+	msg := testMessages(t)
+	req := testRequest(executions.MarkupMarkdown)
+	req.AllowCustom = true
+	req.Render.CollapseParams = true
+	req.Keyboard = executions.KeyboardLayout{Columns: 2, OptionEmoji: []string{"🐤"}}
+	got, err := Keyboard(msg, req)
+	if err != nil {
+		t.Fatalf("Keyboard: %v", err)
+	}
+	testutil.Golden(t, "keyboard", marshalGolden(t, got))
+}
+
+func TestKeyboardLimits(t *testing.T) {
+	msg := testMessages(t)
+	tests := []struct {
+		name   string
+		modify func(*executions.Request)
+	}{
+		{name: "columns", modify: func(req *executions.Request) { req.Keyboard.Columns = MaxRowButtons + 1 }},
+		{name: "callback data", modify: func(req *executions.Request) {
+			req.CorrelationID = strings.Repeat("x", MaxCallbackDataBytes)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testRequest(executions.MarkupMarkdown)
+			tt.modify(&req)
+			if _, err := Keyboard(msg, req); !errors.Is(err, ErrInvalidKeyboard) {
+				t.Errorf("Keyboard error = %v, want ErrInvalidKeyboard", err)
+			}
+		})
+	}
+}
+
+func marshalGolden(t testing.TB, value any) []byte {
+	t.Helper()
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return append(data, '\n')
+}
+
+func BenchmarkPrompt(b *testing.B) {
+	msg := testMessages(b)
+	for _, markup := range []string{executions.MarkupMarkdown, executions.MarkupMarkdownV1, executions.MarkupHTML, executions.MarkupEntities} {
+		req := testRequest(markup)
+		b.Run(markup, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				Prompt(msg, req)
+			}
+		})
+	}
+}
@@ -0,0 +1,2 @@
+// Package reporting forwards errors and panics to an external error tracker.
+package reporting
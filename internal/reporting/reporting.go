@@ -0,0 +1,61 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+)
+
+// Tag keys used across the service.
+const (
+	// TagComponent identifies the subsystem that produced the error.
+	TagComponent = "component"
+	// TagOperation identifies the failed operation.
+	TagOperation = "operation"
+	// TagCorrelationID links the error to an execution request.
+	TagCorrelationID = "correlation_id"
+)
+
+// Reporter receives errors that deserve operator attention.
+type Reporter interface {
+	// Report sends an error with tags to the error tracker.
+	Report(ctx context.Context, err error, tags map[string]string)
+	// Close flushes pending reports.
+	Close(ctx context.Context) error
+}
+
+// Nop discards all reports.
+type Nop struct{}
+
+// Report discards the error.
+func (Nop) Report(context.Context, error, map[string]string) {}
+
+// Close is a no-op.
+func (Nop) Close(context.Context) error { return nil }
+
+// New creates Sentry reporter when DSN is set and Nop otherwise.
+func New(dsn, environment, release string, log *slog.Logger) (Reporter, error) {
+	if strings.TrimSpace(dsn) == "" {
+		return Nop{}, nil
+	}
+	return NewSentry(dsn, environment, release, log)
+}
+
+// PanicError converts recovered panic value into an error with stack trace.
+func PanicError(recovered any) error {
+	return fmt.Errorf("panic: %v\n%s", recovered, debug.Stack())
+}
+
+// Tags builds tags map from key/value pairs, skipping empty values.
+func Tags(pairs ...string) map[string]string {
+	tags := make(map[string]string, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			continue
+		}
+		tags[pairs[i]] = pairs[i+1]
+	}
+	return tags
+}
@@ -0,0 +1,195 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sentryQueueSize   = 64
+	sentryHTTPTimeout = 5 * time.Second
+)
+
+// Sentry reports errors to Sentry store API.
+type Sentry struct {
+	endpoint    string
+	auth        string
+	environment string
+	release     string
+	serverName  string
+	client      *http.Client
+	queue       chan sentryEvent
+	wg          sync.WaitGroup
+	mu          sync.Mutex
+	closed      bool
+	log         *slog.Logger
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Message     string            `json:"message"`
+	Exception   sentryExceptions  `json:"exception"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// NewSentry creates Sentry reporter from DSN (https://key@host/project).
+func NewSentry(dsn, environment, release string, log *slog.Logger) (*Sentry, error) {
+	endpoint, key, err := parseSentryDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	serverName, _ := os.Hostname()
+	s := &Sentry{
+		endpoint:    endpoint,
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=telegram-executor/%s, sentry_key=%s", release, key),
+		environment: environment,
+		release:     release,
+		serverName:  serverName,
+		client:      &http.Client{Timeout: sentryHTTPTimeout},
+		queue:       make(chan sentryEvent, sentryQueueSize),
+		log:         log,
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Report enqueues error for asynchronous delivery; reports are dropped when the queue is full.
+func (s *Sentry) Report(_ context.Context, err error, tags map[string]string) {
+	if err == nil {
+		return
+	}
+	event := sentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Level:       "error",
+		Platform:    "go",
+		Logger:      "telegram-executor",
+		ServerName:  s.serverName,
+		Release:     s.release,
+		Environment: s.environment,
+		Message:     err.Error(),
+		Exception: sentryExceptions{Values: []sentryException{{
+			Type:  fmt.Sprintf("%T", err),
+			Value: err.Error(),
+		}}},
+		Tags: tags,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.queue <- event:
+	default:
+		s.log.Warn("Sentry report dropped: queue full")
+	}
+}
+
+// Close flushes queued reports until context deadline.
+func (s *Sentry) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Sentry) run() {
+	defer s.wg.Done()
+	for event := range s.queue {
+		if err := s.send(event); err != nil {
+			s.log.Warn("Sentry delivery failed", "error", err)
+		}
+	}
+}
+
+func (s *Sentry) send(event sentryEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func parseSentryDSN(dsn string) (string, string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(dsn))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid sentry dsn: %w", err)
+	}
+	if parsed.User == nil || parsed.User.Username() == "" {
+		return "", "", fmt.Errorf("invalid sentry dsn: public key is missing")
+	}
+	path := strings.Trim(parsed.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	projectID := path[idx+1:]
+	prefix := ""
+	if idx >= 0 {
+		prefix = "/" + path[:idx]
+	}
+	if projectID == "" {
+		return "", "", fmt.Errorf("invalid sentry dsn: project id is missing")
+	}
+	endpoint := fmt.Sprintf("%s://%s%s/api/%s/store/", parsed.Scheme, parsed.Host, prefix, projectID)
+	return endpoint, parsed.User.Username(), nil
+}
+
+func newEventID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
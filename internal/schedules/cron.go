@@ -0,0 +1,141 @@
+package schedules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit bounds the search for the next run of expressions that never fire, such as "0 0 31 2 *".
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+var cronMacros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// Cron is a parsed five-field cron expression: minute, hour, day of month, month and day of week.
+type Cron struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday record "*" fields: when both day fields are restricted either may match.
+	anyDay, anyWeekday bool
+}
+
+// ParseCron parses a cron expression. Fields accept "*", numbers, ranges "1-5", lists "1,15" and steps "*/10",
+// "9-17/2"; day of week is 0-7 with both 0 and 7 meaning Sunday. @hourly, @daily, @weekly, @monthly and
+// @yearly are accepted as well.
+func ParseCron(expr string) (Cron, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Cron{}, fmt.Errorf("cron must have 5 fields: minute hour day month weekday")
+	}
+	var cron Cron
+	var err error
+	if cron.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return Cron{}, fmt.Errorf("cron minute: %w", err)
+	}
+	if cron.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return Cron{}, fmt.Errorf("cron hour: %w", err)
+	}
+	if cron.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return Cron{}, fmt.Errorf("cron day: %w", err)
+	}
+	if cron.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return Cron{}, fmt.Errorf("cron month: %w", err)
+	}
+	if cron.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return Cron{}, fmt.Errorf("cron weekday: %w", err)
+	}
+	if cron.weekdays&(1<<7) != 0 {
+		cron.weekdays |= 1
+	}
+	cron.anyDay, cron.anyWeekday = fields[2] == "*", fields[4] == "*"
+	return cron, nil
+}
+
+func parseCronField(field string, minValue, maxValue int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			value, err := strconv.Atoi(stepPart)
+			if err != nil || value < 1 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+			step = value
+		}
+		low, high := minValue, maxValue
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+			if high, err = strconv.Atoi(to); err != nil {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			low, high = value, value
+			if hasStep {
+				high = maxValue
+			}
+		}
+		if low < minValue || high > maxValue || low > high {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, minValue, maxValue)
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// Matches reports whether the cron fires at the minute of t, in the location of t.
+func (c Cron) Matches(t time.Time) bool {
+	return c.minutes&(1<<t.Minute()) != 0 && c.hours&(1<<t.Hour()) != 0 && c.months&(1<<int(t.Month())) != 0 && c.dayMatches(t)
+}
+
+func (c Cron) dayMatches(t time.Time) bool {
+	day := c.days&(1<<t.Day()) != 0
+	weekday := c.weekdays&(1<<int(t.Weekday())) != 0
+	if !c.anyDay && !c.anyWeekday {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// Next returns the first minute after t the cron fires at, in the location of t, or zero time if it never does.
+func (c Cron) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+	for next.Before(limit) {
+		switch {
+		case c.months&(1<<int(next.Month())) == 0:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !c.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case c.hours&(1<<next.Hour()) == 0:
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case c.minutes&(1<<next.Minute()) == 0:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,254 @@
+package schedules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/state"
+)
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,47}$`)
+
+// ErrNotFound is returned for unknown schedules.
+var ErrNotFound = errors.New("schedule not found")
+
+// Schedule is a recurring check-in: every time Cron fires in Timezone, Execute is submitted on behalf of Tenant
+// as an /execute request with a correlation id derived from ID and the run time.
+type Schedule struct {
+	ID       string `json:"id"`
+	Tenant   string `json:"tenant,omitempty"`
+	Cron     string `json:"cron"`
+	Timezone string `json:"timezone"`
+	// Execute is the /execute payload of every run, without correlation_id.
+	Execute   json.RawMessage `json:"execute"`
+	CreatedAt time.Time       `json:"created_at"`
+	// LastRun, LastCorrelationID and LastError describe the latest run.
+	LastRun           *time.Time `json:"last_run,omitempty"`
+	LastCorrelationID string     `json:"last_correlation_id,omitempty"`
+	LastError         string     `json:"last_error,omitempty"`
+}
+
+// Next returns the next run of a registered schedule after t, or zero time if the cron never fires.
+func (s Schedule) Next(t time.Time) time.Time {
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}
+	}
+	return cron.Next(t.In(location))
+}
+
+// RunID is the correlation id of the run at the minute of t.
+func (s Schedule) RunID(t time.Time) string {
+	return s.ID + "-" + t.UTC().Format("200601021504")
+}
+
+type entry struct {
+	schedule Schedule
+	cron     Cron
+	location *time.Location
+}
+
+// Submitter starts a run of the schedule under the correlation id.
+type Submitter func(ctx context.Context, schedule Schedule, correlationID string) error
+
+// Registry holds schedules by namespaced ID and keeps them in the state file.
+type Registry struct {
+	store           *state.Store
+	defaultTimezone string
+	log             *slog.Logger
+
+	mu        sync.Mutex
+	schedules map[string]*entry
+}
+
+// NewRegistry creates a registry with the schedules saved in the store; defaultTimezone applies to schedules
+// created without one.
+func NewRegistry(store *state.Store, defaultTimezone string, log *slog.Logger) *Registry {
+	r := &Registry{store: store, defaultTimezone: defaultTimezone, log: log, schedules: make(map[string]*entry)}
+	for key, raw := range store.Schedules() {
+		var schedule Schedule
+		if err := json.Unmarshal(raw, &schedule); err != nil {
+			log.Warn("Dropping unreadable schedule", "error", err, "schedule", key)
+			_ = store.DeleteSchedule(key)
+			continue
+		}
+		item, err := r.entryOf(schedule)
+		if err != nil {
+			log.Warn("Dropping invalid schedule", "error", err, "schedule", key)
+			_ = store.DeleteSchedule(key)
+			continue
+		}
+		r.schedules[key] = item
+	}
+	return r
+}
+
+// entryOf validates the schedule and parses its cron and timezone.
+func (r *Registry) entryOf(schedule Schedule) (*entry, error) {
+	if !idPattern.MatchString(schedule.ID) {
+		return nil, fmt.Errorf("id must match %s", idPattern)
+	}
+	cron, err := ParseCron(schedule.Cron)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", schedule.Timezone)
+	}
+	if cron.Next(time.Now().In(location)).IsZero() {
+		return nil, fmt.Errorf("cron %q never fires", schedule.Cron)
+	}
+	return &entry{schedule: schedule, cron: cron, location: location}, nil
+}
+
+// Put validates and stores the schedule, replacing one with the same ID, and returns it as stored.
+func (r *Registry) Put(schedule Schedule) (Schedule, error) {
+	schedule.Cron = strings.TrimSpace(schedule.Cron)
+	schedule.Timezone = strings.TrimSpace(schedule.Timezone)
+	if schedule.Timezone == "" {
+		schedule.Timezone = r.defaultTimezone
+	}
+	schedule.CreatedAt = time.Now()
+	schedule.LastRun, schedule.LastCorrelationID, schedule.LastError = nil, "", ""
+	item, err := r.entryOf(schedule)
+	if err != nil {
+		return Schedule{}, err
+	}
+	key := executions.NamespacedID(schedule.Tenant, schedule.ID)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schedules[key] = item
+	r.saveLocked(key)
+	return schedule, nil
+}
+
+// Get returns the schedule of the tenant.
+func (r *Registry) Get(tenant, id string) (Schedule, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.schedules[executions.NamespacedID(tenant, id)]
+	if !ok {
+		return Schedule{}, false
+	}
+	return item.schedule, true
+}
+
+// Delete removes the schedule of the tenant; runs already submitted are not affected.
+func (r *Registry) Delete(tenant, id string) error {
+	key := executions.NamespacedID(tenant, id)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.schedules[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.schedules, key)
+	if err := r.store.DeleteSchedule(key); err != nil {
+		r.log.Warn("Failed to delete schedule", "error", err, "schedule", key)
+	}
+	return nil
+}
+
+// List returns schedules of the tenant sorted by ID.
+func (r *Registry) List(tenant string) []Schedule {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Schedule, 0, len(r.schedules))
+	for _, item := range r.schedules {
+		if item.schedule.Tenant == tenant {
+			out = append(out, item.schedule)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Run submits due schedules at the start of every minute until ctx is done. Submitting can take longer than a
+// minute (Telegram retries, the outbound rate limit), so every minute since the last processed one is run, late
+// rather than never.
+func (r *Registry) Run(ctx context.Context, submit Submitter) {
+	last := time.Now().Truncate(time.Minute)
+	for {
+		now := time.Now()
+		timer := time.NewTimer(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			for minute := last.Add(time.Minute); !minute.After(time.Now()) && ctx.Err() == nil; minute = minute.Add(time.Minute) {
+				r.runDue(ctx, minute, submit)
+				last = minute
+			}
+		}
+	}
+}
+
+// runDue submits schedules firing at the minute one by one and records the outcome of each run.
+func (r *Registry) runDue(ctx context.Context, minute time.Time, submit Submitter) {
+	r.mu.Lock()
+	var due []string
+	for key, item := range r.schedules {
+		if item.cron.Matches(minute.In(item.location)) {
+			due = append(due, key)
+		}
+	}
+	r.mu.Unlock()
+	sort.Strings(due)
+	for _, key := range due {
+		r.mu.Lock()
+		item, ok := r.schedules[key]
+		r.mu.Unlock()
+		if !ok {
+			continue
+		}
+		schedule := item.schedule
+		correlationID := schedule.RunID(minute)
+		err := submit(ctx, schedule, correlationID)
+		if err != nil {
+			r.log.WarnContext(ctx, "Scheduled check-in failed", "error", err, "schedule", key, "correlation_id", correlationID)
+		} else {
+			r.log.InfoContext(ctx, "Scheduled check-in submitted", "schedule", key, "correlation_id", correlationID)
+		}
+		r.recordRun(key, minute, correlationID, err)
+	}
+}
+
+func (r *Registry) recordRun(key string, at time.Time, correlationID string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.schedules[key]
+	if !ok {
+		return
+	}
+	item.schedule.LastRun = &at
+	item.schedule.LastCorrelationID = correlationID
+	item.schedule.LastError = ""
+	if err != nil {
+		item.schedule.LastError = err.Error()
+	}
+	r.saveLocked(key)
+}
+
+func (r *Registry) saveLocked(key string) {
+	raw, err := json.Marshal(r.schedules[key].schedule)
+	if err == nil {
+		err = r.store.SaveSchedule(key, raw)
+	}
+	if err != nil {
+		r.log.Warn("Failed to persist schedule", "error", err, "schedule", key)
+	}
+}
@@ -0,0 +1,2 @@
+// Package state persists small pieces of bot state (chat preferences, supergroup migrations) across restarts.
+package state
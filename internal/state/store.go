@@ -0,0 +1,243 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// data is the persisted document.
+type data struct {
+	// ChatLangs maps chat ID to preferred language set with /lang.
+	ChatLangs map[string]string `json:"chat_langs,omitempty"`
+	// ChatTimezones maps chat ID to display timezone set with /tz.
+	ChatTimezones map[string]string `json:"chat_timezones,omitempty"`
+	// ChatMigrations maps group chat ID to the supergroup ID it was upgraded to.
+	ChatMigrations map[string]int64 `json:"chat_migrations,omitempty"`
+	// Executions maps correlation ID to the snapshot of a pending execution restored after restart.
+	Executions map[string]json.RawMessage `json:"executions,omitempty"`
+	// Schedules maps namespaced schedule ID to a recurring check-in created with POST /schedules.
+	Schedules map[string]json.RawMessage `json:"schedules,omitempty"`
+}
+
+// Store keeps state in memory and writes it to a JSON file on every change.
+// With empty path the state lives in memory only.
+type Store struct {
+	path string
+
+	mu   sync.RWMutex
+	data data
+}
+
+// Open loads state from path; a missing file starts with empty state.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	if path == "" {
+		return s, nil
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state file: %w", err)
+	}
+	if err := json.Unmarshal(raw, &s.data); err != nil {
+		return nil, fmt.Errorf("parse state file: %w", err)
+	}
+	return s, nil
+}
+
+// ChatLang returns preferred language of the chat or empty string.
+func (s *Store) ChatLang(chatID int64) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.ChatLangs[strconv.FormatInt(chatID, 10)]
+}
+
+// SetChatLang stores preferred language of the chat; empty lang removes the preference.
+func (s *Store) SetChatLang(chatID int64, lang string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strconv.FormatInt(chatID, 10)
+	if lang == "" {
+		delete(s.data.ChatLangs, key)
+	} else {
+		if s.data.ChatLangs == nil {
+			s.data.ChatLangs = make(map[string]string)
+		}
+		s.data.ChatLangs[key] = lang
+	}
+	return s.saveLocked()
+}
+
+// ChatTimezone returns display timezone of the chat or empty string.
+func (s *Store) ChatTimezone(chatID int64) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.ChatTimezones[strconv.FormatInt(chatID, 10)]
+}
+
+// SetChatTimezone stores display timezone of the chat; empty name removes the preference.
+func (s *Store) SetChatTimezone(chatID int64, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strconv.FormatInt(chatID, 10)
+	if name == "" {
+		delete(s.data.ChatTimezones, key)
+	} else {
+		if s.data.ChatTimezones == nil {
+			s.data.ChatTimezones = make(map[string]string)
+		}
+		s.data.ChatTimezones[key] = name
+	}
+	return s.saveLocked()
+}
+
+// MigratedChat returns the current ID of the chat, following recorded supergroup migrations.
+func (s *Store) MigratedChat(chatID int64) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for range len(s.data.ChatMigrations) {
+		next, ok := s.data.ChatMigrations[strconv.FormatInt(chatID, 10)]
+		if !ok {
+			break
+		}
+		chatID = next
+	}
+	return chatID
+}
+
+// MigrateChat records that the group was upgraded to a supergroup and moves its preferences.
+func (s *Store) MigrateChat(fromChatID, toChatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	from, to := strconv.FormatInt(fromChatID, 10), strconv.FormatInt(toChatID, 10)
+	if s.data.ChatMigrations == nil {
+		s.data.ChatMigrations = make(map[string]int64)
+	}
+	s.data.ChatMigrations[from] = toChatID
+	moveKey(s.data.ChatLangs, from, to)
+	moveKey(s.data.ChatTimezones, from, to)
+	return s.saveLocked()
+}
+
+// Executions returns snapshots of pending executions saved before restart.
+func (s *Store) Executions() map[string]json.RawMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]json.RawMessage, len(s.data.Executions))
+	for id, raw := range s.data.Executions {
+		out[id] = raw
+	}
+	return out
+}
+
+// DeleteExecution removes the snapshot of a resolved execution.
+func (s *Store) DeleteExecution(correlationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data.Executions[correlationID]; !ok {
+		return nil
+	}
+	delete(s.data.Executions, correlationID)
+	return s.saveLocked()
+}
+
+// UpdateExecutions stores the snapshots of pending executions and removes the deleted ones with a single write;
+// without a state file nothing is kept.
+func (s *Store) UpdateExecutions(saved map[string]json.RawMessage, deleted []string) error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Executions == nil {
+		s.data.Executions = make(map[string]json.RawMessage)
+	}
+	for correlationID, snapshot := range saved {
+		s.data.Executions[correlationID] = snapshot
+	}
+	for _, correlationID := range deleted {
+		delete(s.data.Executions, correlationID)
+	}
+	return s.saveLocked()
+}
+
+// Schedules returns stored recurring check-ins.
+func (s *Store) Schedules() map[string]json.RawMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]json.RawMessage, len(s.data.Schedules))
+	for id, raw := range s.data.Schedules {
+		out[id] = raw
+	}
+	return out
+}
+
+// SaveSchedule stores a recurring check-in; without a state file nothing is kept.
+func (s *Store) SaveSchedule(id string, schedule json.RawMessage) error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Schedules == nil {
+		s.data.Schedules = make(map[string]json.RawMessage)
+	}
+	s.data.Schedules[id] = schedule
+	return s.saveLocked()
+}
+
+// DeleteSchedule removes a recurring check-in.
+func (s *Store) DeleteSchedule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data.Schedules[id]; !ok {
+		return nil
+	}
+	delete(s.data.Schedules, id)
+	return s.saveLocked()
+}
+
+func moveKey(prefs map[string]string, from, to string) {
+	value, ok := prefs[from]
+	if !ok {
+		return
+	}
+	delete(prefs, from)
+	if _, exists := prefs[to]; !exists {
+		prefs[to] = value
+	}
+}
+
+// saveLocked writes state atomically via temp file and rename.
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	return nil
+}
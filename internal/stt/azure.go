@@ -0,0 +1,69 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AzureTranscriber uses Azure AI Speech short-audio REST API (it has no phrase list support, hints are ignored).
+type AzureTranscriber struct {
+	client  *http.Client
+	key     string
+	region  string
+	timeout time.Duration
+	log     *slog.Logger
+}
+
+// NewAzureTranscriber initializes Azure AI Speech client.
+func NewAzureTranscriber(key, region string, timeout time.Duration, log *slog.Logger) *AzureTranscriber {
+	return &AzureTranscriber{client: &http.Client{}, key: key, region: strings.TrimSpace(region), timeout: timeout, log: log}
+}
+
+// AudioFormat reports that Azure short-audio API expects WAV input.
+func (t *AzureTranscriber) AudioFormat() string {
+	return FormatWAV
+}
+
+// Transcribe converts audio to text.
+func (t *AzureTranscriber) Transcribe(ctx context.Context, reader io.Reader, filename, contentType, language string, hints []string) (string, error) {
+	data, err := readAudio(reader)
+	if err != nil {
+		return "", err
+	}
+	transcribeCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("https://%s.stt.speech.microsoft.com/speech/recognition/conversation/cognitiveservices/v1?language=%s&format=simple",
+		url.PathEscape(t.region), url.QueryEscape(regionalLanguage(language)))
+	req, err := http.NewRequestWithContext(transcribeCtx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", t.key)
+	req.Header.Set("Content-Type", "audio/wav; codecs=audio/pcm; samplerate=16000")
+	req.Header.Set("Accept", "application/json")
+
+	var resp struct {
+		RecognitionStatus string `json:"RecognitionStatus"`
+		DisplayText       string `json:"DisplayText"`
+	}
+	if err := doJSON(t.client, req, &resp); err != nil {
+		t.log.Error("Azure transcription failed", "error", err)
+		return "", err
+	}
+	if resp.RecognitionStatus != "" && resp.RecognitionStatus != "Success" {
+		return "", fmt.Errorf("azure recognition status %s", resp.RecognitionStatus)
+	}
+	text := strings.TrimSpace(resp.DisplayText)
+	if text == "" {
+		return "", emptyResult()
+	}
+	return text, nil
+}
@@ -0,0 +1,86 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	deepgramListenURL        = "https://api.deepgram.com/v1/listen"
+	deepgramMaxKeywordLength = 40
+)
+
+// DeepgramTranscriber uses Deepgram pre-recorded audio API.
+type DeepgramTranscriber struct {
+	client  *http.Client
+	apiKey  string
+	model   string
+	timeout time.Duration
+	log     *slog.Logger
+}
+
+// NewDeepgramTranscriber initializes Deepgram client.
+func NewDeepgramTranscriber(apiKey, model string, timeout time.Duration, log *slog.Logger) *DeepgramTranscriber {
+	return &DeepgramTranscriber{client: &http.Client{}, apiKey: apiKey, model: model, timeout: timeout, log: log}
+}
+
+// Transcribe converts audio to text.
+func (t *DeepgramTranscriber) Transcribe(ctx context.Context, reader io.Reader, filename, contentType, language string, hints []string) (string, error) {
+	data, err := readAudio(reader)
+	if err != nil {
+		return "", err
+	}
+	transcribeCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	if contentType == "" {
+		contentType = "audio/mpeg"
+	}
+	query := url.Values{}
+	query.Set("smart_format", "true")
+	if t.model != "" {
+		query.Set("model", t.model)
+	}
+	if language != "" {
+		query.Set("language", language)
+	} else {
+		query.Set("detect_language", "true")
+	}
+	for _, keyword := range phrasesFromHints(hints, deepgramMaxKeywordLength) {
+		query.Add("keywords", keyword)
+	}
+	req, err := http.NewRequestWithContext(transcribeCtx, http.MethodPost, deepgramListenURL+"?"+query.Encode(), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Token "+t.apiKey)
+	req.Header.Set("Content-Type", contentType)
+
+	var resp struct {
+		Results struct {
+			Channels []struct {
+				Alternatives []struct {
+					Transcript string `json:"transcript"`
+				} `json:"alternatives"`
+			} `json:"channels"`
+		} `json:"results"`
+	}
+	if err := doJSON(t.client, req, &resp); err != nil {
+		t.log.Error("Deepgram transcription failed", "error", err)
+		return "", err
+	}
+	for _, channel := range resp.Results.Channels {
+		if len(channel.Alternatives) > 0 {
+			if text := strings.TrimSpace(channel.Alternatives[0].Transcript); text != "" {
+				return text, nil
+			}
+		}
+	}
+	return "", emptyResult()
+}
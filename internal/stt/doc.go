@@ -0,0 +1,2 @@
+// Package stt provides speech-to-text backends for voice answers.
+package stt
@@ -0,0 +1,117 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	googleRecognizeURL    = "https://speech.googleapis.com/v1p1beta1/speech:recognize"
+	googleMaxPhraseLength = 100
+)
+
+// GoogleTranscriber uses Google Cloud Speech-to-Text REST API.
+type GoogleTranscriber struct {
+	client  *http.Client
+	apiKey  string
+	model   string
+	timeout time.Duration
+	log     *slog.Logger
+}
+
+// NewGoogleTranscriber initializes Google Cloud Speech-to-Text client.
+func NewGoogleTranscriber(apiKey, model string, timeout time.Duration, log *slog.Logger) *GoogleTranscriber {
+	return &GoogleTranscriber{client: &http.Client{}, apiKey: apiKey, model: model, timeout: timeout, log: log}
+}
+
+// Transcribe converts audio to text.
+func (t *GoogleTranscriber) Transcribe(ctx context.Context, reader io.Reader, filename, contentType, language string, hints []string) (string, error) {
+	data, err := readAudio(reader)
+	if err != nil {
+		return "", err
+	}
+	transcribeCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	config := map[string]any{
+		"languageCode":               regionalLanguage(language),
+		"enableAutomaticPunctuation": true,
+	}
+	if encoding := googleEncoding(contentType, filename); encoding != "" {
+		config["encoding"] = encoding
+		switch encoding {
+		case "MP3":
+			config["sampleRateHertz"] = 16000
+		case "OGG_OPUS", "WEBM_OPUS":
+			// Opus is always decoded at 48 kHz.
+			config["sampleRateHertz"] = 48000
+		}
+	}
+	if t.model != "" {
+		config["model"] = t.model
+	}
+	if phrases := phrasesFromHints(hints, googleMaxPhraseLength); len(phrases) > 0 {
+		config["speechContexts"] = []map[string]any{{"phrases": phrases}}
+	}
+	payload, err := json.Marshal(map[string]any{
+		"config": config,
+		"audio":  map[string]string{"content": base64.StdEncoding.EncodeToString(data)},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := googleRecognizeURL + "?key=" + url.QueryEscape(t.apiKey)
+	req, err := http.NewRequestWithContext(transcribeCtx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp struct {
+		Results []struct {
+			Alternatives []struct {
+				Transcript string `json:"transcript"`
+			} `json:"alternatives"`
+		} `json:"results"`
+	}
+	if err := doJSON(t.client, req, &resp); err != nil {
+		t.log.Error("Google transcription failed", "error", err)
+		return "", err
+	}
+	parts := make([]string, 0, len(resp.Results))
+	for _, result := range resp.Results {
+		if len(result.Alternatives) > 0 {
+			parts = append(parts, strings.TrimSpace(result.Alternatives[0].Transcript))
+		}
+	}
+	text := strings.TrimSpace(strings.Join(parts, " "))
+	if text == "" {
+		return "", emptyResult()
+	}
+	return text, nil
+}
+
+// googleEncoding maps audio type to Google RecognitionConfig encoding; empty lets Google detect WAV/FLAC headers.
+func googleEncoding(contentType, filename string) string {
+	contentType = strings.ToLower(contentType)
+	filename = strings.ToLower(filename)
+	switch {
+	case contentType == "audio/mpeg" || contentType == "audio/mp3" || strings.HasSuffix(filename, ".mp3"):
+		return "MP3"
+	case contentType == "audio/ogg" || strings.HasSuffix(filename, ".ogg") || strings.HasSuffix(filename, ".oga"):
+		return "OGG_OPUS"
+	case contentType == "audio/webm" || strings.HasSuffix(filename, ".webm"):
+		return "WEBM_OPUS"
+	default:
+		return ""
+	}
+}
@@ -1,9 +1,8 @@
-package handlers
+package stt
 
 import (
 	"bytes"
 	"context"
-	"errors"
 	"io"
 	"log/slog"
 	"time"
@@ -21,24 +20,22 @@ type OpenAITranscriber struct {
 	log     *slog.Logger
 }
 
-// NewOpenAITranscriber initializes OpenAI transcription client.
-func NewOpenAITranscriber(apiKey, model string, timeout time.Duration, log *slog.Logger) *OpenAITranscriber {
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+// NewOpenAITranscriber initializes OpenAI transcription client; baseURL overrides api.openai.com when set.
+func NewOpenAITranscriber(apiKey, baseURL, model string, timeout time.Duration, log *slog.Logger) *OpenAITranscriber {
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+	client := openai.NewClient(opts...)
 	return &OpenAITranscriber{client: client, model: model, timeout: timeout, log: log}
 }
 
 // Transcribe converts audio to text.
-func (t *OpenAITranscriber) Transcribe(ctx context.Context, reader io.Reader, filename, contentType, language string) (string, error) {
-	if reader == nil {
-		return "", errors.New("empty audio reader")
-	}
-	data, err := io.ReadAll(reader)
+func (t *OpenAITranscriber) Transcribe(ctx context.Context, reader io.Reader, filename, contentType, language string, hints []string) (string, error) {
+	data, err := readAudio(reader)
 	if err != nil {
 		return "", err
 	}
-	if len(data) == 0 {
-		return "", errors.New("empty audio content")
-	}
 	transcribeCtx, cancel := context.WithTimeout(ctx, t.timeout)
 	defer cancel()
 
@@ -56,13 +53,16 @@ func (t *OpenAITranscriber) Transcribe(ctx context.Context, reader io.Reader, fi
 	if language != "" {
 		params.Language = param.NewOpt(language)
 	}
+	if prompt := promptFromHints(hints); prompt != "" {
+		params.Prompt = param.NewOpt(prompt)
+	}
 	resp, err := t.client.Audio.Transcriptions.New(transcribeCtx, params)
 	if err != nil {
 		t.log.Error("OpenAI transcription failed", "error", err)
 		return "", err
 	}
 	if resp == nil || resp.Text == "" {
-		return "", errors.New("empty transcription result")
+		return "", emptyResult()
 	}
 	return resp.Text, nil
 }
@@ -0,0 +1,165 @@
+package stt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/config"
+)
+
+// Supported STT providers.
+const (
+	// ProviderOpenAI uses OpenAI (or OpenAI-compatible) transcription API.
+	ProviderOpenAI = "openai"
+	// ProviderWhisperServer uses self-hosted whisper.cpp server.
+	ProviderWhisperServer = "whisper-server"
+	// ProviderGoogle uses Google Cloud Speech-to-Text.
+	ProviderGoogle = "google"
+	// ProviderAzure uses Azure AI Speech.
+	ProviderAzure = "azure"
+	// ProviderDeepgram uses Deepgram.
+	ProviderDeepgram = "deepgram"
+)
+
+// Audio formats transcribers may request from voice normalization.
+const (
+	// FormatMP3 is mono 16 kHz MP3 (default).
+	FormatMP3 = "mp3"
+	// FormatWAV is mono 16 kHz PCM WAV.
+	FormatWAV = "wav"
+)
+
+// Transcriber converts audio to text; hints are domain phrases (question, option labels) that bias recognition.
+type Transcriber interface {
+	Transcribe(ctx context.Context, reader io.Reader, filename, contentType, language string, hints []string) (string, error)
+}
+
+// New creates transcriber for configured provider; it returns nil when transcription is not configured.
+func New(cfg config.Config, log *slog.Logger) (Transcriber, error) {
+	switch cfg.STTProvider {
+	case ProviderOpenAI:
+		if cfg.OpenAIAPIKey == "" {
+			return nil, nil
+		}
+		return NewOpenAITranscriber(cfg.OpenAIAPIKey, cfg.OpenAIBaseURL, cfg.STTModel, cfg.STTTimeout, log), nil
+	case ProviderWhisperServer:
+		return NewWhisperServerTranscriber(cfg.Whisper.URL, cfg.STTTimeout, log), nil
+	case ProviderGoogle:
+		return NewGoogleTranscriber(cfg.Google.APIKey, cfg.Google.Model, cfg.STTTimeout, log), nil
+	case ProviderAzure:
+		return NewAzureTranscriber(cfg.Azure.Key, cfg.Azure.Region, cfg.STTTimeout, log), nil
+	case ProviderDeepgram:
+		return NewDeepgramTranscriber(cfg.Deepgram.APIKey, cfg.Deepgram.Model, cfg.STTTimeout, log), nil
+	default:
+		return nil, fmt.Errorf("unknown stt provider %q", cfg.STTProvider)
+	}
+}
+
+// readAudio reads the whole audio payload.
+func readAudio(reader io.Reader) ([]byte, error) {
+	if reader == nil {
+		return nil, errors.New("empty audio reader")
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty audio content")
+	}
+	return data, nil
+}
+
+// doJSON sends request and decodes JSON response body into out.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// regionalLanguages maps short language codes to locales required by some providers.
+var regionalLanguages = map[string]string{
+	"en": "en-US",
+	"ru": "ru-RU",
+	"de": "de-DE",
+	"fr": "fr-FR",
+	"es": "es-ES",
+	"it": "it-IT",
+	"pt": "pt-BR",
+	"uk": "uk-UA",
+}
+
+// regionalLanguage converts "en" to "en-US"; empty language falls back to en-US.
+func regionalLanguage(language string) string {
+	language = strings.TrimSpace(language)
+	if language == "" {
+		return "en-US"
+	}
+	if strings.Contains(language, "-") {
+		return language
+	}
+	if regional, ok := regionalLanguages[strings.ToLower(language)]; ok {
+		return regional
+	}
+	return language
+}
+
+// maxPromptLength keeps prompt within Whisper's ~224 token prompt window.
+const maxPromptLength = 800
+
+// promptFromHints joins hints into a Whisper-style prompt.
+func promptFromHints(hints []string) string {
+	var b strings.Builder
+	for _, hint := range hints {
+		hint = strings.TrimSpace(hint)
+		if hint == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			hint = ", " + hint
+		}
+		if b.Len()+len(hint) > maxPromptLength {
+			break
+		}
+		b.WriteString(hint)
+	}
+	return b.String()
+}
+
+// phrasesFromHints returns non-empty unique hints not longer than maxLen runes.
+func phrasesFromHints(hints []string, maxLen int) []string {
+	seen := make(map[string]struct{}, len(hints))
+	phrases := make([]string, 0, len(hints))
+	for _, hint := range hints {
+		hint = strings.TrimSpace(hint)
+		if hint == "" || len([]rune(hint)) > maxLen {
+			continue
+		}
+		if _, ok := seen[hint]; ok {
+			continue
+		}
+		seen[hint] = struct{}{}
+		phrases = append(phrases, hint)
+	}
+	return phrases
+}
+
+func emptyResult() error {
+	return errors.New("empty transcription result")
+}
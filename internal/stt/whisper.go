@@ -0,0 +1,96 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// WhisperServerTranscriber uses a self-hosted whisper.cpp server (/inference endpoint).
+type WhisperServerTranscriber struct {
+	client  *http.Client
+	baseURL string
+	timeout time.Duration
+	log     *slog.Logger
+}
+
+// NewWhisperServerTranscriber initializes whisper.cpp server client.
+func NewWhisperServerTranscriber(baseURL string, timeout time.Duration, log *slog.Logger) *WhisperServerTranscriber {
+	return &WhisperServerTranscriber{
+		client:  &http.Client{},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		timeout: timeout,
+		log:     log,
+	}
+}
+
+// AudioFormat reports that whisper.cpp server expects WAV input.
+func (t *WhisperServerTranscriber) AudioFormat() string {
+	return FormatWAV
+}
+
+// Transcribe converts audio to text.
+func (t *WhisperServerTranscriber) Transcribe(ctx context.Context, reader io.Reader, filename, contentType, language string, hints []string) (string, error) {
+	data, err := readAudio(reader)
+	if err != nil {
+		return "", err
+	}
+	transcribeCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	if filename == "" {
+		filename = "voice.wav"
+	}
+	if contentType == "" {
+		contentType = "audio/wav"
+	}
+	if language == "" {
+		language = "auto"
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+strings.ReplaceAll(filename, `"`, "")+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	_ = form.WriteField("response_format", "json")
+	_ = form.WriteField("language", language)
+	if prompt := promptFromHints(hints); prompt != "" {
+		_ = form.WriteField("prompt", prompt)
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(transcribeCtx, http.MethodPost, t.baseURL+"/inference", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var resp struct {
+		Text string `json:"text"`
+	}
+	if err := doJSON(t.client, req, &resp); err != nil {
+		t.log.Error("Whisper server transcription failed", "error", err)
+		return "", err
+	}
+	text := strings.TrimSpace(resp.Text)
+	if text == "" {
+		return "", emptyResult()
+	}
+	return text, nil
+}
@@ -0,0 +1,108 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/reporting"
+	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// failoverError reports whether a failed prompt counts towards the failover: the token was revoked or the bot
+// keeps hitting flood control after all retries.
+func failoverError(err error) bool {
+	var apiErr *ta.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.ErrorCode == http.StatusUnauthorized || apiErr.ErrorCode == http.StatusTooManyRequests
+}
+
+// recordPromptResult counts consecutive prompts failed with failover errors and switches to the standby bot at
+// the threshold. It reports whether the failover happened, so the failed prompt can be sent again.
+func (s *Service) recordPromptResult(ctx context.Context, err error) bool {
+	if s.standby == nil {
+		return false
+	}
+	s.tokenMu.Lock()
+	alert := s.recordPromptResultLocked(ctx, err)
+	s.tokenMu.Unlock()
+	if alert == nil {
+		return false
+	}
+	// The alert is sent unlocked, so a slow Telegram call does not hold up token rotations.
+	s.sendFailoverAlert(ctx, *alert)
+	return true
+}
+
+// failoverAlert describes a failover for the admin chat.
+type failoverAlert struct {
+	count int
+	cause error
+	bot   string
+}
+
+// recordPromptResultLocked counts the prompt result and fails over at the threshold, returning the alert to send.
+func (s *Service) recordPromptResultLocked(ctx context.Context, err error) *failoverAlert {
+	if s.failedOver {
+		return nil
+	}
+	if err == nil || !failoverError(err) {
+		s.promptFailures = 0
+		return nil
+	}
+	s.promptFailures++
+	if s.promptFailures < s.cfg.FailoverThreshold {
+		return nil
+	}
+	alert, failErr := s.failOverLocked(ctx, err)
+	if failErr != nil {
+		s.log.ErrorContext(ctx, "Failed to fail over to standby bot", "error", failErr)
+		s.reporter.Report(ctx, failErr, reporting.Tags(reporting.TagComponent, "telegram", reporting.TagOperation, "failover"))
+		return nil
+	}
+	return alert
+}
+
+// failOverLocked moves updates delivery and new messages to the standby bot. Prompts posted by the primary bot are
+// still edited and deleted through it, so they resolve as long as its token works.
+func (s *Service) failOverLocked(ctx context.Context, cause error) (*failoverAlert, error) {
+	me, err := s.standby.GetMe(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("verify standby token: %w", err)
+	}
+	if err := s.source.SwitchBot(ctx, s.standby); err != nil {
+		return nil, fmt.Errorf("switch updates delivery: %w", err)
+	}
+	s.bot.FailOver(s.standby)
+	s.token = s.cfg.StandbyToken
+	s.failedOver = true
+	s.log.ErrorContext(ctx, "Failed over to standby bot", "error", cause, "count", s.promptFailures, "bot", me.Username)
+	s.reporter.Report(ctx, fmt.Errorf("failed over to standby bot: %w", cause), reporting.Tags(reporting.TagComponent, "telegram", reporting.TagOperation, "failover"))
+	return &failoverAlert{count: s.promptFailures, cause: cause, bot: me.Username}, nil
+}
+
+// sendFailoverAlert notifies the admin chat, or the default chat without one, about the failover.
+func (s *Service) sendFailoverAlert(ctx context.Context, alert failoverAlert) {
+	chatID := s.cfg.AdminChatID
+	if chatID == 0 {
+		chatID = s.chatID
+	}
+	msg := s.messagesFor(s.lang)
+	_, err := s.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID: tu.ID(s.state.MigratedChat(chatID)),
+		Text: msg.Format(fallbackText(msg.AlertFailover, "🚨 Primary bot failed {count} prompts in a row ({error}), new prompts are sent by the standby bot @{bot}"), i18n.Vars{
+			"count": alert.count,
+			"error": alert.cause.Error(),
+			"bot":   alert.bot,
+		}),
+	})
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to send failover alert", "error", err)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+)
+
+// answeredButtonAnswerRunes limits the answer shown on the disabled "answered by" button.
+const answeredButtonAnswerRunes = 32
+
+type senderKey struct{}
+
+// withSender stores the user who triggered the update in context.
+func withSender(ctx context.Context, user *telego.User) context.Context {
+	if user == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, senderKey{}, user)
+}
+
+func senderFrom(ctx context.Context) *telego.User {
+	user, _ := ctx.Value(senderKey{}).(*telego.User)
+	return user
+}
+
+// userDisplayName returns "@username" or the full name of the user.
+func userDisplayName(user *telego.User) string {
+	if user == nil {
+		return ""
+	}
+	if user.Username != "" {
+		return "@" + user.Username
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+// recordAnswer remembers who resolved the execution so that late callbacks of other users can be told about it.
+func (h *Handler) recordAnswer(ctx context.Context, exec *executions.Execution, answer string) {
+	by := userDisplayName(senderFrom(ctx))
+	if by == "" {
+		return
+	}
+	h.registry.RecordAnswer(exec.Request.CorrelationID, executions.AnswerRecord{By: by, Answer: answer})
+}
+
+// alreadyResolvedNote answers a callback that lost the race: "already answered by @user: <option>" when the author
+// is known, the generic "already processed" otherwise.
+func (h *Handler) alreadyResolvedNote(ctx context.Context, correlationID string) string {
+	msg := h.messagesFor(ctx, nil)
+	record, ok := h.registry.Answer(correlationID)
+	if !ok {
+		return msg.AlreadyResolved
+	}
+	note := msg.Format(msg.AlreadyAnswered, i18n.Vars{"user": record.By})
+	if record.Answer != "" {
+		note += ": " + record.Answer
+	}
+	return note
+}
+
+// answeredLabel is the text of the disabled button that replaces options of a resolved prompt.
+func (h *Handler) answeredLabel(ctx context.Context, exec *executions.Execution) string {
+	record, ok := h.registry.Answer(exec.Request.CorrelationID)
+	if !ok {
+		return ""
+	}
+	msg := h.messagesFor(ctx, exec)
+	label := msg.Format(msg.AnsweredButton, i18n.Vars{"user": record.By})
+	if record.Answer != "" {
+		label += ": " + shared.TruncateRunes(record.Answer, answeredButtonAnswerRunes, "…")
+	}
+	return label
+}
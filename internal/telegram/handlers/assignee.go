@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/mymmrac/telego"
+)
+
+// answerActions change the answer of an execution; an exclusive assignee is the only user allowed to use them.
+var answerActions = map[string]struct{}{
+	ActionOption:       {},
+	ActionCustom:       {},
+	ActionCancelCustom: {},
+	ActionSubmit:       {},
+	ActionVoiceUse:     {},
+	ActionVoiceRetry:   {},
+	ActionVoiceEdit:    {},
+}
+
+// canAnswer reports whether the user may answer the execution.
+func canAnswer(exec *executions.Execution, user *telego.User) bool {
+	if user == nil {
+		return exec.Request.CanAnswer(0, "")
+	}
+	return exec.Request.CanAnswer(user.ID, user.Username)
+}
+
+// rejectCallback answers callbacks of other users on executions assigned exclusively to someone or restricted to approvers.
+func (h *Handler) rejectCallback(ctx context.Context, query *telego.CallbackQuery, action string, exec *executions.Execution) bool {
+	if _, ok := answerActions[action]; !ok {
+		return false
+	}
+	if exec == nil || canAnswer(exec, &query.From) {
+		return false
+	}
+	_ = h.answerCallback(ctx, query, h.assigneeOnlyNote(ctx, exec))
+	return true
+}
+
+func (h *Handler) assigneeOnlyNote(ctx context.Context, exec *executions.Execution) string {
+	assignee := exec.Request.Assignee
+	msg := h.messagesFor(ctx, exec)
+	if (!assignee.Exclusive || assignee.IsZero()) && len(exec.Request.Approvers) > 0 {
+		mentions := make([]string, 0, len(exec.Request.Approvers))
+		for _, approver := range exec.Request.Approvers {
+			mentions = append(mentions, "@"+approver)
+		}
+		return msg.Format(msg.ApproversOnly, i18n.Vars{"mentions": strings.Join(mentions, ", ")})
+	}
+	mention := assignee.Name
+	if assignee.Username != "" {
+		mention = "@" + assignee.Username
+	}
+	return msg.Format(msg.AssigneeOnly, i18n.Vars{"mention": mention})
+}
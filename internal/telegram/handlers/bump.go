@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/codex-k8s/telegram-executor/internal/events"
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+var (
+	// ErrExecutionNotFound is returned when the execution is not pending.
+	ErrExecutionNotFound = errors.New("execution not found")
+	// ErrBumpPoll is returned for poll prompts, whose votes live in a separate message that cannot be moved.
+	ErrBumpPoll = errors.New("poll prompts cannot be bumped")
+)
+
+// KeyboardBuilder renders answer keyboards of a pending prompt.
+type KeyboardBuilder interface {
+	PromptKeyboard(exec *executions.Execution) telego.ReplyMarkup
+	// QuickAnswerKeyboard renders option buttons only, for messages posted outside the prompt chat.
+	QuickAnswerKeyboard(exec *executions.Execution) *telego.InlineKeyboardMarkup
+}
+
+// SetKeyboardBuilder sets the builder used to re-send prompts.
+func (h *Handler) SetKeyboardBuilder(builder KeyboardBuilder) {
+	h.keyboards = builder
+}
+
+// Bump re-sends the pending prompt at the bottom of the chat and deletes the original, keeping execution state:
+// shown details, pending custom input and the pin of urgent prompts.
+func (h *Handler) Bump(ctx context.Context, correlationID string) error {
+	exec := h.registry.Get(correlationID)
+	if exec == nil {
+		return ErrExecutionNotFound
+	}
+	if exec.Request.AnswerMode == executions.AnswerModePoll {
+		return ErrBumpPoll
+	}
+	ctx = WithExecution(ctx, exec)
+	keyboard := h.keyboards.PromptKeyboard(exec)
+	if inline, ok := keyboard.(*telego.InlineKeyboardMarkup); ok {
+		keyboard = h.keyboardState(ctx, exec, inline)
+	}
+	chatID := h.currentChat(ctx)
+	sent, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID:      tu.ID(chatID),
+		Text:        h.promptText(ctx, exec),
+		ParseMode:   shared.ParseMode(exec.Request.Markup),
+		Entities:    exec.DisplayEntities(),
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		h.log.ErrorContext(ctx, "Failed to re-send bumped prompt", "error", err)
+		h.reportTelegramError(ctx, err, "bump_prompt", correlationID)
+		return err
+	}
+	previousID, ok := h.registry.MoveMessage(correlationID, sent.MessageID)
+	if !ok {
+		// Resolved while re-sending: the copy is stale.
+		_ = h.DeleteMessage(ctx, sent.MessageID)
+		return ErrExecutionNotFound
+	}
+	if err := h.DeleteMessage(ctx, previousID); err != nil {
+		// Bots cannot delete messages older than 48 hours; strip the buttons so only the new copy is answered.
+		h.log.WarnContext(ctx, "Failed to delete bumped prompt", "error", err, "message_id", previousID)
+		_, _ = h.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+			ChatID:    tu.ID(chatID),
+			MessageID: previousID,
+		})
+		if exec.Pinned {
+			_ = h.bot.UnpinChatMessage(ctx, &telego.UnpinChatMessageParams{ChatID: tu.ID(chatID), MessageID: previousID})
+		}
+	}
+	if exec.Pinned {
+		err := h.bot.PinChatMessage(ctx, &telego.PinChatMessageParams{
+			ChatID:              tu.ID(chatID),
+			MessageID:           sent.MessageID,
+			DisableNotification: true,
+		})
+		if err != nil {
+			h.log.WarnContext(ctx, "Failed to pin bumped prompt", "error", err)
+		}
+	}
+	bumped := events.New(events.TypePromptBumped, correlationID, exec.Request.Tool.Name, exec.CreatedAt)
+	bumped.MessageID = sent.MessageID
+	h.bus.Emit(bumped)
+	h.NotifyCallback(ctx, h.registry.Get(correlationID), executions.CallbackEventReminderSent, "")
+	return nil
+}
+
+// keyboardState applies toggled details and pending custom input to a freshly rendered prompt keyboard.
+func (h *Handler) keyboardState(ctx context.Context, exec *executions.Execution, keyboard *telego.InlineKeyboardMarkup) *telego.InlineKeyboardMarkup {
+	msg := h.messagesFor(ctx, exec)
+	correlationID := exec.Request.CorrelationID
+	if exec.DetailsShown {
+		keyboard = relabelButton(keyboard, CallbackData(ActionDetails, correlationID), msg.HideDetailsButton)
+	}
+	if exec.Prompt != nil {
+		keyboard, _ = replaceButton(keyboard, CallbackData(ActionCustom, correlationID), msg.CancelCustomButton, CallbackData(ActionCancelCustom, correlationID))
+	}
+	return keyboard
+}
+
+// handleBumpCommand bumps the pending prompt the /bump command replies to.
+func (h *Handler) handleBumpCommand(ctx context.Context, message *telego.Message) {
+	var exec *executions.Execution
+	if message.ReplyToMessage != nil {
+		exec = h.registry.FindByMessage(h.currentChat(ctx), message.ReplyToMessage.MessageID)
+	}
+	if exec == nil {
+		_ = h.reply(ctx, h.messagesFor(ctx, nil).BumpUsage)
+		return
+	}
+	if err := h.Bump(ctx, exec.Request.CorrelationID); err != nil {
+		if errors.Is(err, ErrBumpPoll) || errors.Is(err, ErrExecutionNotFound) {
+			_ = h.reply(ctx, h.messagesFor(ctx, exec).BumpUsage)
+		}
+		return
+	}
+	// Keep the chat clean; deleting other users' messages needs admin rights, so failures are ignored.
+	_ = h.DeleteMessage(ctx, message.MessageID)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+// minCompressedCallback is the smallest callback body worth compressing.
+const minCompressedCallback = 1024
+
+// compressCallback encodes body with gzip or deflate (zlib format, as HTTP defines it); other encodings keep
+// it as is.
+func compressCallback(body []byte, encoding string) ([]byte, error) {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case executions.CallbackEncodingGzip:
+		writer = gzip.NewWriter(&buf)
+	case executions.CallbackEncodingDeflate:
+		writer = zlib.NewWriter(&buf)
+	default:
+		return body, nil
+	}
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+// NotifyCallback posts an intermediate event of the pending execution to its callback URL when the request listed
+// it in callback.events. Delivery runs in the background, is best effort and is not retained for redelivery; it is
+// skipped once shutdown began.
+func (h *Handler) NotifyCallback(ctx context.Context, exec *executions.Execution, event, responder string) {
+	if exec == nil || !exec.Request.Callback.Notifies(event) {
+		return
+	}
+	payload := map[string]any{
+		"correlation_id": exec.Request.ClientCorrelationID(),
+		"status":         string(executions.StatusPending),
+		"event":          event,
+		"tool":           exec.Request.Tool.Name,
+		"submitted_at":   exec.CreatedAt.UTC().Format(time.RFC3339Nano),
+		"event_at":       time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if !exec.Request.Deadline.IsZero() {
+		payload["deadline"] = exec.Request.Deadline.UTC().Format(time.RFC3339)
+	}
+	if len(exec.Request.Labels) > 0 {
+		payload["labels"] = exec.Request.Labels
+	}
+	if exec.Request.ChatID != 0 {
+		payload["chat_id"] = exec.Request.ChatID
+	}
+	if exec.MessageID > 0 {
+		payload["message_id"] = exec.MessageID
+		if link := messageLink(exec.Request.ChatID, exec.MessageID); link != "" {
+			payload["message_link"] = link
+		}
+	}
+	if responder != "" {
+		payload["responder"] = responder
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	url, encoding := exec.Request.Callback.URL, exec.Request.Callback.AcceptEncoding
+	correlationID := exec.Request.CorrelationID
+	h.Go(ctx, func(ctx context.Context) {
+		if err := postCallback(ctx, url, encoding, body); err != nil {
+			h.log.WarnContext(ctx, "Failed to deliver intermediate callback", "error", err, "event", event, "correlation_id", correlationID)
+		}
+	})
+}
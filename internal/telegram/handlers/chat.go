@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	applog "github.com/codex-k8s/telegram-executor/internal/log"
+	"github.com/mymmrac/telego"
+)
+
+type chatKey struct{}
+
+// WithChat stores the chat handled messages belong to; zero chatID keeps context as is.
+func WithChat(ctx context.Context, chatID int64) context.Context {
+	if chatID == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, chatKey{}, chatID)
+}
+
+// WithExecution scopes context to the execution: its chat and correlation attributes of log lines.
+func WithExecution(ctx context.Context, exec *executions.Execution) context.Context {
+	return applog.WithAttrs(WithChat(ctx, exec.Request.ChatID), exec.Request.LogAttrs()...)
+}
+
+// currentChat returns chat from context, falling back to the configured chat.
+func (h *Handler) currentChat(ctx context.Context) int64 {
+	if chatID, ok := ctx.Value(chatKey{}).(int64); ok {
+		return chatID
+	}
+	return h.state.MigratedChat(h.chatID)
+}
+
+// updateChat returns chat where the update happened (0 for poll answers).
+func updateChat(update telego.Update) int64 {
+	switch {
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.GetChat().ID
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.EditedMessage != nil:
+		return update.EditedMessage.Chat.ID
+	case update.MessageReaction != nil:
+		return update.MessageReaction.Chat.ID
+	case update.MyChatMember != nil:
+		return update.MyChatMember.Chat.ID
+	default:
+		return 0
+	}
+}
+
+// allowedChat reports whether chat is the configured, a tenant or a routed chat, before or after supergroup migration.
+func (h *Handler) allowedChat(chatID int64) bool {
+	if chatID == h.chatID || chatID == h.state.MigratedChat(h.chatID) {
+		return true
+	}
+	for configured := range h.tenantChats {
+		if chatID == configured || chatID == h.state.MigratedChat(configured) {
+			return true
+		}
+	}
+	h.chatsMu.Lock()
+	defer h.chatsMu.Unlock()
+	_, routed := h.routedChats[chatID]
+	return routed
+}
+
+// AllowChat accepts updates from a chat prompts are routed to by a tool profile, in addition to configured chats.
+func (h *Handler) AllowChat(chatID int64) {
+	if chatID == 0 || h.allowedChat(chatID) {
+		return
+	}
+	h.chatsMu.Lock()
+	defer h.chatsMu.Unlock()
+	h.routedChats[chatID] = struct{}{}
+}
+
+// handleMigration follows a group upgrade to a supergroup: Telegram sends migrate_to_chat_id
+// into the old group and migrate_from_chat_id into the new supergroup.
+func (h *Handler) handleMigration(message *telego.Message) bool {
+	fromChatID, toChatID := int64(0), int64(0)
+	switch {
+	case message.MigrateToChatID != 0:
+		fromChatID, toChatID = message.Chat.ID, message.MigrateToChatID
+	case message.MigrateFromChatID != 0:
+		fromChatID, toChatID = message.MigrateFromChatID, message.Chat.ID
+	default:
+		return false
+	}
+	if !h.allowedChat(fromChatID) || h.state.MigratedChat(fromChatID) == toChatID {
+		return true
+	}
+	if err := h.state.MigrateChat(fromChatID, toChatID); err != nil {
+		h.log.Error("Failed to store chat migration", "error", err)
+	}
+	moved := h.registry.MigrateChat(fromChatID, toChatID)
+	h.log.Warn("Telegram chat migrated to supergroup, update the configured chat id",
+		"from_chat_id", fromChatID,
+		"to_chat_id", toChatID,
+		"pending", moved,
+	)
+	return true
+}
+
+func chatSet(ids []int64) map[int64]struct{} {
+	set := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
+
+// messageLink returns t.me deep link to the message; only supergroups and channels (-100 prefixed IDs) have one.
+func messageLink(chatID int64, messageID int) string {
+	id := strconv.FormatInt(chatID, 10)
+	if messageID <= 0 || !strings.HasPrefix(id, "-100") {
+		return ""
+	}
+	return fmt.Sprintf("https://t.me/c/%s/%d", strings.TrimPrefix(id, "-100"), messageID)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// langCommand shows or sets chat language preference.
+	langCommand = "/lang"
+	// tzCommand shows or sets chat display timezone.
+	tzCommand = "/tz"
+	// bumpCommand re-sends the pending prompt it replies to at the bottom of the chat.
+	bumpCommand = "/bump"
+	// prefDefault removes chat preference set with a command.
+	prefDefault = "default"
+)
+
+// handleCommand processes bot commands and reports whether message was a command.
+func (h *Handler) handleCommand(ctx context.Context, message *telego.Message) bool {
+	fields := strings.Fields(message.Text)
+	if len(fields) == 0 {
+		return false
+	}
+	command, _, _ := strings.Cut(fields[0], "@")
+	switch strings.ToLower(command) {
+	case langCommand:
+		h.handleLangCommand(ctx, fields[1:])
+		return true
+	case tzCommand:
+		h.handleTimezoneCommand(ctx, fields[1:])
+		return true
+	case bumpCommand:
+		h.handleBumpCommand(ctx, message)
+		return true
+	case statusCommand:
+		h.handleStatusCommand(ctx)
+		return true
+	default:
+		return false
+	}
+}
+
+// handleLangCommand shows current language (/lang), sets preference (/lang ru) or resets it (/lang default).
+func (h *Handler) handleLangCommand(ctx context.Context, args []string) {
+	available := h.availableLangs()
+	if len(args) == 0 {
+		lang := h.currentLang(ctx)
+		msg := h.messageFor(lang)
+		_ = h.reply(ctx, msg.Format(msg.LangCurrent, i18n.Vars{"lang": lang, "available": strings.Join(available, ", ")}))
+		return
+	}
+	lang := strings.ToLower(strings.TrimSpace(args[0]))
+	if lang == prefDefault {
+		if err := h.state.SetChatLang(h.currentChat(ctx), ""); err != nil {
+			h.log.ErrorContext(ctx, "Failed to reset chat language", "error", err)
+		}
+		msg := h.messageFor(h.defaultLang)
+		_ = h.reply(ctx, msg.Format(msg.LangReset, i18n.Vars{"lang": h.defaultLang}))
+		return
+	}
+	if !slices.Contains(available, lang) {
+		msg := h.messageFor(h.currentLang(ctx))
+		_ = h.reply(ctx, msg.Format(msg.LangUnknown, i18n.Vars{"lang": lang, "available": strings.Join(available, ", ")}))
+		return
+	}
+	if err := h.state.SetChatLang(h.currentChat(ctx), lang); err != nil {
+		h.log.ErrorContext(ctx, "Failed to store chat language", "error", err)
+	}
+	msg := h.messageFor(lang)
+	_ = h.reply(ctx, msg.Format(msg.LangSet, i18n.Vars{"lang": lang}))
+}
+
+// handleTimezoneCommand shows display timezone (/tz), sets preference (/tz Europe/Berlin) or resets it (/tz default).
+func (h *Handler) handleTimezoneCommand(ctx context.Context, args []string) {
+	msg := h.messageFor(h.currentLang(ctx))
+	if len(args) == 0 {
+		_ = h.reply(ctx, msg.Format(msg.TimezoneCurrent, i18n.Vars{"tz": h.currentTimezone(ctx)}))
+		return
+	}
+	name := strings.TrimSpace(args[0])
+	if strings.EqualFold(name, prefDefault) {
+		if err := h.state.SetChatTimezone(h.currentChat(ctx), ""); err != nil {
+			h.log.ErrorContext(ctx, "Failed to reset chat timezone", "error", err)
+		}
+		_ = h.reply(ctx, msg.Format(msg.TimezoneReset, i18n.Vars{"tz": h.defaultTimezone}))
+		return
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || strings.EqualFold(name, "local") {
+		_ = h.reply(ctx, msg.Format(msg.TimezoneUnknown, i18n.Vars{"tz": name}))
+		return
+	}
+	if err := h.state.SetChatTimezone(h.currentChat(ctx), loc.String()); err != nil {
+		h.log.ErrorContext(ctx, "Failed to store chat timezone", "error", err)
+	}
+	_ = h.reply(ctx, msg.Format(msg.TimezoneSet, i18n.Vars{"tz": loc.String()}))
+}
+
+func (h *Handler) currentTimezone(ctx context.Context) string {
+	if name := h.state.ChatTimezone(h.currentChat(ctx)); name != "" {
+		return name
+	}
+	return h.defaultTimezone
+}
+
+// chatLang returns chat language preference if a locale exists for it.
+func (h *Handler) chatLang(ctx context.Context) string {
+	lang := h.state.ChatLang(h.currentChat(ctx))
+	if _, ok := h.messages[lang]; !ok {
+		return ""
+	}
+	return lang
+}
+
+func (h *Handler) currentLang(ctx context.Context) string {
+	if lang := h.chatLang(ctx); lang != "" {
+		return lang
+	}
+	return h.defaultLang
+}
+
+func (h *Handler) availableLangs() []string {
+	langs := make([]string, 0, len(h.messages))
+	for lang := range h.messages {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+	return langs
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+)
+
+// composing reports whether someone is typing a custom answer to the execution.
+func composing(exec *executions.Execution) bool {
+	return exec.Prompt != nil && exec.Prompt.Composer != ""
+}
+
+// promptText returns the execution message text with a "✏️ @user is composing an answer…" note while custom input
+// is pending, so other approvers do not race the composer.
+func (h *Handler) promptText(ctx context.Context, exec *executions.Execution) string {
+	text := exec.DisplayText()
+	if !composing(exec) {
+		return text
+	}
+	msg := h.messagesFor(ctx, exec)
+	note := msg.Format(msg.ComposingNote, i18n.Vars{"user": exec.Prompt.Composer})
+	return fmt.Sprintf("%s\n\n%s", text, fitNote(text, note, shared.ParseMode(exec.Request.Markup)))
+}
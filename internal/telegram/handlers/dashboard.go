@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// inputModeDashboard marks answers given by an operator through the HTTP API or dashboard.
+const inputModeDashboard = "dashboard"
+
+// ErrEmptyAnswer is returned when an execution is force-resolved without an answer.
+var ErrEmptyAnswer = errors.New("answer is required")
+
+// Cancel resolves the pending execution with the cancelled result and removes its prompt, like group cancellation.
+func (h *Handler) Cancel(ctx context.Context, correlationID string) error {
+	if !h.cancelExecution(ctx, correlationID) {
+		return ErrExecutionNotFound
+	}
+	h.log.InfoContext(ctx, "Execution cancelled by operator", "correlation_id", correlationID)
+	return nil
+}
+
+// ForceResolve resolves the pending execution on behalf of an operator: an answer equal to an option selects it,
+// any other answer resolves the execution as a custom answer.
+func (h *Handler) ForceResolve(ctx context.Context, correlationID, answer string) error {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return ErrEmptyAnswer
+	}
+	exec := h.registry.Get(correlationID)
+	if exec == nil {
+		return ErrExecutionNotFound
+	}
+	ctx = WithExecution(ctx, exec)
+	for idx, option := range exec.Request.Options {
+		if strings.EqualFold(option, answer) {
+			if _, ok := h.selectOption(ctx, correlationID, idx, inputModeDashboard); !ok {
+				return ErrExecutionNotFound
+			}
+			return nil
+		}
+	}
+	h.resolveCustom(ctx, correlationID, answer, inputModeDashboard)
+	return nil
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// decisionFieldRunes limits question and answer in a decisions log entry so the summary stays compact.
+const decisionFieldRunes = 300
+
+// logDecision posts a compact summary of the resolution to the decisions log chat, separate from the working chat.
+func (h *Handler) logDecision(ctx context.Context, exec *executions.Execution, result executions.Result, note string) {
+	if h.decisionsChat == 0 {
+		return
+	}
+	answer, user := note, "—"
+	if record, ok := h.registry.Answer(exec.Request.CorrelationID); ok {
+		user = record.By
+		if record.Answer != "" {
+			answer = record.Answer
+		}
+	}
+	msg := h.messageFor(h.defaultLang)
+	text := msg.Format(msg.DecisionLog, i18n.Vars{
+		"tool":     exec.Request.Tool.Name,
+		"status":   string(result.Status),
+		"question": shared.TruncateRunes(exec.Request.Question, decisionFieldRunes, "…"),
+		"answer":   shared.TruncateRunes(answer, decisionFieldRunes, "…"),
+		"user":     user,
+		"duration": time.Since(exec.CreatedAt).Round(time.Second).String(),
+	})
+	if tags := decisionHashtags(exec.Request); len(tags) > 0 {
+		text += "\n" + strings.Join(tags, " ")
+	}
+	_, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID:              tu.ID(h.decisionsChat),
+		Text:                text,
+		DisableNotification: true,
+	})
+	if err != nil {
+		h.log.WarnContext(ctx, "Failed to post to decisions log", "error", err, "decisions_chat_id", h.decisionsChat)
+		h.reportTelegramError(ctx, err, "decision_log", exec.Request.CorrelationID)
+	}
+}
+
+// decisionHashtags makes decisions searchable with Telegram's native hashtag search: the tool name, tool tags
+// and label values (sorted by label key), e.g. "#deploy_service #payments".
+func decisionHashtags(req executions.Request) []string {
+	values := append([]string{req.Tool.Name}, req.Tool.Tags...)
+	keys := make([]string, 0, len(req.Labels))
+	for key := range req.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values = append(values, req.Labels[key])
+	}
+	return shared.Hashtags(values...)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// documentAnswerExtensions lists text files accepted as custom answers.
+var documentAnswerExtensions = map[string]bool{".txt": true, ".md": true, ".log": true}
+
+var errDocumentUnsupported = errors.New("unsupported document")
+
+// readDocumentAnswer downloads text document and returns its contents prefixed with caption.
+func (h *Handler) readDocumentAnswer(ctx context.Context, document *telego.Document, caption string) (string, error) {
+	if !documentAnswerExtensions[strings.ToLower(path.Ext(document.FileName))] {
+		return "", errDocumentUnsupported
+	}
+	if document.FileSize > h.maxDocumentSize {
+		return "", errDocumentUnsupported
+	}
+	file, err := h.bot.API().GetFile(ctx, &telego.GetFileParams{FileID: document.FileID})
+	if err != nil {
+		return "", err
+	}
+	if file.FileSize > h.maxDocumentSize {
+		return "", errDocumentUnsupported
+	}
+	data, err := tu.DownloadFile(h.bot.API().FileDownloadURL(file.FilePath))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > h.maxDocumentSize {
+		return "", errDocumentUnsupported
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("document %q is not valid UTF-8", document.FileName)
+	}
+	answer := strings.TrimSpace(string(data))
+	if caption = strings.TrimSpace(caption); caption != "" {
+		answer = caption + "\n\n" + answer
+	}
+	return answer, nil
+}
+
+// replyDocumentError explains why document answer was rejected.
+func (h *Handler) replyDocumentError(ctx context.Context, lang string, err error) {
+	msg := h.messageFor(lang)
+	if errors.Is(err, errDocumentUnsupported) {
+		_ = h.reply(ctx, msg.Format(msg.DocumentUnsupported, i18n.Vars{"max_kb": h.maxDocumentSize / 1024}))
+		return
+	}
+	h.log.ErrorContext(ctx, "Failed to read document answer", "error", err)
+	_ = h.reply(ctx, msg.DocumentFailed)
+}
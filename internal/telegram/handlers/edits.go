@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/reporting"
+	"github.com/mymmrac/telego"
+)
+
+// holdAnswer delays resolving custom text answer by the edit grace period so that
+// edits of the user message (edited_message updates) replace the answer text.
+func (h *Handler) holdAnswer(ctx context.Context, correlationID string, messageID int, text string) {
+	if !h.registry.HoldAnswer(correlationID, messageID, text) {
+		return
+	}
+	time.AfterFunc(h.editGrace, func() {
+		if !h.Go(ctx, func(ctx context.Context) { h.resolveHeldAnswer(ctx, correlationID, messageID) }) {
+			h.log.WarnContext(ctx, "Held answer dropped on shutdown", "correlation_id", correlationID)
+		}
+	})
+}
+
+// resolveHeldAnswer resolves the execution with the held answer unless it was taken meanwhile.
+func (h *Handler) resolveHeldAnswer(ctx context.Context, correlationID string, messageID int) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err := reporting.PanicError(recovered)
+			h.log.ErrorContext(ctx, "Panic while resolving held answer", "error", err, "correlation_id", correlationID)
+			h.reporter.Report(ctx, err, reporting.Tags(
+				reporting.TagComponent, "telegram",
+				reporting.TagOperation, "held_answer",
+				reporting.TagCorrelationID, correlationID,
+			))
+		}
+	}()
+	answer, ok := h.registry.TakeHeldAnswer(correlationID, messageID)
+	if !ok {
+		return
+	}
+	h.resolveCustom(ctx, correlationID, answer, inputModeText)
+}
+
+// handleEditedMessage applies corrections to custom answers still within the grace period.
+func (h *Handler) handleEditedMessage(ctx context.Context, message *telego.Message) {
+	if !h.allowedChat(message.Chat.ID) {
+		return
+	}
+	text := strings.TrimSpace(message.Text)
+	if text == "" {
+		return
+	}
+	chatID := h.currentChat(ctx)
+	if h.registry.EditHeldAnswer(chatID, message.MessageID, text) || h.registry.EditAnswerPart(chatID, message.MessageID, text) {
+		h.log.DebugContext(ctx, "Custom answer edited", "message_id", message.MessageID)
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// fuzzyShortAnswerWords limits ordinal/keyword matching to short answers ("yes, option two").
+	fuzzyShortAnswerWords = 6
+	// fuzzyMinMargin is the minimal score gap between the best and the runner-up option.
+	fuzzyMinMargin = 0.1
+	// Confidence scores of individual matching strategies.
+	fuzzyOrdinalScore = 0.9
+	fuzzyKeywordScore = 0.85
+)
+
+var (
+	// ordinalWords map spoken ordinals to option numbers; they identify an option on their own.
+	ordinalWords = map[string]int{
+		"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5,
+		"первый": 1, "второй": 2, "третий": 3, "четвертый": 4, "четвёртый": 4, "пятый": 5,
+		"первая": 1, "вторая": 2, "третья": 3, "четвертая": 4, "четвёртая": 4, "пятая": 5,
+		"первое": 1, "второе": 2, "третье": 3, "четвертое": 4, "четвёртое": 4, "пятое": 5,
+		"первую": 1, "вторую": 2, "третью": 3, "четвертую": 4, "четвёртую": 4, "пятую": 5,
+	}
+	// cardinalWords identify an option only after a marker word ("option two", "вариант два").
+	cardinalWords = map[string]int{
+		"1": 1, "2": 2, "3": 3, "4": 4, "5": 5,
+		"one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+		"один": 1, "два": 2, "три": 3, "четыре": 4, "пять": 5,
+	}
+	optionMarkerWords = map[string]struct{}{
+		"option": {}, "number": {}, "variant": {}, "choice": {},
+		"вариант": {}, "варианта": {}, "номер": {}, "пункт": {},
+	}
+	negationWords = map[string]struct{}{
+		"not": {}, "no": {}, "dont": {}, "don't": {}, "neither": {}, "nor": {}, "except": {}, "instead": {},
+		"не": {}, "нет": {}, "ни": {}, "кроме": {}, "вместо": {},
+	}
+	stopWords = map[string]struct{}{
+		"the": {}, "and": {}, "for": {}, "with": {}, "one": {}, "yes": {}, "please": {}, "let's": {}, "lets": {}, "that": {}, "this": {},
+		"для": {}, "это": {}, "этот": {}, "эту": {}, "да": {}, "давай": {}, "давайте": {}, "пожалуйста": {},
+	}
+)
+
+// fuzzyMatchOption maps free-form answer ("yes, option two", "the canary one") to option index.
+// It returns confidence in [0, 1]; ok is false when no option scores at least threshold
+// or the best match is ambiguous.
+func fuzzyMatchOption(answer string, options []string, threshold float64) (int, float64, bool) {
+	if threshold <= 0 || len(options) == 0 {
+		return 0, 0, false
+	}
+	words := fuzzyWords(answer)
+	if len(words) == 0 {
+		return 0, 0, false
+	}
+	optionWords := make([][]string, len(options))
+	for idx, option := range options {
+		optionWords[idx] = fuzzyWords(option)
+	}
+	short := len(words) <= fuzzyShortAnswerWords && !containsAny(words, negationWords)
+
+	scores := make([]float64, len(options))
+	joined := strings.Join(words, " ")
+	for idx := range options {
+		scores[idx] = similarity(joined, strings.Join(optionWords[idx], " "))
+	}
+	if short {
+		if index, ok := ordinalReference(words, len(options)); ok {
+			scores[index] = max(scores[index], fuzzyOrdinalScore)
+		}
+		if index, ok := keywordReference(words, optionWords); ok {
+			scores[index] = max(scores[index], fuzzyKeywordScore)
+		}
+	}
+
+	best, runnerUp := -1, 0.0
+	for idx, score := range scores {
+		if best < 0 || score > scores[best] {
+			if best >= 0 {
+				runnerUp = max(runnerUp, scores[best])
+			}
+			best = idx
+			continue
+		}
+		runnerUp = max(runnerUp, score)
+	}
+	if scores[best] < threshold || scores[best]-runnerUp < fuzzyMinMargin {
+		return 0, 0, false
+	}
+	return best, roundConfidence(scores[best]), true
+}
+
+// ordinalReference finds a single option number referenced by the answer.
+func ordinalReference(words []string, count int) (int, bool) {
+	found := 0
+	for idx, word := range words {
+		number, ok := ordinalWords[word]
+		if !ok && idx > 0 {
+			if _, marker := optionMarkerWords[words[idx-1]]; marker {
+				number, ok = cardinalWords[word]
+			}
+		}
+		if !ok {
+			continue
+		}
+		if found != 0 && found != number {
+			return 0, false
+		}
+		found = number
+	}
+	if found < 1 || found > count {
+		return 0, false
+	}
+	return found - 1, true
+}
+
+// keywordReference finds option whose distinctive word (absent from other options) is mentioned in the answer.
+func keywordReference(words []string, optionWords [][]string) (int, bool) {
+	owners := map[string]int{}
+	for idx, list := range optionWords {
+		for _, word := range list {
+			if !isKeyword(word) {
+				continue
+			}
+			if owner, ok := owners[word]; ok && owner != idx {
+				owners[word] = -1
+				continue
+			}
+			owners[word] = idx
+		}
+	}
+	found := -1
+	for _, word := range words {
+		if !isKeyword(word) {
+			continue
+		}
+		owner := -1
+		for keyword, idx := range owners {
+			if idx < 0 || similarity(word, keyword) < 0.8 {
+				continue
+			}
+			if owner >= 0 && owner != idx {
+				owner = -1
+				break
+			}
+			owner = idx
+		}
+		if owner < 0 {
+			continue
+		}
+		if found >= 0 && found != owner {
+			return 0, false
+		}
+		found = owner
+	}
+	return found, found >= 0
+}
+
+func isKeyword(word string) bool {
+	if len([]rune(word)) < 4 {
+		return false
+	}
+	_, stop := stopWords[word]
+	return !stop
+}
+
+// fuzzyWords lowercases text and splits it into words without punctuation.
+func fuzzyWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+func containsAny(words []string, set map[string]struct{}) bool {
+	for _, word := range words {
+		if _, ok := set[word]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// similarity returns normalized Levenshtein similarity of two strings.
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func roundConfidence(value float64) float64 {
+	return float64(int(value*100+0.5)) / 100
+}
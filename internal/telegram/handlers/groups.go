@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/codex-k8s/telegram-executor/internal/events"
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+)
+
+// CancelGroup cancels pending executions of the group, removes their Telegram messages
+// and reports them as cancelled to callbacks. It returns the number of cancelled executions.
+func (h *Handler) CancelGroup(ctx context.Context, tenant, groupID string) int {
+	cancelled := 0
+	for _, correlationID := range h.registry.Group(tenant, groupID) {
+		if h.cancelExecution(ctx, correlationID) {
+			cancelled++
+		}
+	}
+	if cancelled > 0 {
+		h.log.InfoContext(ctx, "Execution group cancelled", "tenant", tenant, "group_id", groupID, "cancelled", cancelled)
+	}
+	return cancelled
+}
+
+func (h *Handler) cancelExecution(ctx context.Context, correlationID string) bool {
+	exec, promptID, ok := h.registry.Resolve(correlationID)
+	if !ok {
+		return false
+	}
+	ctx = WithExecution(ctx, exec)
+	h.queue.cancel(correlationID)
+	messageIDs := []int{promptID, exec.PollMessageID, exec.MessageID}
+	if exec.Prompt != nil {
+		messageIDs = append(messageIDs, exec.Prompt.StatusMessageID)
+	}
+	for _, messageID := range messageIDs {
+		if err := h.DeleteMessage(ctx, messageID); err != nil {
+			h.log.WarnContext(ctx, "Failed to delete cancelled execution message", "error", err, "correlation_id", correlationID, "message_id", messageID)
+		}
+	}
+	if exec.Request.AnswerMode == executions.AnswerModeReplyKeyboard {
+		h.removeReplyKeyboard(ctx, exec, shared.WithEmoji(h.theme.Error, h.messagesFor(ctx, exec).CancelledNote))
+	}
+	cancelled := events.New(events.TypeCancelled, correlationID, exec.Request.Tool.Name, exec.CreatedAt)
+	cancelled.MessageID = exec.MessageID
+	h.bus.Emit(cancelled)
+	h.sendWebhook(ctx, exec, executions.Result{Status: executions.StatusCancelled, Output: executions.Cancelled{}})
+	return true
+}
+
+// cancelGroupOf cancels the rest of the group when its parent execution (correlation_id equal to group_id) resolves.
+func (h *Handler) cancelGroupOf(ctx context.Context, exec *executions.Execution) {
+	if groupID := exec.Request.GroupID; groupID != "" && groupID == exec.Request.ClientCorrelationID() {
+		h.CancelGroup(ctx, exec.Request.Tenant, groupID)
+	}
+}
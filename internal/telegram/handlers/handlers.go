@@ -8,15 +8,24 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 
+	"github.com/codex-k8s/telegram-executor/internal/events"
 	"github.com/codex-k8s/telegram-executor/internal/executions"
 	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/reporting"
+	"github.com/codex-k8s/telegram-executor/internal/state"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/outbound"
 	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
 	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
 	tu "github.com/mymmrac/telego/telegoutil"
 )
 
@@ -29,41 +38,167 @@ const (
 	ActionCancelCustom = "custom_cancel"
 	// ActionDelete deletes a resolved message.
 	ActionDelete = "delete"
+	// ActionDetails toggles request parameters in a pending prompt.
+	ActionDetails = "details"
+	// ActionSubmit submits collected multi-message custom answer.
+	ActionSubmit = "submit"
+	// ActionVoiceUse accepts voice transcription as the answer.
+	ActionVoiceUse = "voice_use"
+	// ActionVoiceRetry discards voice transcription to record again.
+	ActionVoiceRetry = "voice_retry"
+	// ActionVoiceEdit asks for corrected text of voice transcription.
+	ActionVoiceEdit = "voice_edit"
+	// ActionAnswered is the disabled button of a resolved prompt telling who answered it.
+	ActionAnswered = "answered"
+)
+
+const (
+	inputModeText          = "text"
+	inputModeVoice         = "voice"
+	inputModeAudio         = "audio"
+	inputModeDocument      = "document"
+	inputModeButton        = "button"
+	inputModeReplyKeyboard = "reply_keyboard"
+	inputModePoll          = "poll"
+	inputModeReaction      = "reaction"
+	inputModeHotkey        = "hotkey"
+	inputModeWebApp        = "web_app"
 )
 
 // Handler processes Telegram updates and resolves executions.
 type Handler struct {
-	bot         *telego.Bot
-	registry    *executions.Registry
-	messages    map[string]i18n.Messages
-	defaultLang string
-	chatID      int64
-	sttLang     string
-	transcriber Transcriber
-	log         *slog.Logger
+	bot             *outbound.Bot
+	registry        *executions.Registry
+	state           *state.Store
+	messages        map[string]i18n.Messages
+	defaultLang     string
+	defaultTimezone string
+	chatID          int64
+	tenantChats     map[int64]struct{}
+	chatsMu         sync.Mutex
+	lostChats       map[int64]string
+	routedChats     map[int64]struct{}
+	sttLang         string
+	transcriber     Transcriber
+	normalizer      AnswerNormalizer
+	editGrace       time.Duration
+	maxDocumentSize int64
+	voiceConfirm    bool
+	voiceLimits     VoiceLimits
+	matchThreshold  float64
+	finalizeMode    string
+	decisionsChat   int64
+	legacyStatus    bool
+	keyboards       KeyboardBuilder
+	updates         *updateQueue
+	lastProcessed   atomic.Int64
+	observeLatency  func(time.Duration)
+	status          *operationalStatus
+	theme           shared.Theme
+	queue           *transcriptionQueue
+	lifecycle       *lifecycle
+	bus             *events.Bus
+	reporter        reporting.Reporter
+	log             *slog.Logger
+}
+
+// Finalization modes select how the prompt is updated once execution resolves.
+const (
+	// FinalizeModeEdit rewrites the prompt with the result note.
+	FinalizeModeEdit = "edit"
+	// FinalizeModeReply keeps the prompt text and posts the result note as a reply to it.
+	FinalizeModeReply = "reply"
+)
+
+// VoiceLimits caps voice messages and audio files accepted for transcription (zero disables a limit).
+type VoiceLimits struct {
+	MaxDuration time.Duration
+	MaxSize     int64
+	// Concurrency is the number of transcription workers.
+	Concurrency int
+	// QueueSize is the number of voice messages waiting for a worker.
+	QueueSize int
 }
 
 // Transcriber converts audio to text.
 type Transcriber interface {
-	Transcribe(ctx context.Context, reader io.Reader, filename, contentType, language string) (string, error)
+	Transcribe(ctx context.Context, reader io.Reader, filename, contentType, language string, hints []string) (string, error)
+}
+
+// AnswerNormalizer interprets free-form answer according to JSON schema.
+type AnswerNormalizer interface {
+	Normalize(ctx context.Context, question string, options []string, answer string, schema map[string]any) (map[string]any, error)
+}
+
+// Options configures the update handler.
+type Options struct {
+	// Messages are the localized texts by language, DefaultLang is used for chats without a /lang preference.
+	Messages        map[string]i18n.Messages
+	DefaultLang     string
+	DefaultTimezone string
+	// ChatID is the default chat; TenantChats are the chats of configured tenants.
+	ChatID      int64
+	TenantChats []int64
+	// STTLang is the language hint for Transcriber; a nil Transcriber disables voice answers.
+	STTLang     string
+	Transcriber Transcriber
+	// Normalizer maps custom answers onto spec.output_schema when set.
+	Normalizer AnswerNormalizer
+	// EditGrace delays custom text answers so that message edits within it replace the answer (0 disables).
+	EditGrace time.Duration
+	// MaxDocumentSize caps documents accepted as custom answers, in bytes.
+	MaxDocumentSize int64
+	// VoiceConfirm asks to confirm a transcription before it is submitted.
+	VoiceConfirm bool
+	VoiceLimits  VoiceLimits
+	// MatchThreshold is the minimal confidence to resolve a custom answer as one of the options (0 disables).
+	MatchThreshold float64
+	// FinalizeMode is FinalizeModeEdit or FinalizeModeReply.
+	FinalizeMode string
+	// DecisionsChat receives a compact summary of every resolution (0 disables).
+	DecisionsChat int64
+	Theme         shared.Theme
 }
 
 // NewHandler creates a new update handler.
-func NewHandler(bot *telego.Bot, registry *executions.Registry, messages map[string]i18n.Messages, defaultLang string, chatID int64, sttLang string, transcriber Transcriber, log *slog.Logger) *Handler {
+func NewHandler(bot *outbound.Bot, registry *executions.Registry, store *state.Store, opts Options, bus *events.Bus, reporter reporting.Reporter, log *slog.Logger) *Handler {
 	return &Handler{
-		bot:         bot,
-		registry:    registry,
-		messages:    messages,
-		defaultLang: defaultLang,
-		chatID:      chatID,
-		sttLang:     sttLang,
-		transcriber: transcriber,
-		log:         log,
+		bot:             bot,
+		registry:        registry,
+		state:           store,
+		messages:        opts.Messages,
+		defaultLang:     opts.DefaultLang,
+		defaultTimezone: opts.DefaultTimezone,
+		chatID:          opts.ChatID,
+		routedChats:     make(map[int64]struct{}),
+		tenantChats:     chatSet(opts.TenantChats),
+		lostChats:       make(map[int64]string),
+		sttLang:         opts.STTLang,
+		status:          newOperationalStatus(),
+		transcriber:     opts.Transcriber,
+		normalizer:      opts.Normalizer,
+		editGrace:       opts.EditGrace,
+		maxDocumentSize: opts.MaxDocumentSize,
+		voiceConfirm:    opts.VoiceConfirm,
+		voiceLimits:     opts.VoiceLimits,
+		matchThreshold:  opts.MatchThreshold,
+		finalizeMode:    opts.FinalizeMode,
+		decisionsChat:   opts.DecisionsChat,
+		theme:           opts.Theme,
+		queue:           newTranscriptionQueue(opts.VoiceLimits.Concurrency, opts.VoiceLimits.QueueSize),
+		updates:         newUpdateQueue(),
+		lifecycle:       newLifecycle(),
+		bus:             bus,
+		reporter:        reporter,
+		log:             log,
 	}
 }
 
-// Run processes updates until context cancellation.
+// Run processes updates until context cancellation. Updates of a chat are handled in order, different chats
+// concurrently; Run returns once running updates are done.
 func (h *Handler) Run(ctx context.Context, updates <-chan telego.Update) {
+	h.startTranscriptionWorkers(ctx)
+	defer h.updates.running.Wait()
 	for {
 		select {
 		case <-ctx.Done():
@@ -72,13 +207,57 @@ func (h *Handler) Run(ctx context.Context, updates <-chan telego.Update) {
 			if !ok {
 				return
 			}
-			h.HandleUpdate(ctx, update)
+			h.dispatch(ctx, update)
 		}
 	}
 }
 
+// SetLatencyObserver sets a function receiving the handling time of every update; call it before Run.
+func (h *Handler) SetLatencyObserver(observe func(time.Duration)) {
+	h.observeLatency = observe
+}
+
+// SetLegacyStatus makes callbacks and the status endpoint report every failure as status "error"; call it before
+// Run.
+func (h *Handler) SetLegacyStatus(legacy bool) {
+	h.legacyStatus = legacy
+}
+
+// LastProcessed returns when handling of the latest update finished, or zero time before the first one.
+func (h *Handler) LastProcessed() time.Time {
+	processed := h.lastProcessed.Load()
+	if processed == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, processed)
+}
+
+// BusySince returns when processing of the longest running update started, or zero time when idle.
+func (h *Handler) BusySince() time.Time {
+	return h.updates.busySince()
+}
+
+// Backlog returns the number of received updates waiting for their chat to finish the previous one.
+func (h *Handler) Backlog() int {
+	return h.updates.backlog()
+}
+
+func (h *Handler) handleUpdateSafe(ctx context.Context, update telego.Update) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err := reporting.PanicError(recovered)
+			h.log.ErrorContext(ctx, "Panic while handling telegram update", "error", err, "update_id", update.UpdateID)
+			h.reporter.Report(ctx, err, reporting.Tags(reporting.TagComponent, "telegram", reporting.TagOperation, "handle_update"))
+		}
+	}()
+	h.HandleUpdate(ctx, update)
+}
+
 // HandleUpdate processes a single update.
 func (h *Handler) HandleUpdate(ctx context.Context, update telego.Update) {
+	ctx = withUserLang(ctx, updateSender(update))
+	ctx = withSender(ctx, updateSender(update))
+	ctx = WithChat(ctx, updateChat(update))
 	if update.CallbackQuery != nil {
 		h.handleCallback(ctx, update.CallbackQuery)
 		return
@@ -87,17 +266,52 @@ func (h *Handler) HandleUpdate(ctx context.Context, update telego.Update) {
 		h.handleMessage(ctx, update.Message)
 		return
 	}
+	if update.EditedMessage != nil {
+		h.handleEditedMessage(ctx, update.EditedMessage)
+		return
+	}
+	if update.PollAnswer != nil {
+		h.handlePollAnswer(ctx, update.PollAnswer)
+		return
+	}
+	if update.MessageReaction != nil {
+		h.handleReaction(ctx, update.MessageReaction)
+		return
+	}
+	if update.MyChatMember != nil {
+		h.handleMyChatMember(ctx, update.MyChatMember)
+		return
+	}
+	if update.InlineQuery != nil {
+		h.handleInlineQuery(ctx, update.InlineQuery)
+		return
+	}
 }
 
 func (h *Handler) handleCallback(ctx context.Context, query *telego.CallbackQuery) {
 	if query.Message == nil {
+		if query.InlineMessageID != "" {
+			h.handleInlineCallback(ctx, query)
+		}
 		return
 	}
 	if !h.allowedChat(query.Message.GetChat().ID) {
-		_ = h.answerCallback(ctx, query, h.messageFor("").InvalidChat)
+		_ = h.answerCallback(ctx, query, h.messagesFor(ctx, nil).InvalidChat)
 		return
 	}
 	action, payload := parseCallback(query.Data)
+	correlationID := callbackCorrelationID(action, payload)
+	exec := h.registry.Get(correlationID)
+	if exec != nil {
+		ctx = WithExecution(ctx, exec)
+	}
+	if h.rejectCallback(ctx, query, action, exec) {
+		return
+	}
+	if exec == nil && correlationID != "" && isPromptAction(action) {
+		h.healStaleKeyboard(ctx, query, correlationID)
+		return
+	}
 
 	switch action {
 	case ActionOption:
@@ -108,114 +322,452 @@ func (h *Handler) handleCallback(ctx context.Context, query *telego.CallbackQuer
 		h.cancelCustomPrompt(ctx, query, payload)
 	case ActionDelete:
 		h.deleteMessage(ctx, query, payload)
+	case ActionDetails:
+		h.toggleDetails(ctx, query, payload)
+	case ActionSubmit:
+		h.submitAnswerCallback(ctx, query, payload)
+	case ActionVoiceUse:
+		h.useTranscription(ctx, query, payload)
+	case ActionVoiceRetry:
+		h.retryTranscription(ctx, query, payload)
+	case ActionVoiceEdit:
+		h.editTranscription(ctx, query, payload)
+	case ActionAnswered:
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, payload))
 	default:
-		_ = h.answerCallback(ctx, query, h.messageFor("").InvalidAction)
+		_ = h.answerCallback(ctx, query, h.messagesFor(ctx, nil).InvalidAction)
 	}
 }
 
 func (h *Handler) handleMessage(ctx context.Context, message *telego.Message) {
+	if h.handleMigration(message) {
+		return
+	}
 	if !h.allowedChat(message.Chat.ID) {
 		return
 	}
-	exec, _ := h.registry.CurrentPrompt()
-	if exec == nil || !exec.AwaitingText {
+	if message.WebAppData != nil {
+		h.handleWebAppData(ctx, message.WebAppData, message.From)
 		return
 	}
-	if message.Text != "" {
-		answer := strings.TrimSpace(message.Text)
-		if answer == "" {
+	if h.handleCommand(ctx, message) {
+		return
+	}
+	if h.handleReplyShortcut(ctx, message) {
+		return
+	}
+	exec, ambiguous := h.awaitingExecution(ctx, message)
+	if exec == nil {
+		keyboardExec := h.registry.Latest(h.currentChat(ctx), executions.AnswerModeReplyKeyboard)
+		if keyboardExec != nil {
+			if index, ok := matchOption(message.Text, keyboardExec.Request); ok {
+				if !canAnswer(keyboardExec, message.From) {
+					_ = h.reply(ctx, h.assigneeOnlyNote(ctx, keyboardExec))
+					return
+				}
+				h.selectOption(ctx, keyboardExec.Request.CorrelationID, index, inputModeReplyKeyboard)
+				return
+			}
+		}
+		if hotkeyExec := h.registry.LatestWithHotkeys(h.currentChat(ctx)); hotkeyExec != nil {
+			if index, ok := matchHotkey(message.Text, hotkeyExec.Request.Keyboard.Hotkeys); ok {
+				if !canAnswer(hotkeyExec, message.From) {
+					_ = h.reply(ctx, h.assigneeOnlyNote(ctx, hotkeyExec))
+					return
+				}
+				h.selectOption(WithExecution(ctx, hotkeyExec), hotkeyExec.Request.CorrelationID, index, inputModeHotkey)
+				return
+			}
+		}
+		if ambiguous {
+			_ = h.reply(ctx, h.messagesFor(ctx, nil).ReplyToPrompt)
 			return
 		}
-		exec, promptID, ok := h.registry.Resolve(exec.Request.CorrelationID)
-		if !ok {
+		if keyboardExec == nil || !keyboardExec.Request.AllowCustom {
 			return
 		}
-		if promptID > 0 {
-			_ = h.DeleteMessage(ctx, promptID)
+		exec = keyboardExec
+	}
+	ctx = WithExecution(ctx, exec)
+	if !canAnswer(exec, message.From) {
+		_ = h.reply(ctx, h.assigneeOnlyNote(ctx, exec))
+		return
+	}
+	multiMessage := exec.Request.MultiMessage && exec.Prompt != nil
+	if message.Text != "" {
+		if multiMessage {
+			h.collectAnswerPart(ctx, exec, message.MessageID, message.Text, inputModeText)
+			return
 		}
-		output := map[string]any{
-			"question":        exec.Request.Question,
-			"selected_option": answer,
-			"selected_index":  nil,
-			"custom":          true,
-			"input_mode":      "text",
+		if h.editGrace > 0 {
+			h.holdAnswer(ctx, exec.Request.CorrelationID, message.MessageID, message.Text)
+			return
 		}
-		note := fmt.Sprintf("✅ %s: %s", h.messageFor(exec.Request.Lang).SelectedNote, answer)
-		h.FinalizeExecution(ctx, exec, executions.Result{Status: executions.StatusSuccess, Output: output, Note: note}, "")
+		h.resolveCustom(ctx, exec.Request.CorrelationID, message.Text, inputModeText)
 		return
 	}
-	if message.Voice != nil {
-		answer, err := h.transcribeVoice(ctx, message.Voice)
+	if message.Document != nil {
+		answer, err := h.readDocumentAnswer(ctx, message.Document, message.Caption)
 		if err != nil {
-			if errors.Is(err, errTranscriberDisabled) {
-				_ = h.reply(ctx, h.messageFor(exec.Request.Lang).VoiceDisabled)
-			} else {
-				_ = h.reply(ctx, h.messageFor(exec.Request.Lang).TranscriptionFailed)
-			}
+			h.replyDocumentError(ctx, h.langFor(ctx, exec), err)
 			return
 		}
-		answer = strings.TrimSpace(answer)
-		if answer == "" {
+		if multiMessage {
+			h.collectAnswerPart(ctx, exec, message.MessageID, answer, inputModeDocument)
 			return
 		}
-		exec, promptID, ok := h.registry.Resolve(exec.Request.CorrelationID)
+		h.resolveCustom(ctx, exec.Request.CorrelationID, answer, inputModeDocument)
+		return
+	}
+	if audio := audioFromMessage(message); audio != nil {
+		h.enqueueTranscription(ctx, exec, message, audio, multiMessage)
+		return
+	}
+}
+
+// handlePollAnswer resolves execution once an option collects poll quorum.
+func (h *Handler) handlePollAnswer(ctx context.Context, answer *telego.PollAnswer) {
+	if answer.User == nil {
+		return
+	}
+	if exec := h.registry.FindByPoll(answer.PollID); exec != nil && !canAnswer(exec, answer.User) {
+		return
+	}
+	exec, optionIndex, resolved := h.registry.RecordPollAnswer(answer.PollID, answer.User.ID, answer.OptionIDs)
+	if !resolved {
+		return
+	}
+	h.selectOption(WithExecution(ctx, exec), exec.Request.CorrelationID, optionIndex, inputModePoll)
+}
+
+// handleReaction resolves execution when a mapped emoji reaction is set on its prompt.
+func (h *Handler) handleReaction(ctx context.Context, reaction *telego.MessageReactionUpdated) {
+	if !h.allowedChat(reaction.Chat.ID) {
+		return
+	}
+	exec := h.registry.FindByMessage(reaction.Chat.ID, reaction.MessageID)
+	if exec == nil || len(exec.Request.Reactions) == 0 || !canAnswer(exec, reaction.User) {
+		return
+	}
+	for _, item := range reaction.NewReaction {
+		emoji, ok := item.(*telego.ReactionTypeEmoji)
 		if !ok {
+			continue
+		}
+		if optionIndex, ok := exec.Request.Reactions[emoji.Emoji]; ok {
+			h.selectOption(ctx, exec.Request.CorrelationID, optionIndex, inputModeReaction)
 			return
 		}
-		if promptID > 0 {
-			_ = h.DeleteMessage(ctx, promptID)
+	}
+}
+
+// awaitingExecution finds execution the custom answer belongs to: by reply to its prompt,
+// otherwise the only execution awaiting custom input. Ambiguous answers are not guessed.
+func (h *Handler) awaitingExecution(ctx context.Context, message *telego.Message) (*executions.Execution, bool) {
+	if message.ReplyToMessage != nil {
+		if exec := h.registry.FindByPrompt(h.currentChat(ctx), message.ReplyToMessage.MessageID); exec != nil {
+			return exec, false
 		}
-		output := map[string]any{
-			"question":        exec.Request.Question,
-			"selected_option": answer,
-			"selected_index":  nil,
-			"custom":          true,
-			"input_mode":      "voice",
+	}
+	awaiting := h.registry.AwaitingCustomInput(h.currentChat(ctx))
+	switch len(awaiting) {
+	case 0:
+		return nil, false
+	case 1:
+		return awaiting[0], false
+	default:
+		return nil, true
+	}
+}
+
+// resolveCustom resolves execution with free-form answer.
+func (h *Handler) resolveCustom(ctx context.Context, correlationID, answer, inputMode string) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return
+	}
+	if exec := h.registry.Get(correlationID); exec != nil {
+		if index, confidence, ok := fuzzyMatchOption(answer, exec.Request.Options, h.matchThreshold); ok {
+			h.selectOptionWith(ctx, correlationID, index, inputMode, answer, confidence)
+			return
 		}
-		note := fmt.Sprintf("✅ %s: %s", h.messageFor(exec.Request.Lang).SelectedNote, answer)
-		h.FinalizeExecution(ctx, exec, executions.Result{Status: executions.StatusSuccess, Output: output, Note: note}, "")
+	}
+	exec, promptID, ok := h.registry.Resolve(correlationID)
+	if !ok {
 		return
 	}
+	if promptID > 0 {
+		_ = h.DeleteMessage(ctx, promptID)
+	}
+	output := executions.CustomAnswer{Question: exec.Request.Question, Answer: answer, InputMode: inputMode}
+	if h.normalizer != nil && exec.Request.OutputSchema != nil {
+		// Normalization is an LLM call of up to TG_EXECUTOR_NORMALIZE_TIMEOUT; it runs off the chat update worker so
+		// that later updates of the chat are not held up meanwhile. The execution is already resolved, so nothing races it.
+		normalized := h.Go(ctx, func(ctx context.Context) {
+			interpretation, err := h.normalizer.Normalize(ctx, exec.Request.Question, exec.Request.Options, answer, exec.Request.OutputSchema)
+			if err != nil {
+				h.log.WarnContext(ctx, "Failed to normalize custom answer", "error", err, "correlation_id", correlationID)
+			} else {
+				output.Interpretation = interpretation
+			}
+			h.finalizeCustom(ctx, exec, output)
+		})
+		if normalized {
+			return
+		}
+		h.log.WarnContext(ctx, "Custom answer normalization skipped on shutdown", "correlation_id", correlationID)
+	}
+	h.finalizeCustom(ctx, exec, output)
+}
+
+// finalizeCustom reports the resolved free-form answer.
+func (h *Handler) finalizeCustom(ctx context.Context, exec *executions.Execution, output executions.CustomAnswer) {
+	note := shared.WithEmoji(h.theme.Success, h.messagesFor(ctx, exec).SelectedNote+": "+output.Answer)
+	h.emitAnswer(ctx, events.TypeCustomAnswer, exec, output.Answer, nil, output.InputMode)
+	h.FinalizeExecution(ctx, exec, executions.Result{Status: executions.StatusSuccess, Output: output, Note: note}, "")
+}
+
+// selectOption resolves execution with predefined option and returns resolution note.
+func (h *Handler) selectOption(ctx context.Context, correlationID string, optionIndex int, inputMode string) (string, bool) {
+	return h.selectOptionWith(ctx, correlationID, optionIndex, inputMode, "", 0)
+}
+
+// selectOptionWith resolves execution with predefined option; rawAnswer and confidence describe a free-form answer
+// matched to the option.
+func (h *Handler) selectOptionWith(ctx context.Context, correlationID string, optionIndex int, inputMode, rawAnswer string, confidence float64) (string, bool) {
+	exec, promptID, ok := h.registry.Resolve(correlationID)
+	if !ok {
+		return "", false
+	}
+	if promptID > 0 {
+		_ = h.DeleteMessage(ctx, promptID)
+	}
+
+	selected := exec.Request.Options[optionIndex]
+	output := executions.OptionSelected{
+		Question:        exec.Request.Question,
+		Option:          selected,
+		Index:           optionIndex,
+		InputMode:       inputMode,
+		RawAnswer:       rawAnswer,
+		MatchConfidence: confidence,
+	}
+	if option, ok := exec.Request.OptionValue(optionIndex); ok {
+		output.Value, output.Metadata = option.Value, option.Metadata
+	}
+	msg := h.messagesFor(ctx, exec)
+	note := shared.WithEmoji(h.theme.Success, msg.SelectedNote+": "+selected)
+	if optionNote := exec.Request.OptionNote(optionIndex); optionNote != "" {
+		note += "\n" + optionNote
+	}
+	h.emitAnswer(ctx, events.TypeOptionSelected, exec, selected, &optionIndex, inputMode)
+	h.FinalizeExecution(ctx, exec, executions.Result{Status: executions.StatusSuccess, Output: output, Note: note}, "")
+	return note, true
+}
+
+// matchOption maps reply keyboard text ("✅ 2. Label", "2", a hotkey or the option itself) to option index.
+func matchOption(text string, req executions.Request) (int, bool) {
+	options := req.Options
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, false
+	}
+	for idx, option := range options {
+		if strings.EqualFold(text, strings.TrimSpace(option)) {
+			return idx, true
+		}
+	}
+	if index, ok := matchHotkey(text, req.Keyboard.Hotkeys); ok {
+		return index, true
+	}
+	// Skip option_emoji prefix.
+	text = strings.TrimLeftFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	if req.Keyboard.HideNumbers {
+		return matchShortenedOption(text, options)
+	}
+	key, label, _ := strings.Cut(text, ".")
+	index, ok := matchHotkey(key, req.Keyboard.Hotkeys)
+	if !ok {
+		number, err := strconv.Atoi(key)
+		if err != nil || number < 1 || number > len(options) {
+			return 0, false
+		}
+		index = number - 1
+	}
+	// Button labels may be shortened with "...".
+	label = strings.TrimSuffix(strings.TrimSpace(label), "...")
+	if !strings.HasPrefix(strings.TrimSpace(options[index]), label) {
+		return 0, false
+	}
+	return index, true
+}
+
+// matchShortenedOption maps an unnumbered label shortened with "..." to the only option starting with it.
+func matchShortenedOption(text string, options []string) (int, bool) {
+	label, shortened := strings.CutSuffix(text, "...")
+	if !shortened || label == "" {
+		return 0, false
+	}
+	match := -1
+	for idx, option := range options {
+		if strings.HasPrefix(strings.TrimSpace(option), label) {
+			if match >= 0 {
+				return 0, false
+			}
+			match = idx
+		}
+	}
+	return match, match >= 0
+}
+
+// matchHotkey maps text equal to an option hotkey (case-insensitive) to option index.
+func matchHotkey(text string, hotkeys []string) (int, bool) {
+	text = strings.TrimSpace(text)
+	for idx, hotkey := range hotkeys {
+		if strings.EqualFold(text, hotkey) {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// audioInput describes voice message or audio file to transcribe.
+type audioInput struct {
+	FileID    string
+	MimeType  string
+	FileName  string
+	Duration  int
+	FileSize  int64
+	InputMode string
+}
+
+// audioFromMessage returns voice message or audio file attached to message.
+func audioFromMessage(message *telego.Message) *audioInput {
+	switch {
+	case message.Voice != nil:
+		return &audioInput{
+			FileID:    message.Voice.FileID,
+			MimeType:  message.Voice.MimeType,
+			Duration:  message.Voice.Duration,
+			FileSize:  message.Voice.FileSize,
+			InputMode: inputModeVoice,
+		}
+	case message.Audio != nil:
+		return &audioInput{
+			FileID:    message.Audio.FileID,
+			MimeType:  message.Audio.MimeType,
+			FileName:  message.Audio.FileName,
+			Duration:  message.Audio.Duration,
+			FileSize:  message.Audio.FileSize,
+			InputMode: inputModeAudio,
+		}
+	default:
+		return nil
+	}
+}
+
+// transcriptionLanguage picks STT language: request stt_lang, then TG_EXECUTOR_STT_LANG,
+// then sender's Telegram language, then service language. Empty result means auto-detect.
+func (h *Handler) transcriptionLanguage(exec *executions.Execution, from *telego.User) string {
+	lang := exec.Request.STTLang
+	if lang == "" {
+		lang = h.sttLang
+	}
+	if lang == "" && from != nil {
+		lang = from.LanguageCode
+	}
+	if lang == "" {
+		lang = h.defaultLang
+	}
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == executions.STTLangAuto {
+		return ""
+	}
+	// Whisper-style APIs expect ISO-639-1; drop the region ("pt-br" -> "pt").
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		return base
+	}
+	return lang
+}
+
+// transcriptionHints returns question and option labels used to bias recognition of domain terms.
+func transcriptionHints(exec *executions.Execution) []string {
+	hints := make([]string, 0, len(exec.Request.Options)+1)
+	hints = append(hints, exec.Request.Options...)
+	return append(hints, exec.Request.Question)
 }
 
-func (h *Handler) transcribeVoice(ctx context.Context, voice *telego.Voice) (string, error) {
+func (h *Handler) transcribeAudio(ctx context.Context, audio *audioInput, language string, hints []string) (string, error) {
 	if h.transcriber == nil {
 		return "", errTranscriberDisabled
 	}
-	file, err := h.bot.GetFile(ctx, &telego.GetFileParams{FileID: voice.FileID})
+	if !h.voiceLimits.allow(time.Duration(audio.Duration)*time.Second, audio.FileSize) {
+		return "", errAudioTooLong
+	}
+	file, err := h.bot.API().GetFile(ctx, &telego.GetFileParams{FileID: audio.FileID})
 	if err != nil {
 		return "", err
 	}
-	audioURL := h.bot.FileDownloadURL(file.FilePath)
+	if !h.voiceLimits.allow(0, file.FileSize) {
+		return "", errAudioTooLong
+	}
+	audioURL := h.bot.API().FileDownloadURL(file.FilePath)
 	data, err := tu.DownloadFile(audioURL)
 	if err != nil {
 		return "", err
 	}
-	normalized, mimeType, fileName, err := normalizeVoiceAudio(ctx, data, "", file.FilePath)
+	fileName := audio.FileName
+	if fileName == "" {
+		fileName = file.FilePath
+	}
+	// Voice notes are always ogg/opus; only audio files carry a meaningful mime type.
+	mimeType := ""
+	if audio.InputMode == inputModeAudio {
+		mimeType = audio.MimeType
+	}
+	format := ""
+	if formatter, ok := h.transcriber.(AudioFormatter); ok {
+		format = formatter.AudioFormat()
+	}
+	normalized, mimeType, fileName, err := normalizeVoiceAudio(ctx, data, mimeType, fileName, format)
 	if err != nil {
 		return "", err
 	}
 	reader := bytes.NewReader(normalized)
-	return h.transcriber.Transcribe(ctx, reader, fileName, mimeType, h.sttLang)
+	return h.transcriber.Transcribe(ctx, reader, fileName, mimeType, language, hints)
 }
 
-var errTranscriberDisabled = errors.New("transcriber disabled")
+var (
+	errTranscriberDisabled = errors.New("transcriber disabled")
+	errAudioTooLong        = errors.New("audio exceeds limits")
+)
 
-func (h *Handler) allowedChat(chatID int64) bool {
-	return chatID == h.chatID
+func (l VoiceLimits) allow(duration time.Duration, size int64) bool {
+	if l.MaxDuration > 0 && duration > l.MaxDuration {
+		return false
+	}
+	return l.MaxSize <= 0 || size <= l.MaxSize
+}
+
+// formatDuration renders limit as "5m" or "90s" for user messages.
+func formatDuration(value time.Duration) string {
+	if value%time.Minute == 0 {
+		return fmt.Sprintf("%dm", int(value/time.Minute))
+	}
+	return fmt.Sprintf("%ds", int(value/time.Second))
 }
 
 func (h *Handler) answerCallback(ctx context.Context, query *telego.CallbackQuery, text string) error {
 	params := &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID}
 	if strings.TrimSpace(text) != "" {
-		params.Text = text
+		params.Text = shared.TruncateRunes(text, shared.MaxCallbackAnswerLength, "…")
 	}
-	return h.bot.AnswerCallbackQuery(ctx, params)
+	return h.bot.API().AnswerCallbackQuery(ctx, params)
 }
 
 func (h *Handler) reply(ctx context.Context, text string) error {
 	_, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
-		ChatID:    tu.ID(h.chatID),
+		ChatID:    tu.ID(h.currentChat(ctx)),
 		Text:      text,
 		ParseMode: telego.ModeMarkdown,
 	})
@@ -225,7 +777,7 @@ func (h *Handler) reply(ctx context.Context, text string) error {
 func (h *Handler) deleteMessage(ctx context.Context, query *telego.CallbackQuery, payload string) {
 	messageID, err := strconv.Atoi(payload)
 	if err != nil || messageID <= 0 {
-		_ = h.answerCallback(ctx, query, h.messageFor("").InvalidAction)
+		_ = h.answerCallback(ctx, query, h.messagesFor(ctx, nil).InvalidAction)
 		return
 	}
 	_ = h.DeleteMessage(ctx, messageID)
@@ -240,6 +792,11 @@ func CallbackData(action, payload string) string {
 	return action + ":" + payload
 }
 
+// OptionCallbackData builds callback data of the button selecting option index of the execution.
+func OptionCallbackData(correlationID string, index int) string {
+	return CallbackData(ActionOption, correlationID+"|"+strconv.Itoa(index))
+}
+
 func parseCallback(data string) (string, string) {
 	parts := strings.SplitN(data, ":", 2)
 	if len(parts) == 1 {
@@ -248,6 +805,19 @@ func parseCallback(data string) (string, string) {
 	return parts[0], parts[1]
 }
 
+// callbackCorrelationID returns correlation id the callback refers to, or empty string.
+func callbackCorrelationID(action, payload string) string {
+	switch action {
+	case ActionDelete:
+		return ""
+	case ActionOption:
+		correlationID, _, _ := parseOptionPayload(payload)
+		return correlationID
+	default:
+		return payload
+	}
+}
+
 func parseOptionPayload(payload string) (string, int, error) {
 	parts := strings.SplitN(payload, "|", 2)
 	if len(parts) != 2 {
@@ -263,111 +833,353 @@ func parseOptionPayload(payload string) (string, int, error) {
 func (h *Handler) resolveOption(ctx context.Context, query *telego.CallbackQuery, payload string) {
 	correlationID, optionIndex, err := parseOptionPayload(payload)
 	if err != nil {
-		_ = h.answerCallback(ctx, query, h.messageFor("").InvalidAction)
+		_ = h.answerCallback(ctx, query, h.messagesFor(ctx, nil).InvalidAction)
 		return
 	}
 
 	exec := h.registry.Get(correlationID)
 	if exec == nil {
-		_ = h.answerCallback(ctx, query, h.messageFor("").AlreadyResolved)
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
 		return
 	}
 	if optionIndex < 0 || optionIndex >= len(exec.Request.Options) {
-		_ = h.answerCallback(ctx, query, h.messageFor(exec.Request.Lang).InvalidAction)
+		_ = h.answerCallback(ctx, query, h.messagesFor(ctx, exec).InvalidAction)
 		return
 	}
 
-	exec, promptID, ok := h.registry.Resolve(correlationID)
+	note, ok := h.selectOption(ctx, correlationID, optionIndex, inputModeButton)
 	if !ok {
-		_ = h.answerCallback(ctx, query, h.messageFor("").AlreadyResolved)
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
 		return
 	}
-	if promptID > 0 {
-		_ = h.DeleteMessage(ctx, promptID)
-	}
-
-	selected := exec.Request.Options[optionIndex]
-	output := map[string]any{
-		"question":        exec.Request.Question,
-		"selected_option": selected,
-		"selected_index":  optionIndex,
-		"custom":          false,
-		"input_mode":      "button",
-	}
-	msg := h.messageFor(exec.Request.Lang)
-	note := fmt.Sprintf("✅ %s: %s", msg.SelectedNote, selected)
-	h.FinalizeExecution(ctx, exec, executions.Result{Status: executions.StatusSuccess, Output: output, Note: note}, "")
 	_ = h.answerCallback(ctx, query, note)
 }
 
 func (h *Handler) startCustomPrompt(ctx context.Context, query *telego.CallbackQuery, correlationID string) {
 	exec := h.registry.Get(correlationID)
 	if exec == nil {
-		_ = h.answerCallback(ctx, query, h.messageFor("").AlreadyResolved)
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
 		return
 	}
 	if !exec.Request.AllowCustom {
-		_ = h.answerCallback(ctx, query, h.messageFor(exec.Request.Lang).InvalidAction)
+		_ = h.answerCallback(ctx, query, h.messagesFor(ctx, exec).InvalidAction)
 		return
 	}
-	prevPromptID, ok := h.registry.StartCustomInput(correlationID)
+	prevPromptID, ok := h.registry.StartCustomInput(correlationID, userDisplayName(&query.From))
 	if !ok {
-		_ = h.answerCallback(ctx, query, h.messageFor(exec.Request.Lang).AlreadyResolved)
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
 		return
 	}
 	if prevPromptID > 0 {
 		_ = h.DeleteMessage(ctx, prevPromptID)
 	}
-	msg := h.messageFor(exec.Request.Lang)
-	mode := parseMode(exec.Request.Markup)
-	promptText := renderModeText(msg.CustomPrompt, mode)
+	msg := h.messagesFor(ctx, exec)
+	mode := shared.ParseMode(exec.Request.Markup)
+	customPrompt := msg.CustomPrompt
+	if exec.Request.MultiMessage {
+		customPrompt = msg.CustomPromptMulti
+	}
+	promptText := shared.Escape(mode, customPrompt)
 	prompt, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
-		ChatID:    tu.ID(h.chatID),
+		ChatID:    tu.ID(h.currentChat(ctx)),
 		Text:      promptText,
 		ParseMode: mode,
 		ReplyParameters: (&telego.ReplyParameters{
 			MessageID: exec.MessageID,
 		}).WithAllowSendingWithoutReply(),
-		ReplyMarkup: h.promptKeyboard(exec.Request.Lang, exec.Request.CorrelationID),
+		// Answers are bound to the execution by replying to this prompt.
+		ReplyMarkup: tu.ForceReply().WithSelective().WithInputFieldPlaceholder(shortenPlaceholder(customPrompt)),
 	})
 	if err != nil {
-		h.log.Error("Failed to send custom prompt", "error", err)
+		h.log.ErrorContext(ctx, "Failed to send custom prompt", "error", err)
+		h.reportTelegramError(ctx, err, "send_custom_prompt", correlationID)
+		h.registry.ClearPrompt(correlationID)
 		_ = h.answerCallback(ctx, query, msg.ErrorNote)
 		return
 	}
 	h.registry.SetPromptMessage(correlationID, prompt.MessageID)
+	h.swapCustomButton(ctx, query, exec, ActionCustom, ActionCancelCustom, msg.CancelCustomButton)
+	h.NotifyCallback(ctx, exec, executions.CallbackEventCustomInputStarted, userDisplayName(&query.From))
 	_ = h.answerCallback(ctx, query, "")
 }
 
 func (h *Handler) cancelCustomPrompt(ctx context.Context, query *telego.CallbackQuery, correlationID string) {
+	if _, statusID := h.registry.AnswerParts(correlationID); statusID > 0 {
+		_ = h.DeleteMessage(ctx, statusID)
+	}
 	promptID := h.registry.ClearPrompt(correlationID)
 	if promptID > 0 {
 		_ = h.DeleteMessage(ctx, promptID)
 	}
+	if exec := h.registry.Get(correlationID); exec != nil {
+		h.swapCustomButton(ctx, query, exec, ActionCancelCustom, ActionCustom, h.messagesFor(ctx, exec).CustomOptionButton)
+	}
+	_ = h.answerCallback(ctx, query, "")
+}
+
+// swapCustomButton switches the custom option button of the execution message between start and cancel actions
+// and shows or clears the "composing an answer" note.
+func (h *Handler) swapCustomButton(ctx context.Context, query *telego.CallbackQuery, exec *executions.Execution, fromAction, toAction, label string) {
+	message := query.Message.Message()
+	if message == nil || message.MessageID != exec.MessageID {
+		return
+	}
+	if strings.TrimSpace(label) == "" {
+		return
+	}
+	correlationID := exec.Request.CorrelationID
+	keyboard, ok := replaceButton(message.ReplyMarkup, CallbackData(fromAction, correlationID), label, CallbackData(toAction, correlationID))
+	if !ok {
+		return
+	}
+	_, err := h.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+		ChatID:      tu.ID(h.currentChat(ctx)),
+		MessageID:   exec.MessageID,
+		Text:        h.promptText(ctx, exec),
+		ParseMode:   shared.ParseMode(exec.Request.Markup),
+		Entities:    exec.DisplayEntities(),
+		ReplyMarkup: keyboard,
+	})
+	if err != nil && !messageNotModified(err) {
+		h.log.ErrorContext(ctx, "Failed to update custom option button", "error", err, "correlation_id", correlationID)
+	}
+}
+
+func (h *Handler) toggleDetails(ctx context.Context, query *telego.CallbackQuery, correlationID string) {
+	exec, ok := h.registry.ToggleDetails(correlationID)
+	if !ok {
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
+		return
+	}
+	msg := h.messagesFor(ctx, exec)
+	label := msg.ShowDetailsButton
+	if exec.DetailsShown {
+		label = msg.HideDetailsButton
+	}
+	var keyboard *telego.InlineKeyboardMarkup
+	if message := query.Message.Message(); message != nil {
+		keyboard = relabelButton(message.ReplyMarkup, CallbackData(ActionDetails, correlationID), label)
+	}
+	_, err := h.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+		ChatID:      tu.ID(h.currentChat(ctx)),
+		MessageID:   exec.MessageID,
+		Text:        h.promptText(ctx, exec),
+		ParseMode:   shared.ParseMode(exec.Request.Markup),
+		Entities:    exec.DisplayEntities(),
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		h.log.ErrorContext(ctx, "Failed to toggle execution details", "error", err, "correlation_id", correlationID)
+		h.reportTelegramError(ctx, err, "toggle_details", correlationID)
+		_ = h.answerCallback(ctx, query, msg.ErrorNote)
+		return
+	}
 	_ = h.answerCallback(ctx, query, "")
 }
 
+// relabelButton returns copy of keyboard with the button matching callback data renamed.
+func relabelButton(markup *telego.InlineKeyboardMarkup, callbackData, label string) *telego.InlineKeyboardMarkup {
+	keyboard, _ := replaceButton(markup, callbackData, label, callbackData)
+	return keyboard
+}
+
+// replaceButton returns copy of keyboard with the button matching callback data replaced and whether it was found.
+func replaceButton(markup *telego.InlineKeyboardMarkup, callbackData, label, newCallbackData string) (*telego.InlineKeyboardMarkup, bool) {
+	if markup == nil {
+		return nil, false
+	}
+	found := false
+	rows := make([][]telego.InlineKeyboardButton, 0, len(markup.InlineKeyboard))
+	for _, row := range markup.InlineKeyboard {
+		buttons := make([]telego.InlineKeyboardButton, len(row))
+		copy(buttons, row)
+		for idx := range buttons {
+			if buttons[idx].CallbackData == callbackData {
+				buttons[idx].Text = label
+				buttons[idx].CallbackData = newCallbackData
+				found = true
+			}
+		}
+		rows = append(rows, buttons)
+	}
+	return tu.InlineKeyboard(rows...), found
+}
+
 // FinalizeExecution updates Telegram message and sends webhook callback.
 func (h *Handler) FinalizeExecution(ctx context.Context, exec *executions.Execution, result executions.Result, timeoutMessage string) {
-	msg := h.messageFor(exec.Request.Lang)
+	ctx = WithExecution(ctx, exec)
+	msg := h.messagesFor(ctx, exec)
 	note := h.noteForResult(msg, result, timeoutMessage)
-	mode := parseMode(exec.Request.Markup)
-	note = renderModeText(note, mode)
-	text := exec.MessageText
+	replyKeyboard := exec.Request.AnswerMode == executions.AnswerModeReplyKeyboard
+	if h.finalizeMode == FinalizeModeReply {
+		h.finalizeInThread(ctx, exec, msg, note, replyKeyboard)
+	} else {
+		h.finalizeInPlace(ctx, exec, msg, note, replyKeyboard)
+	}
+	if replyKeyboard {
+		h.removeReplyKeyboard(ctx, exec, note)
+	}
+	if exec.PollMessageID > 0 {
+		h.stopPoll(ctx, exec)
+	}
+	if exec.Pinned {
+		h.unpinPrompt(ctx, exec)
+	}
+	h.queue.cancel(exec.Request.CorrelationID)
+	h.logDecision(ctx, exec, result, note)
+	h.sendWebhook(ctx, exec, result)
+	h.cancelGroupOf(ctx, exec)
+}
+
+// finalizeInPlace rewrites the prompt with the result note and replaces its keyboard.
+func (h *Handler) finalizeInPlace(ctx context.Context, exec *executions.Execution, msg i18n.Messages, note string, replyKeyboard bool) {
+	mode := shared.ParseMode(exec.Request.Markup)
+	text := exec.DisplayText()
 	if strings.TrimSpace(note) != "" {
-		text = fmt.Sprintf("%s\n\n%s", exec.MessageText, note)
+		text = fmt.Sprintf("%s\n\n%s", text, fitNote(text, note, mode))
 	}
-	_, err := h.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
-		ChatID:      tu.ID(h.chatID),
-		MessageID:   exec.MessageID,
-		Text:        text,
-		ParseMode:   mode,
-		ReplyMarkup: h.resolvedKeyboard(exec.Request.Lang, exec.MessageID),
+	params := &telego.EditMessageTextParams{
+		ChatID:    tu.ID(h.currentChat(ctx)),
+		MessageID: exec.MessageID,
+		Text:      text,
+		ParseMode: mode,
+		Entities:  exec.DisplayEntities(),
+	}
+	if !replyKeyboard {
+		params.ReplyMarkup = h.resolvedKeyboard(ctx, exec)
+	}
+	_, err := h.bot.EditMessageText(ctx, params)
+	if err != nil && !messageNotModified(err) {
+		h.log.ErrorContext(ctx, "Failed to update telegram message", "error", err)
+		h.reportTelegramError(ctx, err, "edit_message", exec.Request.CorrelationID)
+		if !replyKeyboard {
+			h.sendFinalizeFallback(ctx, exec, msg, note, err)
+		}
+	}
+}
+
+// finalizeInThread keeps the prompt text intact for audit and posts the result note as a reply to it.
+// Only the inline keyboard of the prompt is replaced; reply keyboards get their note from removeReplyKeyboard.
+func (h *Handler) finalizeInThread(ctx context.Context, exec *executions.Execution, msg i18n.Messages, note string, replyKeyboard bool) {
+	if replyKeyboard {
+		return
+	}
+	var err error
+	if composing(exec) {
+		// Restore the original prompt text without the "composing an answer" note.
+		_, err = h.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+			ChatID:      tu.ID(h.currentChat(ctx)),
+			MessageID:   exec.MessageID,
+			Text:        exec.DisplayText(),
+			ParseMode:   shared.ParseMode(exec.Request.Markup),
+			Entities:    exec.DisplayEntities(),
+			ReplyMarkup: h.resolvedKeyboard(ctx, exec),
+		})
+	} else {
+		_, err = h.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+			ChatID:      tu.ID(h.currentChat(ctx)),
+			MessageID:   exec.MessageID,
+			ReplyMarkup: h.resolvedKeyboard(ctx, exec),
+		})
+	}
+	if err != nil && !messageNotModified(err) {
+		h.log.WarnContext(ctx, "Failed to replace prompt keyboard", "error", err)
+		h.reportTelegramError(ctx, err, "edit_reply_markup", exec.Request.CorrelationID)
+	}
+	if strings.TrimSpace(note) == "" {
+		note = shared.WithEmoji(h.theme.Success, msg.SelectedNote)
+	}
+	_, err = h.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID: tu.ID(h.currentChat(ctx)),
+		Text:   note,
+		ReplyParameters: (&telego.ReplyParameters{
+			MessageID: exec.MessageID,
+		}).WithAllowSendingWithoutReply(),
 	})
 	if err != nil {
-		h.log.Error("Failed to update telegram message", "error", err)
+		h.log.ErrorContext(ctx, "Failed to reply with execution result", "error", err)
+		h.reportTelegramError(ctx, err, "reply_result", exec.Request.CorrelationID)
+	}
+}
+
+// sendFinalizeFallback posts the result as a new message when the original prompt can no longer be edited
+// (deleted by a human or too old), so the chat still sees how the request ended.
+func (h *Handler) sendFinalizeFallback(ctx context.Context, exec *executions.Execution, msg i18n.Messages, note string, editErr error) {
+	if strings.TrimSpace(note) == "" {
+		note = shared.WithEmoji(h.theme.Success, msg.SelectedNote)
+	}
+	chatID := h.currentChat(ctx)
+	text := msg.Format(msg.FinalizeFallback, i18n.Vars{"question": exec.Request.Question})
+	if link := messageLink(chatID, exec.MessageID); link != "" {
+		text += "\n" + link
+	}
+	text = fmt.Sprintf("%s\n\n%s", text, note)
+	sent, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID: tu.ID(chatID),
+		Text:   text,
+		ReplyParameters: (&telego.ReplyParameters{
+			MessageID: exec.MessageID,
+		}).WithAllowSendingWithoutReply(),
+		LinkPreviewOptions: &telego.LinkPreviewOptions{IsDisabled: true},
+	})
+	if err != nil {
+		h.log.ErrorContext(ctx, "Failed to send finalize fallback message", "error", err)
+		h.reportTelegramError(ctx, err, "finalize_fallback", exec.Request.CorrelationID)
+		return
+	}
+	fallback := events.New(events.TypeFinalizeFallback, exec.Request.CorrelationID, exec.Request.Tool.Name, exec.CreatedAt)
+	fallback.MessageID = sent.MessageID
+	fallback.Error = editErr.Error()
+	h.bus.Emit(fallback)
+}
+
+// messageNotModified reports whether edit failed only because the message already has the requested content.
+func messageNotModified(err error) bool {
+	var apiErr *ta.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Description, "message is not modified")
+}
+
+// unpinPrompt unpins an urgent prompt once it is resolved or timed out.
+func (h *Handler) unpinPrompt(ctx context.Context, exec *executions.Execution) {
+	err := h.bot.UnpinChatMessage(ctx, &telego.UnpinChatMessageParams{
+		ChatID:    tu.ID(h.currentChat(ctx)),
+		MessageID: exec.MessageID,
+	})
+	if err != nil {
+		h.log.WarnContext(ctx, "Failed to unpin prompt", "error", err, "correlation_id", exec.Request.CorrelationID)
+		h.reportTelegramError(ctx, err, "unpin_message", exec.Request.CorrelationID)
+	}
+}
+
+// stopPoll closes execution poll so no more votes are accepted.
+func (h *Handler) stopPoll(ctx context.Context, exec *executions.Execution) {
+	_, err := h.bot.StopPoll(ctx, &telego.StopPollParams{
+		ChatID:    tu.ID(h.currentChat(ctx)),
+		MessageID: exec.PollMessageID,
+	})
+	if err != nil {
+		h.log.ErrorContext(ctx, "Failed to stop telegram poll", "error", err, "correlation_id", exec.Request.CorrelationID)
+		h.reportTelegramError(ctx, err, "stop_poll", exec.Request.CorrelationID)
+	}
+}
+
+// removeReplyKeyboard hides reply keyboard of resolved execution with a short note.
+func (h *Handler) removeReplyKeyboard(ctx context.Context, exec *executions.Execution, note string) {
+	if strings.TrimSpace(note) == "" {
+		note = shared.WithEmoji(h.theme.Success, h.messagesFor(ctx, exec).SelectedNote)
+	}
+	_, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID: tu.ID(h.currentChat(ctx)),
+		Text:   note,
+		ReplyParameters: (&telego.ReplyParameters{
+			MessageID: exec.MessageID,
+		}).WithAllowSendingWithoutReply(),
+		ReplyMarkup: tu.ReplyKeyboardRemove(),
+	})
+	if err != nil {
+		h.log.ErrorContext(ctx, "Failed to remove reply keyboard", "error", err, "correlation_id", exec.Request.CorrelationID)
+		h.reportTelegramError(ctx, err, "remove_reply_keyboard", exec.Request.CorrelationID)
 	}
-	h.sendWebhook(ctx, exec, result)
 }
 
 // DeleteMessage removes a Telegram message.
@@ -376,37 +1188,136 @@ func (h *Handler) DeleteMessage(ctx context.Context, messageID int) error {
 		return nil
 	}
 	return h.bot.DeleteMessage(ctx, &telego.DeleteMessageParams{
-		ChatID:    tu.ID(h.chatID),
+		ChatID:    tu.ID(h.currentChat(ctx)),
 		MessageID: messageID,
 	})
 }
 
+// sendWebhook retains the result for the status endpoint and delivers it to the callback URL.
 func (h *Handler) sendWebhook(ctx context.Context, exec *executions.Execution, result executions.Result) {
 	if exec == nil {
 		return
 	}
-	if strings.TrimSpace(exec.Request.Callback.URL) == "" {
+	if h.legacyStatus {
+		result.Status = result.Status.Legacy()
+	}
+	record := h.registry.Retain(exec, result, callbackBody(exec, result))
+	if record.CallbackBody == nil {
 		return
 	}
+	h.deliverCallback(ctx, exec.Request.CorrelationID, record)
+}
+
+// callbackBody renders callback payload of a resolved execution; nil when the request has no callback URL.
+func callbackBody(exec *executions.Execution, result executions.Result) []byte {
+	if strings.TrimSpace(exec.Request.Callback.URL) == "" {
+		return nil
+	}
 	payload := map[string]any{
-		"correlation_id": exec.Request.CorrelationID,
+		"correlation_id": exec.Request.ClientCorrelationID(),
 		"status":         string(result.Status),
 		"result":         result.Output,
 		"tool":           exec.Request.Tool.Name,
 	}
+	resolvedAt := time.Now().UTC()
+	payload["submitted_at"] = exec.CreatedAt.UTC().Format(time.RFC3339Nano)
+	payload["resolved_at"] = resolvedAt.Format(time.RFC3339Nano)
+	payload["response_seconds"] = math.Round(resolvedAt.Sub(exec.CreatedAt).Seconds()*1000) / 1000
+	if !exec.Request.Deadline.IsZero() {
+		payload["deadline"] = exec.Request.Deadline.UTC().Format(time.RFC3339)
+	}
+	if len(exec.Request.Labels) > 0 {
+		payload["labels"] = exec.Request.Labels
+	}
+	if exec.Request.ChatID != 0 {
+		payload["chat_id"] = exec.Request.ChatID
+	}
+	if exec.MessageID > 0 {
+		payload["message_id"] = exec.MessageID
+		if link := messageLink(exec.Request.ChatID, exec.MessageID); link != "" {
+			payload["message_link"] = link
+		}
+	}
+	if result.CallbackNote != "" {
+		payload["note"] = result.CallbackNote
+	}
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return
+		return nil
+	}
+	return body
+}
+
+// deliverCallback posts retained callback body to the callback URL and emits the delivery event.
+func (h *Handler) deliverCallback(ctx context.Context, correlationID string, record executions.StatusRecord) error {
+	h.trackCallback(correlationID, false)
+	err := postCallback(ctx, record.CallbackURL, record.CallbackEncoding, record.CallbackBody)
+	delivery := events.New(events.TypeCallbackDelivered, correlationID, record.Tool, record.CreatedAt)
+	delivery.Status = string(record.Status)
+	if err != nil {
+		delivery.Type = events.TypeCallbackFailed
+		delivery.Error = err.Error()
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, exec.Request.Callback.URL, bytes.NewReader(body))
+	h.bus.Emit(delivery)
+	h.trackCallback(correlationID, err == nil)
 	if err != nil {
-		return
+		h.reporter.Report(ctx, err, reporting.Tags(
+			reporting.TagComponent, "callback",
+			reporting.TagOperation, "deliver",
+			reporting.TagCorrelationID, correlationID,
+		))
+	}
+	return err
+}
+
+// postCallback posts JSON body to the callback URL and fails on non-2xx responses. Bodies larger than
+// minCompressedCallback are compressed with encoding when the receiver accepts it.
+func postCallback(ctx context.Context, url, encoding string, body []byte) error {
+	if len(body) < minCompressedCallback {
+		encoding = ""
+	}
+	payload, err := compressCallback(body, encoding)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
 	client := &http.Client{Timeout: 10 * time.Second}
-	if _, err := client.Do(req); err != nil {
-		h.log.Error("Webhook delivery failed", "error", err, "correlation_id", exec.Request.CorrelationID)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected callback status %d", resp.StatusCode)
 	}
+	return nil
+}
+
+func (h *Handler) emitAnswer(ctx context.Context, eventType events.Type, exec *executions.Execution, answer string, optionIndex *int, inputMode string) {
+	h.recordAnswer(ctx, exec, answer)
+	event := events.New(eventType, exec.Request.CorrelationID, exec.Request.Tool.Name, exec.CreatedAt)
+	event.MessageID = exec.MessageID
+	event.Answer = answer
+	event.OptionIndex = optionIndex
+	event.InputMode = inputMode
+	h.bus.Emit(event)
+}
+
+// reportTelegramError reports a failed Telegram API call.
+func (h *Handler) reportTelegramError(ctx context.Context, err error, operation, correlationID string) {
+	h.RecordTelegramError(err)
+	h.reporter.Report(ctx, err, reporting.Tags(
+		reporting.TagComponent, "telegram",
+		reporting.TagOperation, operation,
+		reporting.TagCorrelationID, correlationID,
+	))
 }
 
 func (h *Handler) messageFor(lang string) i18n.Messages {
@@ -419,65 +1330,58 @@ func (h *Handler) noteForResult(msg i18n.Messages, result executions.Result, tim
 		if strings.TrimSpace(result.Note) != "" {
 			return result.Note
 		}
-		if result.Output != nil {
-			return fmt.Sprintf("✅ %v", result.Output)
+		if result.Output != nil && strings.TrimSpace(result.Output.String()) != "" {
+			return shared.WithEmoji(h.theme.Success, result.Output.String())
 		}
-		return "✅ " + msg.SelectedNote
-	case executions.StatusError:
-		if value, ok := result.Output.(string); ok {
-			if strings.TrimSpace(value) == "execution timeout" {
-				if strings.TrimSpace(timeoutMessage) != "" {
-					return timeoutMessage
-				}
-				return "⏱️ " + msg.TimeoutNote
-			}
-			if strings.TrimSpace(value) != "" {
-				return "⚠️ " + value
-			}
+		return shared.WithEmoji(h.theme.Success, msg.SelectedNote)
+	case executions.StatusTimeout:
+		if strings.TrimSpace(timeoutMessage) != "" {
+			return timeoutMessage
+		}
+		return shared.WithEmoji(h.theme.Timeout, msg.TimeoutNote)
+	case executions.StatusShutdown:
+		return shared.WithEmoji(h.theme.Error, msg.ShutdownNote)
+	case executions.StatusError, executions.StatusCancelled, executions.StatusChatUnavailable:
+		if result.Output != nil && strings.TrimSpace(result.Output.String()) != "" {
+			return shared.WithEmoji(h.theme.Error, result.Output.String())
 		}
 		if strings.TrimSpace(result.Note) != "" {
 			return result.Note
 		}
-		return "⚠️ " + msg.ErrorNote
+		return shared.WithEmoji(h.theme.Error, msg.ErrorNote)
 	default:
 		return ""
 	}
 }
 
-func (h *Handler) promptKeyboard(lang, correlationID string) *telego.InlineKeyboardMarkup {
-	msg := h.messageFor(lang)
-	cancel := CallbackData(ActionCancelCustom, correlationID)
-	return tu.InlineKeyboard(
-		tu.InlineKeyboardRow(
-			tu.InlineKeyboardButton(msg.CancelCustomButton).WithCallbackData(cancel),
-		),
-	)
-}
-
-func (h *Handler) resolvedKeyboard(lang string, messageID int) *telego.InlineKeyboardMarkup {
-	msg := h.messageFor(lang)
-	del := CallbackData(ActionDelete, strconv.Itoa(messageID))
-	return tu.InlineKeyboard(
-		tu.InlineKeyboardRow(
-			tu.InlineKeyboardButton(msg.DeleteButton).WithCallbackData(del),
-		),
-	)
+// shortenPlaceholder fits text into Telegram input field placeholder limit.
+func shortenPlaceholder(text string) string {
+	return shared.TruncateRunes(strings.TrimSpace(text), 64, "…")
 }
 
-func parseMode(markup string) string {
-	switch strings.ToLower(strings.TrimSpace(markup)) {
-	case "html":
-		return telego.ModeHTML
-	default:
-		return telego.ModeMarkdownV2
+// resolvedKeyboard replaces answer buttons of a resolved prompt: a disabled "answered by" button, when the author
+// is known, and the delete button.
+func (h *Handler) resolvedKeyboard(ctx context.Context, exec *executions.Execution) *telego.InlineKeyboardMarkup {
+	msg := h.messageFor(h.langFor(ctx, exec))
+	del := CallbackData(ActionDelete, strconv.Itoa(exec.MessageID))
+	var rows [][]telego.InlineKeyboardButton
+	if label := h.answeredLabel(ctx, exec); label != "" {
+		answered := CallbackData(ActionAnswered, exec.Request.CorrelationID)
+		rows = append(rows, tu.InlineKeyboardRow(tu.InlineKeyboardButton(label).WithCallbackData(answered)))
 	}
+	rows = append(rows, tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(msg.DeleteButton).WithCallbackData(del),
+	))
+	return tu.InlineKeyboard(rows...)
 }
 
-func renderModeText(value, mode string) string {
-	switch mode {
-	case telego.ModeHTML:
-		return shared.EscapeHTML(value)
-	default:
-		return shared.EscapeMarkdownV2(value)
+// fitNote escapes note and truncates it so that base text with the note fits Telegram message limit.
+func fitNote(base, note, mode string) string {
+	rendered := shared.Escape(mode, note)
+	budget := shared.MaxMessageLength - shared.TextLength(base) - 2
+	if shared.TextLength(rendered) <= budget {
+		return rendered
 	}
+	// Escaping may double each character, so keep half of the remaining budget.
+	return shared.Escape(mode, shared.TruncateRunes(note, budget/2, "…"))
 }
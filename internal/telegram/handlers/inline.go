@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	inputModeInline = "inline"
+	// inlineQueryKeyword is an optional first word of inline queries ("@bot pending deploy").
+	inlineQueryKeyword = "pending"
+	// maxInlineResults caps results of one inline query (Telegram allows 50).
+	maxInlineResults = 20
+	// inlineCacheTime keeps Telegram from serving prompts resolved meanwhile for long.
+	inlineCacheTime = 5
+	// maxInlineTitle and maxInlineDescription keep result previews short.
+	maxInlineTitle       = 64
+	maxInlineDescription = 200
+)
+
+// handleInlineQuery answers "@bot pending deploy" typed in any chat with pending prompts matching all words by
+// tool, question, correlation id or label. Only prompts of chats the user is a member of and may answer are
+// listed; the posted result carries quick-answer option buttons.
+func (h *Handler) handleInlineQuery(ctx context.Context, query *telego.InlineQuery) {
+	terms := inlineTerms(query.Query)
+	members := make(map[int64]bool)
+	summaries := h.registry.List(nil)
+	results := make([]telego.InlineQueryResult, 0, min(len(summaries), maxInlineResults))
+	// Newest prompts first.
+	for idx := len(summaries) - 1; idx >= 0 && len(results) < maxInlineResults; idx-- {
+		exec := h.registry.Get(summaries[idx].CorrelationID)
+		if exec == nil || exec.MessageID == 0 || !matchInlineTerms(exec, terms) || !canAnswer(exec, &query.From) {
+			continue
+		}
+		if !h.isChatMember(ctx, members, exec.Request.ChatID, query.From.ID) {
+			continue
+		}
+		results = append(results, h.inlineResult(strconv.Itoa(len(results)), exec))
+	}
+	err := h.bot.API().AnswerInlineQuery(ctx, &telego.AnswerInlineQueryParams{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+		IsPersonal:    true,
+	})
+	if err != nil {
+		h.log.ErrorContext(ctx, "Failed to answer inline query", "error", err)
+	}
+}
+
+// inlineResult posts the prompt text with option buttons answering the execution from any chat.
+func (h *Handler) inlineResult(id string, exec *executions.Execution) telego.InlineQueryResult {
+	req := exec.Request
+	content := &telego.InputTextMessageContent{
+		MessageText: exec.MessageText,
+		ParseMode:   shared.ParseMode(req.Markup),
+		Entities:    exec.MessageEntities,
+	}
+	result := tu.ResultArticle(id, shared.TruncateRunes(req.Tool.Name+": "+req.Question, maxInlineTitle, "…"), content).
+		WithDescription(shared.TruncateRunes(req.Question, maxInlineDescription, "…"))
+	if h.keyboards != nil {
+		if keyboard := h.keyboards.QuickAnswerKeyboard(exec); keyboard != nil && len(keyboard.InlineKeyboard) > 0 {
+			result = result.WithReplyMarkup(keyboard)
+		}
+	}
+	return result
+}
+
+// handleInlineCallback resolves an execution with an option button of a message posted from inline results. The
+// message may live in any chat, so the user must be a member of the prompt chat as well as allowed to answer.
+func (h *Handler) handleInlineCallback(ctx context.Context, query *telego.CallbackQuery) {
+	action, payload := parseCallback(query.Data)
+	correlationID, optionIndex, err := parseOptionPayload(payload)
+	if action != ActionOption || err != nil {
+		_ = h.answerCallback(ctx, query, h.messagesFor(ctx, nil).InvalidAction)
+		return
+	}
+	exec := h.registry.Get(correlationID)
+	if exec == nil {
+		note := h.alreadyResolvedNote(ctx, correlationID)
+		_ = h.answerCallback(ctx, query, note)
+		h.closeInlineMessage(ctx, query.InlineMessageID, note)
+		return
+	}
+	ctx = WithChat(WithExecution(ctx, exec), exec.Request.ChatID)
+	if !h.isChatMember(ctx, nil, exec.Request.ChatID, query.From.ID) {
+		_ = h.answerCallback(ctx, query, h.messagesFor(ctx, exec).InvalidChat)
+		return
+	}
+	if !canAnswer(exec, &query.From) {
+		_ = h.answerCallback(ctx, query, h.assigneeOnlyNote(ctx, exec))
+		return
+	}
+	if optionIndex < 0 || optionIndex >= len(exec.Request.Options) {
+		_ = h.answerCallback(ctx, query, h.messagesFor(ctx, exec).InvalidAction)
+		return
+	}
+	note, ok := h.selectOption(ctx, correlationID, optionIndex, inputModeInline)
+	if !ok {
+		note = h.alreadyResolvedNote(ctx, correlationID)
+	}
+	_ = h.answerCallback(ctx, query, note)
+	h.closeInlineMessage(ctx, query.InlineMessageID, note)
+}
+
+// closeInlineMessage replaces a message posted from inline results with the resolution note, dropping its buttons.
+func (h *Handler) closeInlineMessage(ctx context.Context, inlineMessageID, note string) {
+	_, err := h.bot.API().EditMessageText(ctx, &telego.EditMessageTextParams{
+		InlineMessageID: inlineMessageID,
+		Text:            note,
+	})
+	if err != nil {
+		h.log.WarnContext(ctx, "Failed to update inline message", "error", err)
+	}
+}
+
+// isChatMember reports whether the user is a member of the chat; members caches answers within one update.
+func (h *Handler) isChatMember(ctx context.Context, members map[int64]bool, chatID, userID int64) bool {
+	if member, ok := members[chatID]; ok {
+		return member
+	}
+	member, err := h.bot.API().GetChatMember(ctx, &telego.GetChatMemberParams{ChatID: tu.ID(chatID), UserID: userID})
+	isMember := err == nil && member.MemberIsMember()
+	if err != nil {
+		h.log.WarnContext(ctx, "Failed to check chat membership", "error", err, "chat_id", chatID)
+	}
+	if members != nil {
+		members[chatID] = isMember
+	}
+	return isMember
+}
+
+// inlineTerms splits an inline query into lowercase search words, dropping the optional "pending" keyword.
+func inlineTerms(query string) []string {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) > 0 && terms[0] == inlineQueryKeyword {
+		terms = terms[1:]
+	}
+	return terms
+}
+
+// matchInlineTerms reports whether every term occurs in the tool, question, correlation id or labels.
+func matchInlineTerms(exec *executions.Execution, terms []string) bool {
+	req := exec.Request
+	fields := []string{req.Tool.Name, req.Question, req.CorrelationID}
+	for key, value := range req.Labels {
+		fields = append(fields, key+"="+value)
+	}
+	haystack := strings.ToLower(strings.Join(fields, "\n"))
+	for _, term := range terms {
+		if !strings.Contains(haystack, term) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/mymmrac/telego"
+)
+
+type userLangKey struct{}
+
+// withUserLang stores Telegram language of the update sender in context.
+func withUserLang(ctx context.Context, user *telego.User) context.Context {
+	if user == nil || user.LanguageCode == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, userLangKey{}, user.LanguageCode)
+}
+
+// updateSender returns user who triggered the update.
+func updateSender(update telego.Update) *telego.User {
+	switch {
+	case update.CallbackQuery != nil:
+		return &update.CallbackQuery.From
+	case update.Message != nil:
+		return update.Message.From
+	case update.EditedMessage != nil:
+		return update.EditedMessage.From
+	case update.PollAnswer != nil:
+		return update.PollAnswer.User
+	case update.MessageReaction != nil:
+		return update.MessageReaction.User
+	case update.InlineQuery != nil:
+		return &update.InlineQuery.From
+	default:
+		return nil
+	}
+}
+
+// langFor picks interaction language: request lang when set explicitly, then chat preference (/lang),
+// then sender's Telegram language if a bundle exists for it, otherwise request (service) lang.
+func (h *Handler) langFor(ctx context.Context, exec *executions.Execution) string {
+	if exec != nil && !exec.Request.LangAuto {
+		return exec.Request.Lang
+	}
+	if lang := h.chatLang(ctx); lang != "" {
+		return lang
+	}
+	if code, ok := ctx.Value(userLangKey{}).(string); ok {
+		code, _, _ = strings.Cut(strings.ToLower(code), "-")
+		if _, ok := h.messages[code]; ok {
+			return code
+		}
+	}
+	if exec != nil {
+		return exec.Request.Lang
+	}
+	return ""
+}
+
+// messagesFor returns messages in interaction language with spec.strings overrides of the execution.
+func (h *Handler) messagesFor(ctx context.Context, exec *executions.Execution) i18n.Messages {
+	msg := h.messageFor(h.langFor(ctx, exec))
+	if exec != nil {
+		msg = msg.WithOverrides(exec.Request.Strings)
+	}
+	return msg
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+)
+
+// lifecycle bounds work that outlives the update or request starting it: expiring executions, callbacks and held
+// answers. Shutdown stops new work, lets running work finish until its deadline and then cancels what is left.
+type lifecycle struct {
+	// stopping is canceled when shutdown begins; timers waiting on it exit without firing.
+	stopping context.Context
+	stop     context.CancelFunc
+	// ctx is canceled when shutdown gives up waiting for running work.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	stopped bool
+	running sync.WaitGroup
+}
+
+func newLifecycle() *lifecycle {
+	l := &lifecycle{}
+	l.stopping, l.stop = context.WithCancel(context.Background())
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+	return l
+}
+
+// Stopping returns a context canceled once shutdown begins; background timers exit when it is done.
+func (h *Handler) Stopping() context.Context {
+	return h.lifecycle.stopping
+}
+
+// Go runs fn in the background with the values of ctx and a context canceled when shutdown gives up on running
+// work. It reports false and does not run fn once shutdown began.
+func (h *Handler) Go(ctx context.Context, fn func(ctx context.Context)) bool {
+	l := h.lifecycle
+	l.mu.Lock()
+	if l.stopped {
+		l.mu.Unlock()
+		return false
+	}
+	l.running.Add(1)
+	l.mu.Unlock()
+	go func() {
+		defer l.running.Done()
+		ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		defer context.AfterFunc(l.ctx, cancel)()
+		defer cancel()
+		fn(ctx)
+	}()
+	return true
+}
+
+// Shutdown stops background timers and work started with Go, waits for running work until ctx is done and then
+// cancels it.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	l := h.lifecycle
+	l.mu.Lock()
+	l.stopped = true
+	l.mu.Unlock()
+	l.stop()
+	done := make(chan struct{})
+	go func() {
+		l.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		l.cancel()
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
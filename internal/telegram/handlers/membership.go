@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/codex-k8s/telegram-executor/internal/events"
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/mymmrac/telego"
+)
+
+// handleMyChatMember tracks whether the bot can still post to an allowed chat. Losing access
+// fails pending executions of the chat at once instead of letting them hang until timeout.
+func (h *Handler) handleMyChatMember(ctx context.Context, update *telego.ChatMemberUpdated) {
+	chatID := update.Chat.ID
+	if !h.allowedChat(chatID) {
+		return
+	}
+	reason := chatLossReason(update.NewChatMember)
+	h.chatsMu.Lock()
+	_, wasLost := h.lostChats[chatID]
+	if reason == "" {
+		delete(h.lostChats, chatID)
+	} else {
+		h.lostChats[chatID] = reason
+	}
+	h.chatsMu.Unlock()
+
+	if reason == "" {
+		if wasLost {
+			h.log.InfoContext(ctx, "Bot access to chat restored", "chat_id", chatID, "status", update.NewChatMember.MemberStatus())
+		}
+		return
+	}
+	failed := 0
+	for _, correlationID := range h.registry.InChat(chatID) {
+		if h.failUnavailable(ctx, correlationID, reason) {
+			failed++
+		}
+	}
+	h.log.ErrorContext(ctx, "Bot lost access to chat",
+		"chat_id", chatID,
+		"status", update.NewChatMember.MemberStatus(),
+		"reason", reason,
+		"failed", failed,
+	)
+}
+
+// chatLossReason explains why the bot cannot post with the membership, or returns empty string.
+func chatLossReason(member telego.ChatMember) string {
+	switch m := member.(type) {
+	case *telego.ChatMemberLeft:
+		return "bot left the chat"
+	case *telego.ChatMemberBanned:
+		return "bot was removed from the chat"
+	case *telego.ChatMemberRestricted:
+		if !m.IsMember {
+			return "bot left the chat"
+		}
+		if !m.CanSendMessages {
+			return "bot is not allowed to send messages"
+		}
+	}
+	return ""
+}
+
+// failUnavailable reports execution as failed without touching its messages, which the bot can no longer edit.
+func (h *Handler) failUnavailable(ctx context.Context, correlationID, reason string) bool {
+	exec, _, ok := h.registry.Resolve(correlationID)
+	if !ok {
+		return false
+	}
+	h.queue.cancel(correlationID)
+	failed := events.New(events.TypeChatUnavailable, correlationID, exec.Request.Tool.Name, exec.CreatedAt)
+	failed.MessageID = exec.MessageID
+	h.bus.Emit(failed)
+	h.sendWebhook(WithExecution(ctx, exec), exec, executions.Result{
+		Status: executions.StatusChatUnavailable,
+		Output: executions.ChatUnavailable{Reason: reason},
+	})
+	return true
+}
+
+// ChatError returns an error when the bot lost access to the chat.
+func (h *Handler) ChatError(chatID int64) error {
+	h.chatsMu.Lock()
+	defer h.chatsMu.Unlock()
+	if reason, ok := h.lostChats[chatID]; ok {
+		return fmt.Errorf("chat %d unavailable: %s", chatID, reason)
+	}
+	return nil
+}
+
+// CheckChats returns an error listing chats the bot lost access to.
+func (h *Handler) CheckChats() error {
+	h.chatsMu.Lock()
+	defer h.chatsMu.Unlock()
+	chatIDs := make([]int64, 0, len(h.lostChats))
+	for chatID := range h.lostChats {
+		chatIDs = append(chatIDs, chatID)
+	}
+	sort.Slice(chatIDs, func(i, j int) bool { return chatIDs[i] < chatIDs[j] })
+	errs := make([]error, 0, len(chatIDs))
+	for _, chatID := range chatIDs {
+		errs = append(errs, fmt.Errorf("chat %d unavailable: %s", chatID, h.lostChats[chatID]))
+	}
+	return errors.Join(errs...)
+}
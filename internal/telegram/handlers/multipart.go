@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// doneCommand submits multi-message answer.
+const doneCommand = "/done"
+
+// collectAnswerPart appends message to multi-message answer and updates the status message.
+func (h *Handler) collectAnswerPart(ctx context.Context, exec *executions.Execution, messageID int, text, inputMode string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	correlationID := exec.Request.CorrelationID
+	if inputMode == inputModeText && strings.EqualFold(text, doneCommand) {
+		h.submitAnswer(ctx, correlationID)
+		return
+	}
+	count, statusID, ok := h.registry.AppendAnswerPart(correlationID, messageID, text)
+	if !ok {
+		return
+	}
+	msg := h.messagesFor(ctx, exec)
+	status := msg.Format(msg.AnswerPartsStatus, i18n.Vars{"count": count})
+	keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(msg.SubmitAnswerButton).WithCallbackData(CallbackData(ActionSubmit, correlationID)),
+	))
+	if statusID > 0 {
+		_, err := h.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+			ChatID:      tu.ID(h.currentChat(ctx)),
+			MessageID:   statusID,
+			Text:        status,
+			ReplyMarkup: keyboard,
+		})
+		if err != nil {
+			h.log.ErrorContext(ctx, "Failed to update answer status message", "error", err, "correlation_id", correlationID)
+		}
+		return
+	}
+	sent, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID: tu.ID(h.currentChat(ctx)),
+		Text:   status,
+		ReplyParameters: (&telego.ReplyParameters{
+			MessageID: exec.MessageID,
+		}).WithAllowSendingWithoutReply(),
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		h.log.ErrorContext(ctx, "Failed to send answer status message", "error", err, "correlation_id", correlationID)
+		h.reportTelegramError(ctx, err, "send_answer_status", correlationID)
+		return
+	}
+	h.registry.SetAnswerStatusMessage(correlationID, sent.MessageID)
+}
+
+// submitAnswer joins collected parts into a single custom answer.
+func (h *Handler) submitAnswer(ctx context.Context, correlationID string) bool {
+	parts, statusID := h.registry.AnswerParts(correlationID)
+	if len(parts) == 0 {
+		return false
+	}
+	_ = h.DeleteMessage(ctx, statusID)
+	h.resolveCustom(ctx, correlationID, strings.Join(parts, "\n\n"), inputModeText)
+	return true
+}
+
+func (h *Handler) submitAnswerCallback(ctx context.Context, query *telego.CallbackQuery, correlationID string) {
+	if !h.submitAnswer(ctx, correlationID) {
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
+		return
+	}
+	_ = h.answerCallback(ctx, query, "")
+}
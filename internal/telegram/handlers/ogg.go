@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// opusHead describes identification header of an Ogg/Opus stream (RFC 7845).
+type opusHead struct {
+	Channels   int
+	SampleRate uint32
+}
+
+var (
+	oggCapturePattern = []byte("OggS")
+	opusHeadMagic     = []byte("OpusHead")
+	errNotOggOpus     = errors.New("not an ogg/opus stream")
+)
+
+const oggPageHeaderSize = 27
+
+// parseOpusHead validates the first Ogg page and reads OpusHead packet from it.
+func parseOpusHead(content []byte) (opusHead, error) {
+	if len(content) < oggPageHeaderSize || !bytes.Equal(content[:4], oggCapturePattern) || content[4] != 0 {
+		return opusHead{}, errNotOggOpus
+	}
+	segments := int(content[26])
+	if len(content) < oggPageHeaderSize+segments {
+		return opusHead{}, errNotOggOpus
+	}
+	// OpusHead must be the only packet of the first page.
+	packetSize := 0
+	for _, lacing := range content[oggPageHeaderSize : oggPageHeaderSize+segments] {
+		packetSize += int(lacing)
+		if lacing < 255 {
+			break
+		}
+	}
+	start := oggPageHeaderSize + segments
+	if packetSize < 19 || len(content) < start+packetSize {
+		return opusHead{}, errNotOggOpus
+	}
+	packet := content[start : start+packetSize]
+	if !bytes.Equal(packet[:8], opusHeadMagic) || packet[8]>>4 != 0 {
+		return opusHead{}, errNotOggOpus
+	}
+	return opusHead{
+		Channels:   int(packet[9]),
+		SampleRate: binary.LittleEndian.Uint32(packet[12:16]),
+	}, nil
+}
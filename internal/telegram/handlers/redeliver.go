@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+var (
+	// ErrNotResolved is returned when a callback is redelivered for an execution that is still pending.
+	ErrNotResolved = errors.New("execution is not resolved yet")
+	// ErrNoCallback is returned when the resolved execution had no callback URL.
+	ErrNoCallback = errors.New("execution has no callback")
+)
+
+// Redeliver re-sends the retained callback payload of a resolved execution, e.g. after upstream was down.
+func (h *Handler) Redeliver(ctx context.Context, correlationID string) error {
+	record, ok := h.registry.Status(correlationID)
+	switch {
+	case !ok:
+		return ErrExecutionNotFound
+	case record.Status == executions.StatusPending:
+		return ErrNotResolved
+	case record.CallbackBody == nil:
+		return ErrNoCallback
+	}
+	return h.deliverCallback(ctx, correlationID, record)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/mymmrac/telego"
+)
+
+const inputModeReply = "reply"
+
+// handleReplyShortcut selects an option when the message replies to a prompt with an option number, hotkey or
+// option text, whether or not custom input was started. Other replies are left to custom answer handling.
+func (h *Handler) handleReplyShortcut(ctx context.Context, message *telego.Message) bool {
+	if message.ReplyToMessage == nil || message.Text == "" {
+		return false
+	}
+	exec := h.registry.FindByMessage(h.currentChat(ctx), message.ReplyToMessage.MessageID)
+	if exec == nil || len(exec.Request.Options) == 0 {
+		return false
+	}
+	index, ok := matchReplyShortcut(message.Text, exec.Request)
+	if !ok {
+		return false
+	}
+	ctx = WithExecution(ctx, exec)
+	if !canAnswer(exec, message.From) {
+		_ = h.reply(ctx, h.assigneeOnlyNote(ctx, exec))
+		return true
+	}
+	h.selectOption(ctx, exec.Request.CorrelationID, index, inputModeReply)
+	return true
+}
+
+// matchReplyShortcut maps a bare option number ("2") or anything matchOption accepts to option index.
+func matchReplyShortcut(text string, req executions.Request) (int, bool) {
+	if number, err := strconv.Atoi(strings.TrimSpace(text)); err == nil {
+		if number < 1 || number > len(req.Options) {
+			return 0, false
+		}
+		return number - 1, true
+	}
+	return matchOption(text, req)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// isPromptAction reports whether the callback comes from buttons of a pending prompt, which become stale once
+// the execution is gone (restart without persistence, timeout already fired).
+func isPromptAction(action string) bool {
+	if _, ok := answerActions[action]; ok {
+		return true
+	}
+	return action == ActionDetails
+}
+
+// healStaleKeyboard answers a callback of an execution that no longer exists and turns its message into a terminal
+// state: the "already processed" note is appended and the keyboard removed, so it stops attracting clicks.
+func (h *Handler) healStaleKeyboard(ctx context.Context, query *telego.CallbackQuery, correlationID string) {
+	note := h.alreadyResolvedNote(ctx, correlationID)
+	_ = h.answerCallback(ctx, query, note)
+	message := query.Message.Message()
+	if message == nil {
+		return
+	}
+	var err error
+	text := fmt.Sprintf("%s\n\n%s", message.Text, note)
+	if message.Text != "" && shared.FitsMessage(text) {
+		// Entities keep formatting of the original text; the note is appended after them.
+		_, err = h.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+			ChatID:    tu.ID(message.Chat.ID),
+			MessageID: message.MessageID,
+			Text:      text,
+			Entities:  message.Entities,
+		})
+	} else {
+		_, err = h.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+			ChatID:    tu.ID(message.Chat.ID),
+			MessageID: message.MessageID,
+		})
+	}
+	if err != nil && !messageNotModified(err) {
+		h.log.WarnContext(ctx, "Failed to clear stale keyboard", "error", err, "message_id", message.MessageID)
+	}
+}
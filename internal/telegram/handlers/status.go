@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// statusCommand reports operational status of the service.
+const statusCommand = "/status"
+
+// operationalStatus collects data shown by /status that is not available from the registry.
+type operationalStatus struct {
+	startedAt  time.Time
+	updateMode string
+
+	mu          sync.Mutex
+	telegramErr string
+	telegramAt  time.Time
+	undelivered map[string]struct{}
+}
+
+func newOperationalStatus() *operationalStatus {
+	return &operationalStatus{startedAt: time.Now(), undelivered: make(map[string]struct{})}
+}
+
+// SetUpdateMode sets how updates are received ("webhook" or "long polling"), shown by /status.
+func (h *Handler) SetUpdateMode(mode string) {
+	h.status.updateMode = mode
+}
+
+// RecordTelegramError remembers the last failed Telegram API call for /status.
+func (h *Handler) RecordTelegramError(err error) {
+	if err == nil {
+		return
+	}
+	h.status.mu.Lock()
+	defer h.status.mu.Unlock()
+	h.status.telegramErr = err.Error()
+	h.status.telegramAt = time.Now()
+}
+
+// trackCallback marks callback of correlationID as pending until it is delivered.
+func (h *Handler) trackCallback(correlationID string, delivered bool) {
+	h.status.mu.Lock()
+	defer h.status.mu.Unlock()
+	if delivered {
+		delete(h.status.undelivered, correlationID)
+		return
+	}
+	h.status.undelivered[correlationID] = struct{}{}
+}
+
+// callbackBacklog counts callbacks in flight or failed and still retained for redelivery.
+func (h *Handler) callbackBacklog() int {
+	h.status.mu.Lock()
+	defer h.status.mu.Unlock()
+	for correlationID := range h.status.undelivered {
+		if _, ok := h.registry.Status(correlationID); !ok {
+			delete(h.status.undelivered, correlationID)
+		}
+	}
+	return len(h.status.undelivered)
+}
+
+// handleStatusCommand replies with uptime, update mode, pending count, last Telegram API error,
+// voice transcription availability and callback delivery backlog.
+func (h *Handler) handleStatusCommand(ctx context.Context) {
+	msg := h.messageFor(h.currentLang(ctx))
+	h.status.mu.Lock()
+	telegramErr, telegramAt := h.status.telegramErr, h.status.telegramAt
+	h.status.mu.Unlock()
+	lastError := msg.StatusNone
+	if telegramErr != "" {
+		lastError = msg.Format(msg.StatusError, i18n.Vars{
+			"error": telegramErr,
+			"ago":   time.Since(telegramAt).Round(time.Second).String(),
+		})
+	}
+	stt := msg.StatusSTTDisabled
+	if h.transcriber != nil {
+		stt = msg.StatusSTTAvailable
+	}
+	text := msg.Format(msg.StatusReport, i18n.Vars{
+		"uptime":         time.Since(h.status.startedAt).Round(time.Second).String(),
+		"mode":           h.status.updateMode,
+		"pending":        h.registry.Stats().Pending,
+		"telegram_error": lastError,
+		"stt":            stt,
+		"callbacks":      h.callbackBacklog(),
+	})
+	// Plain text: the last error may contain characters that break Markdown.
+	if _, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID: tu.ID(h.currentChat(ctx)),
+		Text:   text,
+	}); err != nil {
+		h.log.WarnContext(ctx, "Failed to send status", "error", err)
+	}
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/reporting"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// transcriptionJob is a voice message or audio file waiting for transcription.
+type transcriptionJob struct {
+	ctx           context.Context
+	cancel        context.CancelFunc
+	exec          *executions.Execution
+	audio         *audioInput
+	language      string
+	messageID     int
+	multiMessage  bool
+	placeholderID int
+}
+
+// transcriptionQueue is a bounded queue of transcription jobs served by a fixed number of workers.
+type transcriptionQueue struct {
+	jobs    chan *transcriptionJob
+	workers int
+
+	mu      sync.Mutex
+	pending map[string]map[*transcriptionJob]struct{}
+}
+
+func newTranscriptionQueue(workers, size int) *transcriptionQueue {
+	return &transcriptionQueue{
+		jobs:    make(chan *transcriptionJob, max(size, 0)),
+		workers: max(workers, 1),
+		pending: make(map[string]map[*transcriptionJob]struct{}),
+	}
+}
+
+// push registers job and queues it; it returns false when the queue is full.
+func (q *transcriptionQueue) push(job *transcriptionJob) bool {
+	correlationID := job.exec.Request.CorrelationID
+	q.mu.Lock()
+	if q.pending[correlationID] == nil {
+		q.pending[correlationID] = make(map[*transcriptionJob]struct{})
+	}
+	q.pending[correlationID][job] = struct{}{}
+	q.mu.Unlock()
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		q.done(job)
+		return false
+	}
+}
+
+// done unregisters finished job.
+func (q *transcriptionQueue) done(job *transcriptionJob) {
+	job.cancel()
+	correlationID := job.exec.Request.CorrelationID
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.pending[correlationID], job)
+	if len(q.pending[correlationID]) == 0 {
+		delete(q.pending, correlationID)
+	}
+}
+
+// cancel aborts queued and running jobs of execution resolved in another way.
+func (q *transcriptionQueue) cancel(correlationID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for job := range q.pending[correlationID] {
+		job.cancel()
+	}
+}
+
+func (h *Handler) startTranscriptionWorkers(ctx context.Context) {
+	for range h.queue.workers {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-h.queue.jobs:
+					h.processTranscriptionSafe(job)
+				}
+			}
+		}()
+	}
+}
+
+// enqueueTranscription shows "transcribing" placeholder and queues voice answer.
+func (h *Handler) enqueueTranscription(ctx context.Context, exec *executions.Execution, message *telego.Message, audio *audioInput, multiMessage bool) {
+	msg := h.messagesFor(ctx, exec)
+	if h.transcriber == nil {
+		_ = h.reply(ctx, msg.VoiceDisabled)
+		return
+	}
+	jobCtx, cancel := context.WithCancel(ctx)
+	job := &transcriptionJob{
+		ctx:          jobCtx,
+		cancel:       cancel,
+		exec:         exec,
+		audio:        audio,
+		language:     h.transcriptionLanguage(exec, message.From),
+		messageID:    message.MessageID,
+		multiMessage: multiMessage,
+	}
+	placeholder, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID: tu.ID(h.currentChat(ctx)),
+		Text:   msg.VoiceTranscribing,
+		ReplyParameters: (&telego.ReplyParameters{
+			MessageID: message.MessageID,
+		}).WithAllowSendingWithoutReply(),
+	})
+	if err != nil {
+		h.log.WarnContext(ctx, "Failed to send transcription placeholder", "error", err, "correlation_id", exec.Request.CorrelationID)
+	} else {
+		job.placeholderID = placeholder.MessageID
+	}
+	if !h.queue.push(job) {
+		h.log.WarnContext(ctx, "Transcription queue is full", "correlation_id", exec.Request.CorrelationID)
+		h.deletePlaceholder(job)
+		_ = h.reply(ctx, msg.VoiceQueueFull)
+	}
+}
+
+func (h *Handler) processTranscriptionSafe(job *transcriptionJob) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err := reporting.PanicError(recovered)
+			h.log.ErrorContext(job.ctx, "Panic while transcribing audio", "error", err, "correlation_id", job.exec.Request.CorrelationID)
+			h.reporter.Report(context.WithoutCancel(job.ctx), err, reporting.Tags(reporting.TagComponent, "telegram", reporting.TagOperation, "transcribe"))
+			h.deletePlaceholder(job)
+		}
+	}()
+	h.processTranscription(job)
+}
+
+func (h *Handler) processTranscription(job *transcriptionJob) {
+	defer h.queue.done(job)
+	exec := job.exec
+	correlationID := exec.Request.CorrelationID
+	if job.ctx.Err() != nil || h.registry.Get(correlationID) == nil {
+		h.deletePlaceholder(job)
+		return
+	}
+	answer, err := h.transcribeAudio(job.ctx, job.audio, job.language, transcriptionHints(exec))
+	h.deletePlaceholder(job)
+	if job.ctx.Err() != nil {
+		// Execution was resolved while transcribing.
+		return
+	}
+	ctx := job.ctx
+	msg := h.messagesFor(ctx, exec)
+	if err != nil {
+		switch {
+		case errors.Is(err, errTranscriberDisabled):
+			_ = h.reply(ctx, msg.VoiceDisabled)
+		case errors.Is(err, errAudioTooLong):
+			_ = h.reply(ctx, msg.Format(msg.VoiceTooLong, i18n.Vars{
+				"max_duration": formatDuration(h.voiceLimits.MaxDuration),
+				"max_mb":       h.voiceLimits.MaxSize / (1024 * 1024),
+			}))
+		default:
+			h.log.ErrorContext(ctx, "Failed to transcribe audio", "error", err, "correlation_id", correlationID)
+			_ = h.reply(ctx, msg.TranscriptionFailed)
+		}
+		return
+	}
+	if job.multiMessage {
+		h.collectAnswerPart(ctx, exec, job.messageID, answer, job.audio.InputMode)
+		return
+	}
+	if h.voiceConfirm && strings.TrimSpace(answer) != "" {
+		h.confirmTranscription(ctx, exec, strings.TrimSpace(answer), job.audio.InputMode)
+		return
+	}
+	h.resolveCustom(ctx, correlationID, answer, job.audio.InputMode)
+}
+
+// deletePlaceholder removes "transcribing" message even if job context is canceled.
+func (h *Handler) deletePlaceholder(job *transcriptionJob) {
+	if job.placeholderID == 0 {
+		return
+	}
+	_ = h.DeleteMessage(context.WithoutCancel(job.ctx), job.placeholderID)
+	job.placeholderID = 0
+}
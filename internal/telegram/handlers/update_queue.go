@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+// updateQueue runs updates of each chat one by one in arrival order while different chats are handled
+// concurrently, so a chat waiting on its outbound rate limit does not hold up the others. Updates without a chat
+// (poll answers, inline queries) share one queue.
+type updateQueue struct {
+	mu      sync.Mutex
+	chats   map[int64]*chatUpdates
+	running sync.WaitGroup
+}
+
+// chatUpdates holds updates of a chat waiting for its worker; started is set while the worker handles one.
+type chatUpdates struct {
+	updates []telego.Update
+	started time.Time
+}
+
+func newUpdateQueue() *updateQueue {
+	return &updateQueue{chats: make(map[int64]*chatUpdates)}
+}
+
+// dispatch queues update for its chat and starts the chat worker when it is idle.
+func (h *Handler) dispatch(ctx context.Context, update telego.Update) {
+	q := h.updates
+	chat := updateChat(update)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c, active := q.chats[chat]
+	if !active {
+		c = &chatUpdates{}
+		q.chats[chat] = c
+		q.running.Add(1)
+		go h.drainChat(ctx, chat, c)
+	}
+	c.updates = append(c.updates, update)
+}
+
+// drainChat handles queued updates of the chat until none are left or ctx is done; at most one runs per chat.
+func (h *Handler) drainChat(ctx context.Context, chat int64, c *chatUpdates) {
+	q := h.updates
+	defer q.running.Done()
+	for {
+		q.mu.Lock()
+		if len(c.updates) == 0 || ctx.Err() != nil {
+			delete(q.chats, chat)
+			q.mu.Unlock()
+			return
+		}
+		update := c.updates[0]
+		c.updates[0] = telego.Update{}
+		c.updates = c.updates[1:]
+		started := time.Now()
+		c.started = started
+		q.mu.Unlock()
+
+		h.handleUpdateSafe(ctx, update)
+
+		q.mu.Lock()
+		c.started = time.Time{}
+		q.mu.Unlock()
+		h.lastProcessed.Store(time.Now().UnixNano())
+		if h.observeLatency != nil {
+			h.observeLatency(time.Since(started))
+		}
+	}
+}
+
+// busySince returns when the longest running update started, or zero time when idle.
+func (q *updateQueue) busySince() time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var oldest time.Time
+	for _, c := range q.chats {
+		if !c.started.IsZero() && (oldest.IsZero() || c.started.Before(oldest)) {
+			oldest = c.started
+		}
+	}
+	return oldest
+}
+
+// backlog returns the number of updates waiting for their chat worker.
+func (q *updateQueue) backlog() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	waiting := 0
+	for _, c := range q.chats {
+		waiting += len(c.updates)
+	}
+	return waiting
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// confirmTranscription shows recognized voice answer with Use/Re-record/Edit buttons instead of resolving immediately.
+func (h *Handler) confirmTranscription(ctx context.Context, exec *executions.Execution, text, inputMode string) {
+	correlationID := exec.Request.CorrelationID
+	msg := h.messagesFor(ctx, exec)
+	confirmation, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID: tu.ID(h.currentChat(ctx)),
+		Text:   msg.VoiceTranscription + "\n\n" + text,
+		ReplyParameters: (&telego.ReplyParameters{
+			MessageID: exec.MessageID,
+		}).WithAllowSendingWithoutReply(),
+		ReplyMarkup: tu.InlineKeyboard(
+			tu.InlineKeyboardRow(
+				tu.InlineKeyboardButton(msg.VoiceUseButton).WithCallbackData(CallbackData(ActionVoiceUse, correlationID)),
+			),
+			tu.InlineKeyboardRow(
+				tu.InlineKeyboardButton(msg.VoiceRetryButton).WithCallbackData(CallbackData(ActionVoiceRetry, correlationID)),
+				tu.InlineKeyboardButton(msg.VoiceEditButton).WithCallbackData(CallbackData(ActionVoiceEdit, correlationID)),
+			),
+		),
+	})
+	if err != nil {
+		h.log.ErrorContext(ctx, "Failed to send transcription confirmation", "error", err, "correlation_id", correlationID)
+		h.reportTelegramError(ctx, err, "send_transcription", correlationID)
+		h.resolveCustom(ctx, correlationID, text, inputMode)
+		return
+	}
+	previous, ok := h.registry.SetTranscription(correlationID, text, inputMode, confirmation.MessageID)
+	if !ok {
+		_ = h.DeleteMessage(ctx, confirmation.MessageID)
+		return
+	}
+	_ = h.DeleteMessage(ctx, previous)
+}
+
+func (h *Handler) useTranscription(ctx context.Context, query *telego.CallbackQuery, correlationID string) {
+	transcription, ok := h.registry.TakeTranscription(correlationID)
+	if !ok {
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
+		return
+	}
+	_ = h.DeleteMessage(ctx, transcription.MessageID)
+	h.resolveCustom(ctx, correlationID, transcription.Text, transcription.InputMode)
+	_ = h.answerCallback(ctx, query, "")
+}
+
+func (h *Handler) retryTranscription(ctx context.Context, query *telego.CallbackQuery, correlationID string) {
+	transcription, ok := h.registry.TakeTranscription(correlationID)
+	if !ok {
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
+		return
+	}
+	_ = h.DeleteMessage(ctx, transcription.MessageID)
+	exec := h.registry.Get(correlationID)
+	_ = h.answerCallback(ctx, query, h.messagesFor(ctx, exec).VoiceRetryHint)
+}
+
+// editTranscription sends recognized text as tap-to-copy code with ForceReply so the user can send a corrected answer.
+func (h *Handler) editTranscription(ctx context.Context, query *telego.CallbackQuery, correlationID string) {
+	transcription, ok := h.registry.TakeTranscription(correlationID)
+	if !ok {
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
+		return
+	}
+	_ = h.DeleteMessage(ctx, transcription.MessageID)
+	exec := h.registry.Get(correlationID)
+	if exec == nil {
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
+		return
+	}
+	prevPromptID, ok := h.registry.StartCustomInput(correlationID, "")
+	if !ok {
+		_ = h.answerCallback(ctx, query, h.alreadyResolvedNote(ctx, correlationID))
+		return
+	}
+	_ = h.DeleteMessage(ctx, prevPromptID)
+	msg := h.messagesFor(ctx, exec)
+	prefix := msg.VoiceEditPrompt + "\n\n"
+	prompt, err := h.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID: tu.ID(h.currentChat(ctx)),
+		Text:   prefix + transcription.Text,
+		Entities: []telego.MessageEntity{{
+			Type:   telego.EntityTypeCode,
+			Offset: shared.TextLength(prefix),
+			Length: shared.TextLength(transcription.Text),
+		}},
+		ReplyParameters: (&telego.ReplyParameters{
+			MessageID: exec.MessageID,
+		}).WithAllowSendingWithoutReply(),
+		ReplyMarkup: tu.ForceReply().WithSelective().WithInputFieldPlaceholder(shortenPlaceholder(msg.VoiceEditPrompt)),
+	})
+	if err != nil {
+		h.log.ErrorContext(ctx, "Failed to send transcription edit prompt", "error", err, "correlation_id", correlationID)
+		h.reportTelegramError(ctx, err, "send_transcription_edit", correlationID)
+		h.registry.ClearPrompt(correlationID)
+		_ = h.answerCallback(ctx, query, msg.ErrorNote)
+		return
+	}
+	h.registry.SetPromptMessage(correlationID, prompt.MessageID)
+	_ = h.answerCallback(ctx, query, "")
+}
@@ -3,27 +3,44 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 const (
 	ffmpegSampleRate = "16000"
 	ffmpegChannels   = "1"
 	ffmpegFormat     = "mp3"
+	ffmpegFormatWAV  = "wav"
 )
 
-func normalizeVoiceAudio(ctx context.Context, content []byte, mimeType, filename string) ([]byte, string, string, error) {
+// AudioFormatter is implemented by transcribers that require a specific audio container (e.g. "wav").
+type AudioFormatter interface {
+	AudioFormat() string
+}
+
+func normalizeVoiceAudio(ctx context.Context, content []byte, mimeType, filename, format string) ([]byte, string, string, error) {
 	if len(content) == 0 {
 		return nil, "", "", fmt.Errorf("empty audio content")
 	}
+	if format != ffmpegFormatWAV {
+		format = ffmpegFormat
+	}
 
 	lowerMime := strings.ToLower(strings.TrimSpace(mimeType))
-	if isOpenAICompatibleAudio(lowerMime, filename) {
+	if format == ffmpegFormatWAV && isWAVAudio(lowerMime, filename) {
+		return content, mimeType, filename, nil
+	}
+	if format == ffmpegFormat && isOpenAICompatibleAudio(lowerMime, filename) {
 		return content, mimeType, filename, nil
 	}
+	if !ffmpegAvailable() {
+		return passthroughOggOpus(content, filename, format)
+	}
 
 	cmd := exec.CommandContext(ctx, "ffmpeg",
 		"-nostdin",
@@ -31,7 +48,7 @@ func normalizeVoiceAudio(ctx context.Context, content []byte, mimeType, filename
 		"-i", "pipe:0",
 		"-ac", ffmpegChannels,
 		"-ar", ffmpegSampleRate,
-		"-f", ffmpegFormat,
+		"-f", format,
 		"pipe:1",
 	)
 
@@ -55,22 +72,58 @@ func normalizeVoiceAudio(ctx context.Context, content []byte, mimeType, filename
 	}
 
 	newMime := "audio/mpeg"
-	newName := normalizeFilename(filename)
+	if format == ffmpegFormatWAV {
+		newMime = "audio/wav"
+	}
+	newName := normalizeFilename(filename, "."+format)
 	return out, newMime, newName, nil
 }
 
-func normalizeFilename(filename string) string {
+// ffmpegAvailable reports whether ffmpeg binary is on PATH; the lookup is done once.
+var ffmpegAvailable = sync.OnceValue(func() bool {
+	_, err := exec.LookPath("ffmpeg")
+	return err == nil
+})
+
+// VoiceNeedsFFmpeg reports whether the transcriber requires WAV while ffmpeg is not installed, so voice notes
+// can't be transcribed: Opus is never decoded in-process.
+func VoiceNeedsFFmpeg(transcriber Transcriber) bool {
+	formatter, ok := transcriber.(AudioFormatter)
+	return ok && formatter.AudioFormat() == ffmpegFormatWAV && !ffmpegAvailable()
+}
+
+// passthroughOggOpus sends Telegram voice notes (Ogg/Opus) unchanged when ffmpeg is absent. The audio is only
+// checked, not decoded: backends that take compressed audio accept Ogg/Opus natively, WAV-only ones need ffmpeg.
+func passthroughOggOpus(content []byte, filename, format string) ([]byte, string, string, error) {
+	if _, err := parseOpusHead(content); err != nil {
+		return nil, "", "", fmt.Errorf("ffmpeg is not installed and audio is not ogg/opus: %w", err)
+	}
+	if format == ffmpegFormatWAV {
+		return nil, "", "", errors.New("ffmpeg is required to convert ogg/opus to wav for the configured stt provider")
+	}
+	return content, "audio/ogg", normalizeFilename(filename, ".ogg"), nil
+}
+
+func normalizeFilename(filename, suffix string) string {
 	if strings.TrimSpace(filename) == "" {
-		return "voice.mp3"
+		return "voice" + suffix
 	}
 	lower := strings.ToLower(filename)
-	if strings.HasSuffix(lower, ".mp3") {
+	if strings.HasSuffix(lower, suffix) {
 		return filename
 	}
 	if ext := filepath.Ext(filename); ext != "" {
-		return strings.TrimSuffix(filename, ext) + ".mp3"
+		return strings.TrimSuffix(filename, ext) + suffix
+	}
+	return filename + suffix
+}
+
+func isWAVAudio(mimeType, filename string) bool {
+	switch mimeType {
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return true
 	}
-	return filename + ".mp3"
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(filename)), ".wav")
 }
 
 func isOpenAICompatibleAudio(mimeType, filename string) bool {
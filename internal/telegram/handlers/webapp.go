@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/events"
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+)
+
+// webAppSubmission is the payload sent by the Mini App form via Telegram.WebApp.sendData.
+type webAppSubmission struct {
+	Token  string         `json:"token"`
+	Values map[string]any `json:"values"`
+}
+
+// handleWebAppData resolves execution with structured values submitted from the Mini App form.
+func (h *Handler) handleWebAppData(ctx context.Context, data *telego.WebAppData, from *telego.User) {
+	var submission webAppSubmission
+	if err := json.Unmarshal([]byte(data.Data), &submission); err != nil {
+		h.log.WarnContext(ctx, "Failed to parse web app data", "error", err)
+		return
+	}
+	exec := h.registry.FindByWebAppToken(submission.Token)
+	if exec == nil {
+		_ = h.reply(ctx, h.messagesFor(ctx, nil).AlreadyResolved)
+		return
+	}
+	if !canAnswer(exec, from) {
+		_ = h.reply(ctx, h.assigneeOnlyNote(ctx, exec))
+		return
+	}
+	msg := h.messagesFor(ctx, exec)
+	values, err := validateFormValues(exec.Request.Form, submission.Values)
+	if err != nil {
+		_ = h.reply(ctx, msg.Format(msg.FormInvalid, i18n.Vars{"error": err}))
+		return
+	}
+	exec, promptID, ok := h.registry.Resolve(exec.Request.CorrelationID)
+	if !ok {
+		return
+	}
+	if promptID > 0 {
+		_ = h.DeleteMessage(ctx, promptID)
+	}
+	output := executions.FormSubmitted{Question: exec.Request.Question, Values: values, InputMode: inputModeWebApp}
+	note := shared.WithEmoji(h.theme.Success, msg.FormSubmittedNote)
+	h.emitAnswer(ctx, events.TypeCustomAnswer, exec, "", nil, inputModeWebApp)
+	h.FinalizeExecution(ctx, exec, executions.Result{Status: executions.StatusSuccess, Output: output, Note: note}, "")
+}
+
+// validateFormValues keeps only declared fields and checks types, required values and select options.
+func validateFormValues(fields []executions.FormField, submitted map[string]any) (map[string]any, error) {
+	values := make(map[string]any, len(fields))
+	for _, field := range fields {
+		raw, present := submitted[field.Name]
+		if field.Type == executions.FieldCheckbox {
+			checked, _ := raw.(bool)
+			if field.Required && !checked {
+				return nil, fmt.Errorf("%s is required", field.Name)
+			}
+			values[field.Name] = checked
+			continue
+		}
+		value, ok := raw.(string)
+		if present && raw != nil && !ok {
+			return nil, fmt.Errorf("%s must be string", field.Name)
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			if field.Required {
+				return nil, fmt.Errorf("%s is required", field.Name)
+			}
+			values[field.Name] = nil
+			continue
+		}
+		switch field.Type {
+		case executions.FieldNumber:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be number", field.Name)
+			}
+			values[field.Name] = number
+		case executions.FieldDate:
+			if _, err := time.Parse(time.DateOnly, value); err != nil {
+				return nil, fmt.Errorf("%s must be date YYYY-MM-DD", field.Name)
+			}
+			values[field.Name] = value
+		case executions.FieldSelect:
+			if !slices.Contains(field.Options, value) {
+				return nil, fmt.Errorf("%s has unknown option", field.Name)
+			}
+			values[field.Name] = value
+		default:
+			values[field.Name] = value
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,86 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/telegram/updates"
+)
+
+// cachedCheck memoizes a health probe result to avoid hitting Bot API on every readiness request.
+type cachedCheck struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+func newCachedCheck(ttl time.Duration) *cachedCheck {
+	return &cachedCheck{ttl: ttl}
+}
+
+func (c *cachedCheck) Do(ctx context.Context, probe func(ctx context.Context) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.ttl {
+		return c.err
+	}
+	c.err = probe(ctx)
+	c.checkedAt = time.Now()
+	return c.err
+}
+
+// CheckTelegram verifies Telegram Bot API reachability via cached getMe call.
+func (s *Service) CheckTelegram(ctx context.Context) error {
+	return s.botCheck.Do(ctx, func(ctx context.Context) error {
+		_, err := s.bot.API().GetMe(ctx)
+		return err
+	})
+}
+
+// CheckUpdates verifies update source registration (webhook mode) via cached call.
+func (s *Service) CheckUpdates(ctx context.Context) error {
+	return s.updatesCheck.Do(ctx, s.source.Check)
+}
+
+// CheckChats fails while the bot has no access to the configured or a tenant chat (kicked or muted).
+func (s *Service) CheckChats(context.Context) error {
+	return s.handler.CheckChats()
+}
+
+// CheckUpdateLag fails when no update was processed for TG_EXECUTOR_UPDATES_LAG_THRESHOLD while prompts are
+// pending: answers would never arrive if Telegram stopped delivering updates. The lag counts from the latest of
+// the last processed update, the oldest pending prompt and startup.
+func (s *Service) CheckUpdateLag(ctx context.Context) error {
+	stats := s.registry.Stats()
+	since := time.Unix(0, s.startedAt.Load())
+	if last := s.handler.LastProcessed(); last.After(since) {
+		since = last
+	}
+	if stats.OldestCreatedAt.After(since) {
+		since = stats.OldestCreatedAt
+	}
+	lag := time.Since(since)
+	if stats.Pending == 0 || lag < s.cfg.UpdatesLagThreshold {
+		if s.lagging.Swap(false) {
+			s.log.InfoContext(ctx, "Telegram updates are processed again")
+		}
+		return nil
+	}
+	if !s.lagging.Swap(true) {
+		s.log.WarnContext(ctx, "No Telegram updates processed while prompts are pending", "lag", lag.Round(time.Second), "pending", stats.Pending)
+	}
+	return fmt.Errorf("no updates processed for %s with %d pending prompts", lag.Round(time.Second), stats.Pending)
+}
+
+// UpdateStats returns update delivery counters of the update source.
+func (s *Service) UpdateStats() updates.Stats {
+	return s.source.Stats()
+}
+
+// ObserveUpdateLatency sets a function receiving the handling time of every update; call it before Start.
+func (s *Service) ObserveUpdateLatency(observe func(time.Duration)) {
+	s.handler.SetLatencyObserver(observe)
+}
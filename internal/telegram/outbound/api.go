@@ -0,0 +1,29 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/mymmrac/telego"
+)
+
+// BotAPI is the part of the Telegram Bot API the executor calls. *telego.Bot implements it; tests substitute an
+// in-memory client to run handlers without network.
+type BotAPI interface {
+	GetMe(ctx context.Context) (*telego.User, error)
+	SendMessage(ctx context.Context, params *telego.SendMessageParams) (*telego.Message, error)
+	SendDocument(ctx context.Context, params *telego.SendDocumentParams) (*telego.Message, error)
+	SendPoll(ctx context.Context, params *telego.SendPollParams) (*telego.Message, error)
+	StopPoll(ctx context.Context, params *telego.StopPollParams) (*telego.Poll, error)
+	EditMessageText(ctx context.Context, params *telego.EditMessageTextParams) (*telego.Message, error)
+	EditMessageReplyMarkup(ctx context.Context, params *telego.EditMessageReplyMarkupParams) (*telego.Message, error)
+	DeleteMessage(ctx context.Context, params *telego.DeleteMessageParams) error
+	PinChatMessage(ctx context.Context, params *telego.PinChatMessageParams) error
+	UnpinChatMessage(ctx context.Context, params *telego.UnpinChatMessageParams) error
+	AnswerCallbackQuery(ctx context.Context, params *telego.AnswerCallbackQueryParams) error
+	AnswerInlineQuery(ctx context.Context, params *telego.AnswerInlineQueryParams) error
+	GetChatMember(ctx context.Context, params *telego.GetChatMemberParams) (telego.ChatMember, error)
+	GetFile(ctx context.Context, params *telego.GetFileParams) (*telego.File, error)
+	FileDownloadURL(filepath string) string
+}
+
+var _ BotAPI = (*telego.Bot)(nil)
@@ -0,0 +1,158 @@
+package outbound
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mymmrac/telego"
+)
+
+// Bot is a Telegram bot whose chat-bound calls (send, edit, delete, pin, polls) go through the outbound queue.
+// Other Bot API methods are called directly on API.
+type Bot struct {
+	api   atomic.Pointer[BotAPI]
+	queue *Queue
+
+	// previous is the client of the bot replaced by FailOver; firstSent is the id of the first message the current
+	// client posted in every chat since. Message ids only grow within a chat, so lower ids belong to previous.
+	mu        sync.Mutex
+	previous  BotAPI
+	firstSent map[string]int
+}
+
+// NewBot wraps api with the queue.
+func NewBot(api BotAPI, queue *Queue) *Bot {
+	b := &Bot{queue: queue}
+	b.api.Store(&api)
+	return b
+}
+
+// API returns the current Bot API client.
+func (b *Bot) API() BotAPI {
+	return *b.api.Load()
+}
+
+// SetAPI switches all calls, including queued ones not started yet, to a client with a rotated token.
+func (b *Bot) SetAPI(api BotAPI) {
+	b.api.Store(&api)
+}
+
+// FailOver switches to api of another bot. New messages are sent by api, while edits, deletions and stopped polls
+// of messages posted before keep going through the previous client: another bot cannot change them.
+func (b *Bot) FailOver(api BotAPI) {
+	b.mu.Lock()
+	b.previous = b.API()
+	b.firstSent = make(map[string]int)
+	b.mu.Unlock()
+	b.SetAPI(api)
+}
+
+// messageAPI returns the client of the bot that posted the message.
+func (b *Bot) messageAPI(chat telego.ChatID, messageID int) BotAPI {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.previous != nil {
+		if first, ok := b.firstSent[chat.String()]; !ok || messageID < first {
+			return b.previous
+		}
+	}
+	return b.API()
+}
+
+// sent records a message posted by the current client after FailOver.
+func (b *Bot) sent(chat telego.ChatID, msg *telego.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.previous == nil || msg == nil {
+		return
+	}
+	if _, ok := b.firstSent[chat.String()]; !ok {
+		b.firstSent[chat.String()] = msg.MessageID
+	}
+}
+
+// Queue returns the outbound queue of the bot.
+func (b *Bot) Queue() *Queue {
+	return b.queue
+}
+
+// SendMessage queues telego.Bot.SendMessage.
+func (b *Bot) SendMessage(ctx context.Context, params *telego.SendMessageParams) (*telego.Message, error) {
+	return queued(ctx, b.queue, params.ChatID, func(ctx context.Context) (*telego.Message, error) {
+		msg, err := b.API().SendMessage(ctx, params)
+		b.sent(params.ChatID, msg)
+		return msg, err
+	})
+}
+
+// SendDocument queues telego.Bot.SendDocument.
+func (b *Bot) SendDocument(ctx context.Context, params *telego.SendDocumentParams) (*telego.Message, error) {
+	return queued(ctx, b.queue, params.ChatID, func(ctx context.Context) (*telego.Message, error) {
+		msg, err := b.API().SendDocument(ctx, params)
+		b.sent(params.ChatID, msg)
+		return msg, err
+	})
+}
+
+// SendPoll queues telego.Bot.SendPoll.
+func (b *Bot) SendPoll(ctx context.Context, params *telego.SendPollParams) (*telego.Message, error) {
+	return queued(ctx, b.queue, params.ChatID, func(ctx context.Context) (*telego.Message, error) {
+		msg, err := b.API().SendPoll(ctx, params)
+		b.sent(params.ChatID, msg)
+		return msg, err
+	})
+}
+
+// StopPoll queues telego.Bot.StopPoll.
+func (b *Bot) StopPoll(ctx context.Context, params *telego.StopPollParams) (*telego.Poll, error) {
+	return queued(ctx, b.queue, params.ChatID, func(ctx context.Context) (*telego.Poll, error) {
+		return b.messageAPI(params.ChatID, params.MessageID).StopPoll(ctx, params)
+	})
+}
+
+// EditMessageText queues telego.Bot.EditMessageText.
+func (b *Bot) EditMessageText(ctx context.Context, params *telego.EditMessageTextParams) (*telego.Message, error) {
+	return queued(ctx, b.queue, params.ChatID, func(ctx context.Context) (*telego.Message, error) {
+		return b.messageAPI(params.ChatID, params.MessageID).EditMessageText(ctx, params)
+	})
+}
+
+// EditMessageReplyMarkup queues telego.Bot.EditMessageReplyMarkup.
+func (b *Bot) EditMessageReplyMarkup(ctx context.Context, params *telego.EditMessageReplyMarkupParams) (*telego.Message, error) {
+	return queued(ctx, b.queue, params.ChatID, func(ctx context.Context) (*telego.Message, error) {
+		return b.messageAPI(params.ChatID, params.MessageID).EditMessageReplyMarkup(ctx, params)
+	})
+}
+
+// DeleteMessage queues telego.Bot.DeleteMessage.
+func (b *Bot) DeleteMessage(ctx context.Context, params *telego.DeleteMessageParams) error {
+	return b.queue.Do(ctx, params.ChatID.String(), func(ctx context.Context) error {
+		return b.messageAPI(params.ChatID, params.MessageID).DeleteMessage(ctx, params)
+	})
+}
+
+// PinChatMessage queues telego.Bot.PinChatMessage.
+func (b *Bot) PinChatMessage(ctx context.Context, params *telego.PinChatMessageParams) error {
+	return b.queue.Do(ctx, params.ChatID.String(), func(ctx context.Context) error {
+		return b.API().PinChatMessage(ctx, params)
+	})
+}
+
+// UnpinChatMessage queues telego.Bot.UnpinChatMessage.
+func (b *Bot) UnpinChatMessage(ctx context.Context, params *telego.UnpinChatMessageParams) error {
+	return b.queue.Do(ctx, params.ChatID.String(), func(ctx context.Context) error {
+		return b.API().UnpinChatMessage(ctx, params)
+	})
+}
+
+// queued runs call in the chat queue and returns its result.
+func queued[T any](ctx context.Context, queue *Queue, chat telego.ChatID, call func(context.Context) (T, error)) (T, error) {
+	var result T
+	err := queue.Do(ctx, chat.String(), func(ctx context.Context) error {
+		var err error
+		result, err = call(ctx)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,2 @@
+// Package outbound serializes chat-bound Telegram calls through per-chat queues with rate control.
+package outbound
@@ -0,0 +1,204 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	ta "github.com/mymmrac/telego/telegoapi"
+)
+
+// Queue runs calls of each chat one by one in submission order, keeping them within per-chat and global rate limits.
+type Queue struct {
+	mu        sync.Mutex
+	chats     map[string]*chatQueue
+	global    bucket
+	chatRate  float64
+	chatBurst int
+}
+
+type chatQueue struct {
+	jobs    []*job
+	active  bool
+	limiter bucket
+}
+
+type job struct {
+	ctx  context.Context
+	call func(context.Context) error
+	done chan error
+}
+
+// Depth is the number of queued and running calls of a chat.
+type Depth struct {
+	Chat  string
+	Calls int
+}
+
+// NewQueue creates a queue allowing chatRate calls per second in every chat (with bursts of chatBurst calls)
+// and globalRate calls per second in total.
+func NewQueue(chatRate float64, chatBurst int, globalRate float64) *Queue {
+	return &Queue{
+		chats:     make(map[string]*chatQueue),
+		global:    newBucket(globalRate, max(1, int(globalRate))),
+		chatRate:  chatRate,
+		chatBurst: chatBurst,
+	}
+}
+
+// Do enqueues call for the chat and waits for its result. Calls whose context is done before their turn are dropped.
+func (q *Queue) Do(ctx context.Context, chat string, call func(context.Context) error) error {
+	j := &job{ctx: ctx, call: call, done: make(chan error, 1)}
+	q.mu.Lock()
+	c, ok := q.chats[chat]
+	if !ok {
+		c = &chatQueue{limiter: newBucket(q.chatRate, q.chatBurst)}
+		q.chats[chat] = c
+	}
+	c.jobs = append(c.jobs, j)
+	if !c.active {
+		c.active = true
+		go q.drain(c)
+	}
+	q.mu.Unlock()
+
+	select {
+	case err := <-j.done:
+		return err
+	case <-ctx.Done():
+	}
+	q.mu.Lock()
+	for idx, queued := range c.jobs {
+		if queued == j {
+			c.jobs = append(c.jobs[:idx], c.jobs[idx+1:]...)
+			q.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+	q.mu.Unlock()
+	// The call has already started; it sees the cancelled context and returns shortly.
+	return <-j.done
+}
+
+// drain runs queued calls of the chat until the queue is empty; at most one drain runs per chat.
+func (q *Queue) drain(c *chatQueue) {
+	for {
+		q.mu.Lock()
+		if len(c.jobs) == 0 {
+			c.active = false
+			q.mu.Unlock()
+			return
+		}
+		j := c.jobs[0]
+		c.jobs[0] = nil
+		c.jobs = c.jobs[1:]
+		if err := j.ctx.Err(); err != nil {
+			q.mu.Unlock()
+			j.done <- err
+			continue
+		}
+		now := time.Now()
+		wait := max(c.limiter.reserve(now), q.global.reserve(now))
+		q.mu.Unlock()
+
+		if err := sleep(j.ctx, wait); err != nil {
+			j.done <- err
+			continue
+		}
+		err := j.call(j.ctx)
+		if pause, ok := floodWait(err); ok {
+			q.mu.Lock()
+			c.limiter.pause(time.Now().Add(pause))
+			q.mu.Unlock()
+		}
+		j.done <- err
+	}
+}
+
+// Depths returns per-chat numbers of queued and running calls, sorted by chat.
+func (q *Queue) Depths() []Depth {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	depths := make([]Depth, 0, len(q.chats))
+	for chat, c := range q.chats {
+		calls := len(c.jobs)
+		if c.active {
+			// The drain goroutine holds the running call outside of jobs.
+			calls++
+		}
+		if calls > 0 {
+			depths = append(depths, Depth{Chat: chat, Calls: calls})
+		}
+	}
+	sort.Slice(depths, func(i, j int) bool { return depths[i].Chat < depths[j].Chat })
+	return depths
+}
+
+// floodWait extracts retry_after of a Telegram flood control error.
+func floodWait(err error) (time.Duration, bool) {
+	var apiErr *ta.Error
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode != http.StatusTooManyRequests || apiErr.Parameters == nil {
+		return 0, false
+	}
+	return time.Duration(apiErr.Parameters.RetryAfter) * time.Second, apiErr.Parameters.RetryAfter > 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// bucket is a token bucket; reserve may drive tokens negative, the debt is the caller's wait.
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64, burst int) bucket {
+	return bucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes a token and returns how long to wait before using it.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	var wait time.Duration
+	if now.Before(b.last) {
+		// Paused by flood control: nothing is refilled until the pause ends.
+		wait = b.last.Sub(now)
+	} else {
+		if !b.last.IsZero() {
+			b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		}
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens < 0 {
+		wait += time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	return wait
+}
+
+// pause blocks the bucket until the moment, e.g. when Telegram asks to retry after a delay.
+func (b *bucket) pause(until time.Time) {
+	if b.rate <= 0 || !until.After(b.last) {
+		return
+	}
+	b.last = until
+	b.tokens = min(b.tokens, 0)
+}
@@ -0,0 +1,99 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/render"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const truncatedMarker = "…"
+
+// fitPrompt renders prompt so that it fits Telegram message limit.
+// It progressively drops params and details, truncates context and question, and finally drops the options list.
+// The returned flag reports that full arguments must be attached as a document.
+func (s *Service) fitPrompt(req executions.Request) (executions.Request, render.Text, render.Text, bool) {
+	text, details := s.renderMessages(req)
+	attach := false
+
+	if details.Text != "" && !shared.FitsMessage(details.Text) {
+		req.Render.CollapseParams = false
+		req.Render.Sections = render.WithoutSection(req.Render.Sections, executions.SectionParams)
+		text, details = s.renderMessages(req)
+		attach = true
+	}
+	if !shared.FitsMessage(text.Text) && slices.Contains(req.Render.Sections, executions.SectionParams) {
+		req.Render.Sections = render.WithoutSection(req.Render.Sections, executions.SectionParams)
+		text, details = s.renderMessages(req)
+		attach = true
+	}
+	for _, field := range []*string{&req.Context, &req.Question} {
+		if shared.FitsMessage(text.Text) {
+			break
+		}
+		*field = shrinkToFit(*field, shared.TextLength(text.Text)-shared.MaxMessageLength)
+		text, details = s.renderMessages(req)
+		attach = true
+	}
+	if !shared.FitsMessage(text.Text) {
+		req.Render.Sections = render.WithoutSection(req.Render.Sections, executions.SectionOptions)
+		text, details = s.renderMessages(req)
+		attach = true
+	}
+	return req, text, details, attach
+}
+
+// shrinkToFit removes at least overflow characters from value, accounting for markup escaping that may double them.
+func shrinkToFit(value string, overflow int) string {
+	keep := len([]rune(value)) - overflow*2 - len([]rune(truncatedMarker))
+	return shared.TruncateRunes(value, keep, truncatedMarker)
+}
+
+// sendArgumentsDocument attaches full request arguments as a JSON document replying to the prompt.
+func (s *Service) sendArgumentsDocument(ctx context.Context, req executions.Request, replyTo int) {
+	msg := s.requestMessages(req)
+	name := fmt.Sprintf("%s-arguments.json", req.ClientCorrelationID())
+	s.sendDocument(ctx, req, replyTo, name, []byte(render.ArgumentsJSON(req.Arguments)), fallbackText(msg.AttachmentCaption, "Full request parameters"))
+}
+
+// sendDiffDocuments attaches diff-like arguments as .diff documents replying to the prompt.
+func (s *Service) sendDiffDocuments(ctx context.Context, req executions.Request, replyTo int) {
+	_, diffs := render.SplitDiffArguments(req.Arguments)
+	for _, diff := range diffs {
+		name := fmt.Sprintf("%s-%s.diff", req.ClientCorrelationID(), diff.Key)
+		s.sendDocument(ctx, req, replyTo, name, []byte(diff.Value+"\n"), diff.Key)
+	}
+}
+
+// sendFullTextDocuments attaches question and context truncated by spec.overflow as .txt documents.
+func (s *Service) sendFullTextDocuments(ctx context.Context, req executions.Request, replyTo int) {
+	for _, key := range []string{"question", "context"} {
+		text, ok := req.FullTexts[key]
+		if !ok {
+			continue
+		}
+		name := fmt.Sprintf("%s-%s.txt", req.ClientCorrelationID(), key)
+		s.sendDocument(ctx, req, replyTo, name, []byte(text+"\n"), key)
+	}
+}
+
+func (s *Service) sendDocument(ctx context.Context, req executions.Request, replyTo int, name string, data []byte, caption string) {
+	_, err := s.bot.SendDocument(ctx, &telego.SendDocumentParams{
+		ChatID:   tu.ID(req.ChatID),
+		Document: tu.FileFromBytes(data, name),
+		Caption:  shared.TruncateRunes(caption, shared.MaxCaptionLength/2, truncatedMarker),
+		ReplyParameters: (&telego.ReplyParameters{
+			MessageID: replyTo,
+		}).WithAllowSendingWithoutReply(),
+		DisableNotification: true,
+	})
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to send document", "error", err, "document", name, "correlation_id", req.CorrelationID)
+		s.reportTelegramError(ctx, err, "send_document", req.CorrelationID)
+	}
+}
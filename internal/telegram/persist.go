@@ -0,0 +1,126 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/events"
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+)
+
+// noteExpiredDuringDowntime marks callbacks of executions whose deadline passed while the service was down.
+const noteExpiredDuringDowntime = "expired_during_downtime"
+
+// persistDelay batches state file writes of execution changes arriving close together.
+const persistDelay = 200 * time.Millisecond
+
+// executionWriter writes pending executions to the state file off the event bus: events only mark executions
+// dirty, and runExecutionWriter saves them with one write at most every persistDelay.
+type executionWriter struct {
+	mu    sync.Mutex
+	dirty map[string]struct{}
+	wake  chan struct{}
+	// flushMu serializes flushes, so a snapshot read before a resolution is never written after its removal.
+	flushMu sync.Mutex
+}
+
+func newExecutionWriter() *executionWriter {
+	return &executionWriter{dirty: make(map[string]struct{}), wake: make(chan struct{}, 1)}
+}
+
+// persistExecution keeps pending executions in the state file: the snapshot is saved once the prompt is posted
+// or moved and removed by the first event after the execution is resolved.
+func (s *Service) persistExecution(event events.Event) {
+	if event.CorrelationID == "" {
+		return
+	}
+	if event.Type != events.TypePromptSent && event.Type != events.TypePromptBumped && s.registry.Get(event.CorrelationID) != nil {
+		return
+	}
+	s.persist.mu.Lock()
+	s.persist.dirty[event.CorrelationID] = struct{}{}
+	s.persist.mu.Unlock()
+	select {
+	case s.persist.wake <- struct{}{}:
+	default:
+	}
+}
+
+// runExecutionWriter flushes marked executions until ctx is done; Stop flushes the changes made afterwards.
+func (s *Service) runExecutionWriter(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			s.flushExecutions()
+			return
+		case <-s.persist.wake:
+		}
+		timer := time.NewTimer(persistDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		s.flushExecutions()
+	}
+}
+
+// flushExecutions saves the current state of the marked executions: snapshots of pending ones, removal of
+// resolved ones.
+func (s *Service) flushExecutions() {
+	s.persist.flushMu.Lock()
+	defer s.persist.flushMu.Unlock()
+	s.persist.mu.Lock()
+	dirty := s.persist.dirty
+	s.persist.dirty = make(map[string]struct{})
+	s.persist.mu.Unlock()
+	if len(dirty) == 0 {
+		return
+	}
+	saved := make(map[string]json.RawMessage)
+	var deleted []string
+	for correlationID := range dirty {
+		snapshot, pending := s.registry.Snapshot(correlationID)
+		if !pending {
+			deleted = append(deleted, correlationID)
+			continue
+		}
+		raw, err := json.Marshal(snapshot)
+		if err != nil {
+			s.log.Warn("Failed to persist execution", "error", err, "correlation_id", correlationID)
+			continue
+		}
+		saved[correlationID] = raw
+	}
+	if err := s.state.UpdateExecutions(saved, deleted); err != nil {
+		s.log.Warn("Failed to persist executions", "error", err, "saved", len(saved), "deleted", len(deleted))
+	}
+}
+
+// restoreExecutions registers pending executions saved before restart. Timers are computed from the absolute
+// deadline; executions that expired during downtime time out immediately.
+func (s *Service) restoreExecutions(ctx context.Context) {
+	now := time.Now()
+	for correlationID, raw := range s.state.Executions() {
+		var snapshot executions.Snapshot
+		if err := json.Unmarshal(raw, &snapshot); err != nil || snapshot.Request.CorrelationID != correlationID {
+			s.log.WarnContext(ctx, "Dropping unreadable execution snapshot", "error", err, "correlation_id", correlationID)
+			_ = s.state.DeleteExecution(correlationID)
+			continue
+		}
+		if _, err := s.registry.Restore(snapshot); err != nil {
+			continue
+		}
+		s.handler.AllowChat(snapshot.Request.ChatID)
+		remaining := snapshot.Request.Deadline.Sub(now)
+		if remaining <= 0 {
+			s.log.InfoContext(ctx, "Execution expired during downtime", "correlation_id", correlationID, "deadline", snapshot.Request.Deadline)
+			s.expire(ctx, correlationID, s.cfg.TimeoutMessage, noteExpiredDuringDowntime)
+			continue
+		}
+		s.log.InfoContext(ctx, "Execution restored", "correlation_id", correlationID, "remaining", remaining)
+		s.scheduleTimeout(correlationID, remaining, s.cfg.TimeoutMessage)
+	}
+}
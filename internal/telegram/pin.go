@@ -0,0 +1,30 @@
+package telegram
+
+import (
+	"context"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// pinPrompt pins an urgent prompt so it stays on top of the chat until it resolves.
+// Missing pin permission only loses the pin, the prompt itself is already sent.
+func (s *Service) pinPrompt(ctx context.Context, req executions.Request, messageID int) {
+	err := s.bot.PinChatMessage(ctx, &telego.PinChatMessageParams{
+		ChatID:    tu.ID(req.ChatID),
+		MessageID: messageID,
+	})
+	if err != nil {
+		s.log.WarnContext(ctx, "Failed to pin urgent prompt", "error", err, "correlation_id", req.CorrelationID)
+		s.reportTelegramError(ctx, err, "pin_message", req.CorrelationID)
+		return
+	}
+	if !s.registry.SetPinned(req.CorrelationID) {
+		// Resolved while pinning: nobody else will unpin it.
+		_ = s.bot.UnpinChatMessage(ctx, &telego.UnpinChatMessageParams{
+			ChatID:    tu.ID(req.ChatID),
+			MessageID: messageID,
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package telegram
+
+import (
+	"context"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	maxPollQuestionLength = 300
+	maxPollOptionLength   = 100
+)
+
+// sendPoll presents execution options as a non-anonymous poll replying to the prompt.
+// Falls back to inline option buttons when poll cannot be sent.
+func (s *Service) sendPoll(ctx context.Context, req executions.Request, promptID int) {
+	options := make([]telego.InputPollOption, 0, len(req.Options))
+	for _, option := range req.Options {
+		options = append(options, tu.PollOption(shared.TruncateRunes(option, maxPollOptionLength, truncatedMarker)))
+	}
+	params := tu.Poll(tu.ID(req.ChatID), shared.TruncateRunes(req.Question, maxPollQuestionLength, truncatedMarker), options...).
+		WithIsAnonymous(false).
+		WithReplyParameters((&telego.ReplyParameters{MessageID: promptID}).WithAllowSendingWithoutReply())
+	poll, err := s.bot.SendPoll(ctx, params)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to send telegram poll", "error", err, "correlation_id", req.CorrelationID)
+		s.reportTelegramError(ctx, err, "send_poll", req.CorrelationID)
+		// Keep execution answerable with regular option buttons.
+		_, err = s.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+			ChatID:      tu.ID(req.ChatID),
+			MessageID:   promptID,
+			ReplyMarkup: s.optionsKeyboard(req),
+		})
+		if err != nil {
+			s.log.ErrorContext(ctx, "Failed to attach option buttons after poll failure", "error", err, "correlation_id", req.CorrelationID)
+		}
+		return
+	}
+	if poll.Poll == nil {
+		return
+	}
+	s.registry.SetPoll(req.CorrelationID, poll.Poll.ID, poll.MessageID)
+}
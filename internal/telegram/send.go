@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
+)
+
+// sendWithRetry sends the prompt message, retrying transient failures with exponential backoff.
+// A prompt that triggers the failover to the standby bot is sent once more by the standby bot.
+func (s *Service) sendWithRetry(ctx context.Context, correlationID string, params *telego.SendMessageParams) (*telego.Message, error) {
+	backoff := s.cfg.SendRetryBackoff
+	for attempt := 1; ; attempt++ {
+		msg, err := s.bot.SendMessage(ctx, params)
+		if err == nil {
+			s.recordPromptResult(ctx, nil)
+			return msg, nil
+		}
+		delay, retry := retryDelay(err, backoff)
+		if !retry || attempt > s.cfg.SendRetries || ctx.Err() != nil {
+			if s.recordPromptResult(ctx, err) {
+				return s.bot.SendMessage(ctx, params)
+			}
+			return nil, err
+		}
+		s.log.WarnContext(ctx, "Failed to send telegram message, retrying",
+			"error", err,
+			"correlation_id", correlationID,
+			"attempt", attempt,
+			"delay", delay,
+		)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// retryDelay reports whether the send error is transient: a network failure, flood control
+// (waiting at least retry_after) or a Bot API server error. Other API errors are final.
+func retryDelay(err error, backoff time.Duration) (time.Duration, bool) {
+	var apiErr *ta.Error
+	if !errors.As(err, &apiErr) {
+		return backoff, true
+	}
+	switch {
+	case apiErr.ErrorCode == http.StatusTooManyRequests:
+		if apiErr.Parameters != nil {
+			return max(backoff, time.Duration(apiErr.Parameters.RetryAfter)*time.Second), true
+		}
+		return backoff, true
+	case apiErr.ErrorCode >= http.StatusInternalServerError:
+		return backoff, true
+	default:
+		return 0, false
+	}
+}
@@ -2,98 +2,282 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/codex-k8s/telegram-executor/internal/config"
+	"github.com/codex-k8s/telegram-executor/internal/events"
 	"github.com/codex-k8s/telegram-executor/internal/executions"
 	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	applog "github.com/codex-k8s/telegram-executor/internal/log"
+	"github.com/codex-k8s/telegram-executor/internal/normalize"
+	"github.com/codex-k8s/telegram-executor/internal/render"
+	"github.com/codex-k8s/telegram-executor/internal/reporting"
+	"github.com/codex-k8s/telegram-executor/internal/state"
+	"github.com/codex-k8s/telegram-executor/internal/stt"
 	"github.com/codex-k8s/telegram-executor/internal/telegram/handlers"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/outbound"
 	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
 	"github.com/codex-k8s/telegram-executor/internal/telegram/updates"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
 )
 
-const timeoutResult = "execution timeout"
+var (
+	// ErrInvalidKeyboard is returned by SubmitExecution for requests whose keyboard breaks Telegram limits.
+	ErrInvalidKeyboard = render.ErrInvalidKeyboard
+	// ErrNotSimulated is returned by Simulate* methods of a service talking to Telegram.
+	ErrNotSimulated = errors.New("simulation mode is disabled")
+	// ErrSimulationNotBuilt is returned by NewSimulated in binaries built without the simulate tag.
+	ErrSimulationNotBuilt = errors.New("simulation mode requires a binary built with -tags simulate")
+)
 
 // Service manages Telegram bot lifecycle and execution requests.
 type Service struct {
-	bot      *telego.Bot
-	source   updates.Source
-	handler  *handlers.Handler
-	registry *executions.Registry
-	log      *slog.Logger
-	messages map[string]i18n.Messages
-	lang     string
-	chatID   int64
+	bot       *outbound.Bot
+	source    updates.Source
+	handler   *handlers.Handler
+	registry  *executions.Registry
+	tenants   *tenants.Set
+	bus       *events.Bus
+	reporter  reporting.Reporter
+	log       *slog.Logger
+	messages  map[string]i18n.Messages
+	lang      string
+	chatID    int64
+	templates *messageTemplates
+	cfg       config.Config
+	state     *state.Store
+	theme     shared.Theme
+	watchdog  *watchdog
+	persist   *executionWriter
+
+	botCheck     *cachedCheck
+	updatesCheck *cachedCheck
+	// startedAt and lagging back the updates lag check.
+	startedAt atomic.Int64
+	lagging   atomic.Bool
+
+	// tokenMu serializes token rotations and the failover; token is the token of the current client.
+	tokenMu sync.Mutex
+	token   string
+	// standby takes over after promptFailures reaches the failover threshold; failedOver is set once it did.
+	standby        *telego.Bot
+	promptFailures int
+	failedOver     bool
+
+	// simulation is set by NewSimulated.
+	simulation *simulation
 }
 
 // New creates a new Telegram service.
-func New(cfg config.Config, bundle i18n.Bundle, registry *executions.Registry, log *slog.Logger) (*Service, error) {
-	bot, err := telego.NewBot(cfg.Token, telego.WithLogger(telegoLogger{log: log}))
+func New(cfg config.Config, bundle i18n.Bundle, registry *executions.Registry, store *state.Store, tenantSet *tenants.Set, bus *events.Bus, reporter reporting.Reporter, log *slog.Logger) (*Service, error) {
+	apiBot, err := telego.NewBot(cfg.Token, telego.WithLogger(telegoLogger{log: log}))
 	if err != nil {
 		return nil, err
 	}
-
+	var standby *telego.Bot
+	if cfg.StandbyToken != "" {
+		if standby, err = telego.NewBot(cfg.StandbyToken, telego.WithLogger(telegoLogger{log: log})); err != nil {
+			return nil, fmt.Errorf("standby token: %w", err)
+		}
+	}
 	var source updates.Source
 	if cfg.WebhookEnabled() {
-		source = updates.NewWebhook(bot, cfg.WebhookURL, cfg.WebhookSecret, log)
+		source = updates.NewWebhook(apiBot, updates.WebhookOptions{
+			URL:            cfg.WebhookURL,
+			Secret:         cfg.WebhookSecret,
+			PreviousSecret: cfg.WebhookPreviousSecret,
+			SecretGrace:    cfg.WebhookSecretGrace,
+			PathSecret:     cfg.WebhookPathSecret,
+		}, log)
 	} else {
-		source = updates.NewLongPolling(bot, log)
+		source = updates.NewLongPolling(apiBot, log)
 	}
 
-	var transcriber handlers.Transcriber
-	if cfg.OpenAIAPIKey != "" {
-		transcriber = handlers.NewOpenAITranscriber(cfg.OpenAIAPIKey, cfg.STTModel, cfg.STTTimeout, log)
+	svc, err := NewWithAPI(cfg, apiBot, source, bundle, registry, store, tenantSet, bus, reporter, log)
+	if err != nil {
+		return nil, err
 	}
+	svc.standby = standby
+	return svc, nil
+}
 
-	sttLang := cfg.Lang
-	if sttLang == "" {
-		sttLang = "en"
+// NewWithAPI creates a Telegram service calling api and receiving updates from source, e.g. an in-memory client
+// in tests. The standby bot of TG_EXECUTOR_STANDBY_TOKEN is only set up by New.
+func NewWithAPI(cfg config.Config, api outbound.BotAPI, source updates.Source, bundle i18n.Bundle, registry *executions.Registry, store *state.Store, tenantSet *tenants.Set, bus *events.Bus, reporter reporting.Reporter, log *slog.Logger) (*Service, error) {
+	bot := outbound.NewBot(api, outbound.NewQueue(cfg.OutboundChatRate, cfg.OutboundChatBurst, cfg.OutboundGlobalRate))
+
+	var transcriber handlers.Transcriber
+	var normalizer handlers.AnswerNormalizer
+	if cfg.AnswerNormalization {
+		normalizer = normalize.New(cfg.OpenAIAPIKey, cfg.OpenAIBaseURL, cfg.NormalizeModel, cfg.NormalizeTimeout, log)
 	}
 
-	messages := map[string]i18n.Messages{bundle.Lang: bundle.Messages}
-	if bundle.Lang != "en" {
-		if extra, err := i18n.Load("en"); err == nil {
-			messages[extra.Lang] = extra.Messages
-		}
+	sttBackend, err := stt.New(cfg, log)
+	if err != nil {
+		return nil, err
 	}
-	if bundle.Lang != "ru" {
-		if extra, err := i18n.Load("ru"); err == nil {
-			messages[extra.Lang] = extra.Messages
+	if sttBackend != nil {
+		transcriber = sttBackend
+		log.Info("Voice transcription enabled", "provider", cfg.STTProvider)
+		if handlers.VoiceNeedsFFmpeg(sttBackend) {
+			log.Warn("ffmpeg is not installed: voice messages can't be converted to WAV for the STT provider and will fail", "provider", cfg.STTProvider)
 		}
 	}
 
-	handler := handlers.NewHandler(bot, registry, messages, cfg.Lang, cfg.ChatID, sttLang, transcriber, log)
+	templates, err := loadTemplates(cfg.TemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+	if templates.Len() > 0 {
+		log.Info("Loaded message templates", "dir", cfg.TemplatesDir, "count", templates.Len())
+	}
+
+	messages := bundle.All
+	theme := themeFromConfig(cfg.Theme)
+	for _, chatID := range append([]int64{cfg.ChatID}, tenantSet.ChatIDs()...) {
+		if migrated := store.MigratedChat(chatID); migrated != chatID {
+			log.Warn("Configured chat was migrated to supergroup, update the configuration", "chat_id", chatID, "migrated_chat_id", migrated)
+		}
+	}
 
-	return &Service{
-		bot:      bot,
-		source:   source,
-		handler:  handler,
-		registry: registry,
-		log:      log,
-		messages: messages,
-		lang:     cfg.Lang,
-		chatID:   cfg.ChatID,
-	}, nil
+	handler := handlers.NewHandler(bot, registry, store, handlers.Options{
+		Messages:        messages,
+		DefaultLang:     cfg.Lang,
+		DefaultTimezone: cfg.Timezone,
+		ChatID:          cfg.ChatID,
+		TenantChats:     tenantSet.ChatIDs(),
+		STTLang:         cfg.STTLang,
+		Transcriber:     transcriber,
+		Normalizer:      normalizer,
+		EditGrace:       cfg.EditGracePeriod,
+		MaxDocumentSize: cfg.DocumentAnswerMaxSize,
+		VoiceConfirm:    cfg.VoiceConfirmation,
+		VoiceLimits: handlers.VoiceLimits{
+			MaxDuration: cfg.VoiceMaxDuration,
+			MaxSize:     cfg.VoiceMaxSize,
+			Concurrency: cfg.STTConcurrency,
+			QueueSize:   cfg.STTQueueSize,
+		},
+		MatchThreshold: cfg.OptionMatchThreshold,
+		FinalizeMode:   cfg.FinalizeMode,
+		DecisionsChat:  cfg.DecisionsChatID,
+		Theme:          theme,
+	}, bus, reporter, log)
+
+	svc := &Service{
+		bot:       bot,
+		source:    source,
+		handler:   handler,
+		registry:  registry,
+		tenants:   tenantSet,
+		bus:       bus,
+		reporter:  reporter,
+		log:       log,
+		messages:  messages,
+		lang:      cfg.Lang,
+		chatID:    cfg.ChatID,
+		templates: templates,
+		cfg:       cfg,
+		state:     store,
+		theme:     theme,
+		persist:   newExecutionWriter(),
+
+		botCheck:     newCachedCheck(cfg.HealthCacheTTL),
+		updatesCheck: newCachedCheck(cfg.HealthCacheTTL),
+		token:        cfg.Token,
+	}
+	handler.SetKeyboardBuilder(svc)
+	handler.SetLegacyStatus(cfg.CallbackLegacyStatus)
+	if cfg.WebhookEnabled() {
+		handler.SetUpdateMode("webhook")
+	} else {
+		handler.SetUpdateMode("long polling")
+	}
+	bus.Subscribe(svc.persistExecution)
+	if cfg.AdminChatID != 0 {
+		svc.watchdog = newWatchdog(cfg.AdminChatID, cfg.WatchdogInterval, cfg.WatchdogThreshold, cfg.AlertCooldown)
+		bus.Subscribe(svc.watchdog.observe)
+	}
+	return svc, nil
 }
 
 // Start begins receiving Telegram updates.
 func (s *Service) Start(ctx context.Context) error {
+	s.startedAt.Store(time.Now().UnixNano())
+	s.restoreExecutions(ctx)
 	if err := s.source.Start(ctx); err != nil {
 		return err
 	}
 	go s.handler.Run(ctx, s.source.Updates())
+	go s.runExecutionWriter(ctx)
+	if s.watchdog != nil {
+		go s.runWatchdog(ctx)
+	}
+	if s.cfg.TokenFile != "" {
+		go s.watchTokenFile(ctx)
+	}
 	return nil
 }
 
-// Stop shuts down Telegram update processing.
+// Stop shuts down Telegram update processing, stops timeout timers and waits for expiring executions, callbacks
+// and held answers until ctx is done; work still running then is canceled. With ResolvePendingOnShutdown pending
+// executions are resolved with the shutdown status first. Pending executions are saved to the state file last.
 func (s *Service) Stop(ctx context.Context) error {
-	return s.source.Stop(ctx)
+	err := s.source.Stop(ctx)
+	if s.cfg.ResolvePendingOnShutdown {
+		s.dropPending(ctx)
+	}
+	err = errors.Join(err, s.handler.Shutdown(ctx))
+	s.flushExecutions()
+	return err
+}
+
+// dropPending resolves all pending executions with the shutdown status, notifying chats and callbacks.
+func (s *Service) dropPending(ctx context.Context) {
+	for _, correlationID := range s.registry.Pending() {
+		if ctx.Err() != nil {
+			return
+		}
+		exec, promptID, ok := s.registry.Resolve(correlationID)
+		if !ok {
+			continue
+		}
+		execCtx := handlers.WithExecution(ctx, exec)
+		if promptID > 0 {
+			_ = s.handler.DeleteMessage(execCtx, promptID)
+		}
+		dropped := events.New(events.TypeShutdown, correlationID, exec.Request.Tool.Name, exec.CreatedAt)
+		dropped.MessageID = exec.MessageID
+		s.bus.Emit(dropped)
+		s.handler.FinalizeExecution(execCtx, exec, executions.Result{
+			Status: executions.StatusShutdown,
+			Output: executions.Shutdown{},
+		}, "")
+	}
+}
+
+// Announce sends a localized startup message with the number of restored pending executions.
+func (s *Service) Announce(ctx context.Context, version string) error {
+	msg := s.messagesFor(s.lang)
+	text := msg.Format(fallbackText(msg.StartupAnnouncement, "telegram-executor {version} started. Pending prompts restored: {pending}."), i18n.Vars{
+		"version": version,
+		"pending": s.registry.Stats().Pending,
+	})
+	_, err := s.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID:              tu.ID(s.state.MigratedChat(s.chatID)),
+		Text:                text,
+		DisableNotification: true,
+	})
+	return err
 }
 
 // WebhookHandler returns the webhook HTTP handler if enabled.
@@ -106,263 +290,349 @@ func (s *Service) SubmitExecution(ctx context.Context, req executions.Request, t
 	if timeout <= 0 {
 		timeout = time.Hour
 	}
-	_, err := s.registry.Add(req)
+	if req.ChatID == 0 {
+		req.ChatID = s.chatID
+	}
+	req.ChatID = s.state.MigratedChat(req.ChatID)
+	s.handler.AllowChat(req.ChatID)
+	ctx = applog.WithAttrs(ctx, req.LogAttrs()...)
+	if err := s.handler.ChatError(req.ChatID); err != nil {
+		return executions.Result{Status: executions.StatusError, Output: executions.Error{Message: err.Error()}}, nil
+	}
+	if req.Render.TitleEmoji == "" && !req.Render.HideEmoji {
+		req.Render.TitleEmoji = s.theme.PriorityEmoji(req.Priority)
+	}
+	req.SubmittedAt = time.Now().In(s.location(req.ChatID))
+	if req.Deadline.IsZero() {
+		req.Deadline = req.SubmittedAt.Add(timeout)
+	} else {
+		// Absolute deadline keeps the answer window of retried requests instead of restarting it.
+		req.Deadline = req.Deadline.In(req.SubmittedAt.Location())
+		timeout = req.Deadline.Sub(req.SubmittedAt)
+	}
+	// Keyboard limits are checked up front so a bad request fails here instead of in the Telegram API.
+	if _, err := render.Keyboard(s.requestMessages(req), req); err != nil {
+		return executions.Result{Status: executions.StatusError, Output: executions.Error{Message: err.Error()}}, err
+	}
+	var maxPending int
+	if tenant, ok := s.tenants.Get(req.Tenant); ok {
+		maxPending = tenant.MaxPending
+	}
+	exec, err := s.registry.AddLimited(req, maxPending)
+	if errors.Is(err, executions.ErrPendingLimit) {
+		return executions.Result{Status: executions.StatusError, Output: executions.Error{Message: err.Error()}}, err
+	}
 	if err != nil {
-		return executions.Result{Status: executions.StatusError, Output: "execution already exists"}, nil
+		return executions.Result{Status: executions.StatusError, Output: executions.Error{Message: "execution already exists"}}, nil
 	}
+	s.bus.Emit(events.New(events.TypeExecutionSubmitted, req.CorrelationID, req.Tool.Name, exec.CreatedAt))
 
-	messageText := s.renderMessage(req)
-	keyboard := s.optionsKeyboard(req)
-	parseMode := parseMode(req.Markup)
+	fitted, message, details, attach := s.fitPrompt(req)
+	keyboard := s.promptKeyboard(fitted, exec.WebAppToken)
 
-	msg, err := s.bot.SendMessage(ctx, &telego.SendMessageParams{
-		ChatID:      tu.ID(s.chatID),
-		Text:        messageText,
-		ParseMode:   parseMode,
+	msg, err := s.sendWithRetry(ctx, req.CorrelationID, &telego.SendMessageParams{
+		ChatID:      tu.ID(req.ChatID),
+		Text:        message.Text,
+		ParseMode:   shared.ParseMode(req.Markup),
+		Entities:    message.Entities,
 		ReplyMarkup: keyboard,
 	})
 	if err != nil {
-		s.log.Error("Failed to send telegram message", "error", err)
-		return executions.Result{Status: executions.StatusError, Output: "failed to send telegram message"}, err
+		// Nothing was posted: drop the execution so the correlation id can be submitted again.
+		s.registry.Resolve(req.CorrelationID)
+		s.log.ErrorContext(ctx, "Failed to send telegram message", "error", err, "correlation_id", req.CorrelationID)
+		s.reportTelegramError(ctx, err, "send_message", req.CorrelationID)
+		return executions.Result{Status: executions.StatusError, Output: executions.Error{Message: "failed to send telegram message"}}, err
 	}
 
-	s.registry.SetMessage(req.CorrelationID, msg.MessageID, messageText)
-	s.scheduleTimeout(req.CorrelationID, timeout, timeoutMessage)
-	return executions.Result{Status: executions.StatusPending, Output: "queued"}, nil
-}
-
-func (s *Service) renderMessage(req executions.Request) string {
-	msg := s.messagesFor(req.Lang)
-	switch strings.ToLower(strings.TrimSpace(req.Markup)) {
-	case "html":
-		return renderHTML(msg, req)
-	default:
-		return renderMarkdown(msg, req)
-	}
-}
-
-func (s *Service) optionsKeyboard(req executions.Request) *telego.InlineKeyboardMarkup {
-	msg := s.messagesFor(req.Lang)
-	rows := make([][]telego.InlineKeyboardButton, 0, len(req.Options)+1)
-	for idx, option := range req.Options {
-		payload := fmt.Sprintf("%s|%d", req.CorrelationID, idx)
-		label := fmt.Sprintf("%d. %s", idx+1, shortenButtonLabel(option, 42))
-		rows = append(rows, tu.InlineKeyboardRow(
-			tu.InlineKeyboardButton(label).WithCallbackData(handlers.CallbackData(handlers.ActionOption, payload)),
-		))
-	}
-	if req.AllowCustom {
-		customLabel := strings.TrimSpace(msg.CustomOptionButton)
-		if customLabel == "" {
-			customLabel = "Custom option"
-		}
-		rows = append(rows, tu.InlineKeyboardRow(
-			tu.InlineKeyboardButton(customLabel).WithCallbackData(handlers.CallbackData(handlers.ActionCustom, req.CorrelationID)),
-		))
+	s.registry.SetMessage(req.CorrelationID, msg.MessageID, message.Text, message.Entities)
+	if details.Text != "" {
+		s.registry.SetDetails(req.CorrelationID, details.Text, details.Entities)
 	}
-	return tu.InlineKeyboard(rows...)
-}
-
-func shortenButtonLabel(value string, maxRunes int) string {
-	value = strings.TrimSpace(value)
-	if value == "" {
-		return "-"
+	if req.AnswerMode == executions.AnswerModePoll {
+		s.sendPoll(ctx, req, msg.MessageID)
 	}
-	if maxRunes <= 0 {
-		return value
+	if attach {
+		s.sendArgumentsDocument(ctx, req, msg.MessageID)
 	}
-	runes := []rune(value)
-	if len(runes) <= maxRunes {
-		return value
+	if req.Render.AttachDiffs {
+		s.sendDiffDocuments(ctx, req, msg.MessageID)
 	}
-	if maxRunes <= 1 {
-		return string(runes[:maxRunes])
+	if len(req.FullTexts) > 0 {
+		s.sendFullTextDocuments(ctx, req, msg.MessageID)
 	}
-	if maxRunes <= 3 {
-		return string(runes[:maxRunes])
+	if req.Priority == executions.PriorityUrgent && s.cfg.PinUrgent {
+		s.pinPrompt(ctx, req, msg.MessageID)
 	}
-	return string(runes[:maxRunes-3]) + "..."
+	sent := events.New(events.TypePromptSent, req.CorrelationID, req.Tool.Name, exec.CreatedAt)
+	sent.MessageID = msg.MessageID
+	s.bus.Emit(sent)
+	s.handler.NotifyCallback(ctx, s.registry.Get(req.CorrelationID), executions.CallbackEventPromptSent, "")
+	s.scheduleTimeout(req.CorrelationID, timeout, timeoutMessage)
+	return executions.Result{Status: executions.StatusPending, Output: executions.Queued{}}, nil
 }
 
-func (s *Service) scheduleTimeout(correlationID string, timeout time.Duration, timeoutMessage string) {
-	go func() {
-		timer := time.NewTimer(timeout)
-		defer timer.Stop()
-		<-timer.C
-		exec, promptID, ok := s.registry.Resolve(correlationID)
-		if !ok {
-			return
-		}
-		if promptID > 0 {
-			_ = s.handler.DeleteMessage(context.Background(), promptID)
+// promptKeyboard builds the answer keyboard of a prompt for its answer mode.
+func (s *Service) promptKeyboard(req executions.Request, webAppToken string) telego.ReplyMarkup {
+	switch req.AnswerMode {
+	case executions.AnswerModeReplyKeyboard:
+		return s.replyKeyboard(req, webAppToken)
+	case executions.AnswerModePoll:
+		// Options are voted in the poll; prompt keeps only custom/details buttons.
+		buttons := req
+		buttons.Options = nil
+		if extra := s.optionsKeyboard(buttons); len(extra.InlineKeyboard) > 0 {
+			return extra
 		}
-		s.handler.FinalizeExecution(context.Background(), exec, executions.Result{
-			Status: executions.StatusError,
-			Output: timeoutResult,
-		}, timeoutMessage)
-	}()
+		return nil
+	default:
+		return s.optionsKeyboard(req)
+	}
 }
 
-func (s *Service) messagesFor(lang string) i18n.Messages {
-	return shared.MessagesFor(s.messages, lang, s.lang)
+// PromptKeyboard renders the answer keyboard of a pending execution for re-sending its prompt.
+func (s *Service) PromptKeyboard(exec *executions.Execution) telego.ReplyMarkup {
+	fitted, _, _, _ := s.fitPrompt(exec.Request)
+	return s.promptKeyboard(fitted, exec.WebAppToken)
 }
 
-func parseMode(markup string) string {
-	switch strings.ToLower(strings.TrimSpace(markup)) {
-	case "html":
-		return telego.ModeHTML
-	default:
-		return telego.ModeMarkdownV2
-	}
+// QuickAnswerKeyboard renders the option buttons of a pending execution without details and custom answer buttons.
+func (s *Service) QuickAnswerKeyboard(exec *executions.Execution) *telego.InlineKeyboardMarkup {
+	req := exec.Request
+	req.Render.CollapseParams = false
+	req.AllowCustom = false
+	return s.optionsKeyboard(req)
+}
+
+// Bump re-sends the pending prompt of the tenant at the bottom of its chat.
+func (s *Service) Bump(ctx context.Context, tenant, correlationID string) error {
+	return s.handler.Bump(context.WithoutCancel(ctx), executions.NamespacedID(tenant, correlationID))
 }
 
-func renderMarkdown(msg i18n.Messages, req executions.Request) string {
-	return renderExecution(msg, req, markdownExecutionWriter{})
+// Redeliver re-sends the retained callback of the tenant's resolved execution.
+func (s *Service) Redeliver(ctx context.Context, tenant, correlationID string) error {
+	correlationID = executions.NamespacedID(tenant, correlationID)
+	return s.handler.Redeliver(applog.WithAttrs(ctx, "correlation_id", correlationID), correlationID)
 }
 
-func renderHTML(msg i18n.Messages, req executions.Request) string {
-	return renderExecution(msg, req, htmlExecutionWriter{})
+// Cancel cancels the tenant's pending execution on behalf of an operator.
+func (s *Service) Cancel(ctx context.Context, tenant, correlationID string) error {
+	correlationID = executions.NamespacedID(tenant, correlationID)
+	return s.handler.Cancel(applog.WithAttrs(context.WithoutCancel(ctx), "correlation_id", correlationID), correlationID)
 }
 
-func renderExecution(msg i18n.Messages, req executions.Request, writer executionMessageWriter) string {
-	labels := executionLabelsFor(msg)
-	builder := &strings.Builder{}
-	writer.WriteTitle(builder, msg.ExecutionTitle)
+// ForceResolve resolves the tenant's pending execution with the operator's answer.
+func (s *Service) ForceResolve(ctx context.Context, tenant, correlationID, answer string) error {
+	correlationID = executions.NamespacedID(tenant, correlationID)
+	return s.handler.ForceResolve(applog.WithAttrs(context.WithoutCancel(ctx), "correlation_id", correlationID), correlationID, answer)
+}
 
-	writer.WriteSectionHeader(builder, labels.ContextTitle)
-	writer.WriteLabelValue(builder, labels.QuestionLabel, req.Question, false)
+// CancelGroup cancels pending executions of the tenant group and returns their number.
+func (s *Service) CancelGroup(ctx context.Context, tenant, groupID string) int {
+	return s.handler.CancelGroup(context.WithoutCancel(ctx), tenant, groupID)
+}
 
-	if strings.TrimSpace(req.Context) != "" {
-		writer.WriteLabelValue(builder, labels.ContextLabel, req.Context, false)
+// renderMessages renders prompt text and, for collapsed params, the expanded details text.
+func (s *Service) renderMessages(req executions.Request) (render.Text, render.Text) {
+	if !req.Render.CollapseParams {
+		return s.renderMessage(req), render.Text{}
 	}
+	compact, details := req, req
+	compact.Render.Sections = render.WithoutSection(req.Render.Sections, executions.SectionParams)
+	details.Render.Sections = render.WithSection(req.Render.Sections, executions.SectionParams, executions.SectionAction)
+	return s.renderMessage(compact), s.renderMessage(details)
+}
 
-	writer.WriteOptions(builder, labels.OptionsLabel, req.Options)
-
-	writer.WriteSectionHeader(builder, labels.ActionTitle)
-	writer.WriteCodeValue(builder, msg.ExecutionTool, req.Tool.Name, false)
-	writer.WriteCodeValue(builder, msg.ExecutionCorrelation, req.CorrelationID, false)
-	return builder.String()
+func (s *Service) renderMessage(req executions.Request) render.Text {
+	msg := s.requestMessages(req)
+	if req.Markup == executions.MarkupEntities {
+		return render.Prompt(msg, req)
+	}
+	name := req.Tool.Name
+	if req.Template != "" {
+		name = req.Template
+	}
+	if tmpl := s.templates.Lookup(name, req.Markup); tmpl != nil {
+		text, err := renderTemplate(tmpl, msg, req)
+		if err == nil {
+			return render.Text{Text: text}
+		}
+		s.log.Error("Failed to render message template, using built-in layout", "error", err, "template", tmpl.Name(), "correlation_id", req.CorrelationID)
+	}
+	return render.Prompt(msg, req)
 }
 
-type executionMessageWriter interface {
-	WriteTitle(builder *strings.Builder, title string)
-	WriteSectionHeader(builder *strings.Builder, title string)
-	WriteLabelValue(builder *strings.Builder, label, value string, addEmptyLine bool)
-	WriteOptions(builder *strings.Builder, label string, options []string)
-	WriteCodeValue(builder *strings.Builder, label, value string, addEmptyLine bool)
+func fallbackText(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
 }
 
-type markdownExecutionWriter struct{}
+// optionsKeyboard renders the inline keyboard of a request already checked by SubmitExecution.
+func (s *Service) optionsKeyboard(req executions.Request) *telego.InlineKeyboardMarkup {
+	keyboard, err := render.Keyboard(s.requestMessages(req), req)
+	if err != nil {
+		return tu.InlineKeyboard()
+	}
+	return keyboard
+}
 
-func (markdownExecutionWriter) WriteTitle(builder *strings.Builder, title string) {
-	builder.WriteString("*")
-	builder.WriteString(shared.EscapeMarkdownV2(title))
-	builder.WriteString("*\n\n")
+// replyKeyboard presents options as a one-time reply keyboard with the Mini App form button of the execution.
+func (s *Service) replyKeyboard(req executions.Request, webAppToken string) *telego.ReplyKeyboardMarkup {
+	formURL := ""
+	if webAppToken != "" {
+		formURL = s.cfg.WebAppFormURL(webAppToken)
+	}
+	return render.ReplyKeyboard(s.requestMessages(req), req, formURL)
 }
 
-func (markdownExecutionWriter) WriteSectionHeader(builder *strings.Builder, title string) {
-	builder.WriteString("*")
-	builder.WriteString(shared.EscapeMarkdownV2(title))
-	builder.WriteString("*\n")
+// scheduleTimeout expires the execution after timeout. The timers are kept in the registry, which stops them
+// when the execution resolves earlier, and stop when shutdown begins; restored executions get new ones after restart.
+func (s *Service) scheduleTimeout(correlationID string, timeout time.Duration, timeoutMessage string) {
+	ctx := s.handler.Stopping()
+	var warning *time.Timer
+	if delay, ok := s.timeoutWarningDelay(correlationID); ok {
+		warning = time.AfterFunc(delay, func() {
+			defer s.recoverTimeout(ctx, correlationID)
+			s.handler.NotifyCallback(ctx, s.registry.Get(correlationID), executions.CallbackEventTimeoutWarning, "")
+		})
+	}
+	deadline := time.AfterFunc(timeout, func() {
+		s.handler.Go(ctx, func(ctx context.Context) {
+			defer s.recoverTimeout(ctx, correlationID)
+			s.expire(ctx, correlationID, timeoutMessage, "")
+		})
+	})
+	stopOnShutdown := context.AfterFunc(ctx, func() { stopTimers(deadline, warning) })
+	stop := func() {
+		stopOnShutdown()
+		stopTimers(deadline, warning)
+	}
+	if !s.registry.SetTimeout(correlationID, stop) {
+		// Resolved before the timers were set.
+		stop()
+	}
 }
 
-func (markdownExecutionWriter) WriteLabelValue(builder *strings.Builder, label, value string, addEmptyLine bool) {
-	builder.WriteString("*")
-	builder.WriteString(shared.EscapeMarkdownV2(label))
-	builder.WriteString(":* ")
-	builder.WriteString(shared.EscapeMarkdownV2(value))
-	builder.WriteString("\n")
-	appendOptionalLineBreak(builder, "\n", addEmptyLine)
+func stopTimers(timers ...*time.Timer) {
+	for _, timer := range timers {
+		if timer != nil {
+			timer.Stop()
+		}
+	}
 }
 
-func (markdownExecutionWriter) WriteOptions(builder *strings.Builder, label string, options []string) {
-	builder.WriteString("*")
-	builder.WriteString(shared.EscapeMarkdownV2(label))
-	builder.WriteString(":*\n")
-	for idx, option := range options {
-		builder.WriteString(fmt.Sprintf("%d\\) %s\n", idx+1, shared.EscapeMarkdownV2(option)))
+// recoverTimeout reports a panic of a timeout timer; defer it in timer functions.
+func (s *Service) recoverTimeout(ctx context.Context, correlationID string) {
+	if recovered := recover(); recovered != nil {
+		err := reporting.PanicError(recovered)
+		s.log.Error("Panic in execution timeout", "error", err, "correlation_id", correlationID)
+		s.reporter.Report(context.WithoutCancel(ctx), err, reporting.Tags(
+			reporting.TagComponent, "telegram",
+			reporting.TagOperation, "timeout",
+			reporting.TagCorrelationID, correlationID,
+		))
 	}
-	builder.WriteString("\n")
 }
 
-func (markdownExecutionWriter) WriteCodeValue(builder *strings.Builder, label, value string, addEmptyLine bool) {
-	builder.WriteString("*")
-	builder.WriteString(shared.EscapeMarkdownV2(label))
-	builder.WriteString(":* `")
-	builder.WriteString(shared.EscapeMarkdownV2Code(value))
-	builder.WriteString("`\n")
-	appendOptionalLineBreak(builder, "\n", addEmptyLine)
+// timeoutWarningDelay returns when the timeout_warning callback is due: after 80% of the time between submission
+// and deadline. It reports false when the callback is not requested or the moment has passed.
+func (s *Service) timeoutWarningDelay(correlationID string) (time.Duration, bool) {
+	exec := s.registry.Get(correlationID)
+	if exec == nil || !exec.Request.Callback.Notifies(executions.CallbackEventTimeoutWarning) {
+		return 0, false
+	}
+	req := exec.Request
+	delay := time.Until(req.SubmittedAt.Add(req.Deadline.Sub(req.SubmittedAt) * 8 / 10))
+	return delay, delay > 0
 }
 
-type htmlExecutionWriter struct{}
+// expire resolves the pending execution with the timeout result.
+func (s *Service) expire(ctx context.Context, correlationID, timeoutMessage, callbackNote string) {
+	exec, promptID, ok := s.registry.Resolve(correlationID)
+	if !ok {
+		return
+	}
+	ctx = handlers.WithExecution(ctx, exec)
+	if promptID > 0 {
+		_ = s.handler.DeleteMessage(ctx, promptID)
+	}
+	timedOut := events.New(events.TypeTimedOut, correlationID, exec.Request.Tool.Name, exec.CreatedAt)
+	timedOut.MessageID = exec.MessageID
+	s.bus.Emit(timedOut)
+	s.handler.FinalizeExecution(ctx, exec, executions.Result{
+		Status:       executions.StatusTimeout,
+		Output:       executions.Timeout{},
+		CallbackNote: callbackNote,
+	}, timeoutMessage)
+}
 
-func (htmlExecutionWriter) WriteTitle(builder *strings.Builder, title string) {
-	builder.WriteString("<b>")
-	builder.WriteString(shared.EscapeHTML(title))
-	builder.WriteString("</b><br><br>")
+func (s *Service) reportTelegramError(ctx context.Context, err error, operation, correlationID string) {
+	s.handler.RecordTelegramError(err)
+	s.reporter.Report(ctx, err, reporting.Tags(
+		reporting.TagComponent, "telegram",
+		reporting.TagOperation, operation,
+		reporting.TagCorrelationID, correlationID,
+	))
 }
 
-func (htmlExecutionWriter) WriteSectionHeader(builder *strings.Builder, title string) {
-	builder.WriteString("<b>")
-	builder.WriteString(shared.EscapeHTML(title))
-	builder.WriteString("</b><br>")
+func (s *Service) messagesFor(lang string) i18n.Messages {
+	return shared.MessagesFor(s.messages, lang, s.lang)
 }
 
-func (htmlExecutionWriter) WriteLabelValue(builder *strings.Builder, label, value string, addEmptyLine bool) {
-	builder.WriteString("<b>")
-	builder.WriteString(shared.EscapeHTML(label))
-	builder.WriteString(":</b> ")
-	builder.WriteString(shared.EscapeHTML(value))
-	builder.WriteString("<br>")
-	appendOptionalLineBreak(builder, "<br>", addEmptyLine)
+// requestMessages returns messages in request language with spec.strings overrides.
+func (s *Service) requestMessages(req executions.Request) i18n.Messages {
+	return s.messagesFor(req.Lang).WithOverrides(req.Strings)
 }
 
-func (htmlExecutionWriter) WriteOptions(builder *strings.Builder, label string, options []string) {
-	builder.WriteString("<b>")
-	builder.WriteString(shared.EscapeHTML(label))
-	builder.WriteString(":</b><br>")
-	for idx, option := range options {
-		builder.WriteString(fmt.Sprintf("%d) %s<br>", idx+1, shared.EscapeHTML(option)))
+// DefaultLang returns chat language preference set with /lang, or the configured language.
+func (s *Service) DefaultLang() string {
+	if lang := s.state.ChatLang(s.state.MigratedChat(s.chatID)); lang != "" && s.HasLang(lang) {
+		return lang
 	}
-	builder.WriteString("<br>")
+	return s.lang
 }
 
-func (htmlExecutionWriter) WriteCodeValue(builder *strings.Builder, label, value string, addEmptyLine bool) {
-	builder.WriteString("<b>")
-	builder.WriteString(shared.EscapeHTML(label))
-	builder.WriteString(":</b> <code>")
-	builder.WriteString(shared.EscapeHTML(value))
-	builder.WriteString("</code><br>")
-	appendOptionalLineBreak(builder, "<br>", addEmptyLine)
+func themeFromConfig(cfg config.ThemeConfig) shared.Theme {
+	return shared.Theme{
+		Success: cfg.Success,
+		Error:   cfg.Error,
+		Timeout: cfg.Timeout,
+		Priority: map[string]string{
+			executions.PriorityLow:    cfg.PriorityLow,
+			executions.PriorityNormal: cfg.PriorityNormal,
+			executions.PriorityHigh:   cfg.PriorityHigh,
+			executions.PriorityUrgent: cfg.PriorityUrgent,
+		},
+	}
 }
 
-func appendOptionalLineBreak(builder *strings.Builder, lineBreak string, enabled bool) {
-	if enabled {
-		builder.WriteString(lineBreak)
+// location returns display timezone: chat preference set with /tz, or the configured one.
+func (s *Service) location(chatID int64) *time.Location {
+	name := s.state.ChatTimezone(chatID)
+	if name == "" {
+		name = s.cfg.Timezone
 	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
 }
 
-type executionLabels struct {
-	ContextTitle  string
-	ActionTitle   string
-	QuestionLabel string
-	ContextLabel  string
-	OptionsLabel  string
+// HasLang reports whether a locale is available for the language.
+func (s *Service) HasLang(lang string) bool {
+	_, ok := s.messages[lang]
+	return ok
 }
 
-func executionLabelsFor(msg i18n.Messages) executionLabels {
-	return executionLabels{
-		ContextTitle:  fallbackText(msg.SectionContext, "Context"),
-		ActionTitle:   fallbackText(msg.SectionAction, "Action"),
-		QuestionLabel: fallbackText(msg.QuestionLabel, "Question"),
-		ContextLabel:  fallbackText(msg.ContextLabel, "Context"),
-		OptionsLabel:  fallbackText(msg.OptionsLabel, "Options"),
-	}
+// OutboundQueue returns the queue of chat-bound Telegram calls.
+func (s *Service) OutboundQueue() *outbound.Queue {
+	return s.bot.Queue()
 }
 
-func fallbackText(value, fallback string) string {
-	if strings.TrimSpace(value) == "" {
-		return fallback
-	}
-	return value
+// Messages returns localized strings for the language with fallback to the configured default.
+func (s *Service) Messages(lang string) i18n.Messages {
+	return s.messagesFor(lang)
 }
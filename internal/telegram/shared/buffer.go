@@ -0,0 +1,25 @@
+package shared
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer keeps buffers grown by unusually large messages out of the pool.
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// GetBuffer returns an empty buffer from the pool; return it with PutBuffer once its contents are copied out.
+func GetBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// PutBuffer resets the buffer and returns it to the pool.
+func PutBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
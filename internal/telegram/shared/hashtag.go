@@ -0,0 +1,48 @@
+package shared
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Hashtag turns value into a Telegram hashtag ("deploy-api v2" -> "#deploy_api_v2"): characters other than
+// letters and digits become underscores. It returns "" when nothing searchable is left.
+func Hashtag(value string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "#")) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+			underscore = false
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	tag := strings.TrimRight(b.String(), "_")
+	// Telegram does not link hashtags made of digits only.
+	if strings.IndexFunc(tag, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+		return ""
+	}
+	return "#" + tag
+}
+
+// Hashtags converts values into unique hashtags, skipping values that give none.
+func Hashtags(values ...string) []string {
+	out := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		tag := Hashtag(value)
+		if tag == "" {
+			continue
+		}
+		if _, dup := seen[tag]; dup {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	return out
+}
@@ -0,0 +1,300 @@
+package shared
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	"github.com/mymmrac/telego"
+)
+
+// Span is a fragment of user-supplied text; Type is a telego.EntityType* constant or empty for plain text.
+type Span struct {
+	Text     string
+	Type     string
+	Language string
+	URL      string
+}
+
+// emphasis maps inline Markdown delimiters to entity types; longer delimiters are tried first.
+var emphasis = []struct {
+	delimiter string
+	entity    string
+}{
+	{"**", telego.EntityTypeBold},
+	{"__", telego.EntityTypeBold},
+	{"~~", telego.EntityTypeStrikethrough},
+	{"*", telego.EntityTypeItalic},
+	{"_", telego.EntityTypeItalic},
+}
+
+// ParseMarkdown splits user-supplied text with common Markdown into spans: **bold**, __bold__, *italic*,
+// _italic_, ~~strikethrough~~, `code`, ```lang code blocks``` and [text](https://link). Constructs do not nest
+// and unmatched or intraword delimiters (snake_case, 2*3*4) stay literal.
+func ParseMarkdown(value string) []Span {
+	runes := []rune(value)
+	var spans []Span
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() > 0 {
+			spans = append(spans, Span{Text: plain.String()})
+			plain.Reset()
+		}
+	}
+	for idx := 0; idx < len(runes); {
+		span, next, ok := parseMarkdownAt(runes, idx)
+		if !ok {
+			plain.WriteRune(runes[idx])
+			idx++
+			continue
+		}
+		flush()
+		spans = append(spans, span)
+		idx = next
+	}
+	flush()
+	return spans
+}
+
+// parseMarkdownAt parses a construct starting at idx and returns it with the index after it.
+func parseMarkdownAt(runes []rune, idx int) (Span, int, bool) {
+	switch {
+	case hasPrefixAt(runes, idx, "```"):
+		end := indexAt(runes, idx+3, "```")
+		if end < 0 {
+			return Span{}, 0, false
+		}
+		body := string(runes[idx+3 : end])
+		language := ""
+		if first, rest, ok := strings.Cut(body, "\n"); ok && !strings.ContainsFunc(first, unicode.IsSpace) {
+			language, body = first, rest
+		}
+		body = strings.TrimSuffix(strings.TrimPrefix(body, "\n"), "\n")
+		if body == "" {
+			return Span{}, 0, false
+		}
+		return Span{Text: body, Type: telego.EntityTypePre, Language: language}, end + 3, true
+	case runes[idx] == '`':
+		end := indexAt(runes, idx+1, "`")
+		if end <= idx+1 {
+			return Span{}, 0, false
+		}
+		return Span{Text: string(runes[idx+1 : end]), Type: telego.EntityTypeCode}, end + 1, true
+	case runes[idx] == '[':
+		return parseLinkAt(runes, idx)
+	}
+	for _, item := range emphasis {
+		if !hasPrefixAt(runes, idx, item.delimiter) || idx > 0 && isWordRune(runes[idx-1]) {
+			continue
+		}
+		size := len([]rune(item.delimiter))
+		start := idx + size
+		if start >= len(runes) || unicode.IsSpace(runes[start]) {
+			continue
+		}
+		for end := indexAt(runes, start+1, item.delimiter); end > 0; end = indexAt(runes, end+1, item.delimiter) {
+			after := end + size
+			if unicode.IsSpace(runes[end-1]) || after < len(runes) && (isWordRune(runes[after]) || hasPrefixAt(runes, after, item.delimiter[:1])) {
+				continue
+			}
+			return Span{Text: string(runes[start:end]), Type: item.entity}, after, true
+		}
+	}
+	return Span{}, 0, false
+}
+
+// parseLinkAt parses [text](url) with an http(s) URL.
+func parseLinkAt(runes []rune, idx int) (Span, int, bool) {
+	closing := indexAt(runes, idx+1, "](")
+	if closing <= idx+1 {
+		return Span{}, 0, false
+	}
+	end := indexAt(runes, closing+2, ")")
+	if end < 0 {
+		return Span{}, 0, false
+	}
+	text, url := string(runes[idx+1:closing]), string(runes[closing+2:end])
+	if strings.Contains(text, "\n") || strings.ContainsFunc(url, unicode.IsSpace) ||
+		!strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return Span{}, 0, false
+	}
+	return Span{Text: text, Type: telego.EntityTypeTextLink, URL: url}, end + 1, true
+}
+
+func hasPrefixAt(runes []rune, idx int, prefix string) bool {
+	for _, r := range prefix {
+		if idx >= len(runes) || runes[idx] != r {
+			return false
+		}
+		idx++
+	}
+	return true
+}
+
+// indexAt returns the index of the first occurrence of substr at or after from, or -1.
+func indexAt(runes []rune, from int, substr string) int {
+	for idx := from; idx < len(runes); idx++ {
+		if hasPrefixAt(runes, idx, substr) {
+			return idx
+		}
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// SpansToMode renders spans for the Telegram parse mode; without a parse mode formatting is dropped.
+func SpansToMode(mode string, spans []Span) string {
+	switch mode {
+	case "":
+		text, _ := SpansToEntities(spans, 0)
+		return text
+	case telego.ModeHTML:
+		return SpansToHTML(spans)
+	case telego.ModeMarkdown:
+		return SpansToMarkdownV1(spans)
+	default:
+		return SpansToMarkdownV2(spans)
+	}
+}
+
+// SpansToMarkdownV2 renders spans as Telegram MarkdownV2 with escaping.
+func SpansToMarkdownV2(spans []Span) string {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	WriteSpansMarkdownV2(buf, spans)
+	return buf.String()
+}
+
+// WriteSpansMarkdownV2 writes spans as Telegram MarkdownV2 with escaping.
+func WriteSpansMarkdownV2(buf *bytes.Buffer, spans []Span) {
+	for idx, span := range spans {
+		switch span.Type {
+		case telego.EntityTypeBold:
+			writeWrapped(buf, "*", span.Text, "*", WriteMarkdownV2)
+		case telego.EntityTypeItalic:
+			writeWrapped(buf, "_", span.Text, "_", WriteMarkdownV2)
+			if idx+1 < len(spans) && spans[idx+1].Type == telego.EntityTypeItalic {
+				// Telegram ignores "\r"; it keeps adjacent "_" delimiters from being read as underline "__".
+				buf.WriteString("\r")
+			}
+		case telego.EntityTypeStrikethrough:
+			writeWrapped(buf, "~", span.Text, "~", WriteMarkdownV2)
+		case telego.EntityTypeCode:
+			writeWrapped(buf, "`", span.Text, "`", WriteMarkdownV2Code)
+		case telego.EntityTypePre:
+			buf.WriteString("```")
+			buf.WriteString(span.Language)
+			writeWrapped(buf, "\n", span.Text, "\n```", WriteMarkdownV2Code)
+		case telego.EntityTypeTextLink:
+			writeWrapped(buf, "[", span.Text, "](", WriteMarkdownV2)
+			writeWithSet(buf, span.URL, &markdownV2URLEscapes)
+			buf.WriteString(")")
+		default:
+			WriteMarkdownV2(buf, span.Text)
+		}
+	}
+}
+
+// SpansToMarkdownV1 renders spans as legacy Telegram Markdown; strikethrough is not supported there and stays plain.
+func SpansToMarkdownV1(spans []Span) string {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	WriteSpansMarkdownV1(buf, spans)
+	return buf.String()
+}
+
+// WriteSpansMarkdownV1 writes spans as legacy Telegram Markdown.
+func WriteSpansMarkdownV1(buf *bytes.Buffer, spans []Span) {
+	for _, span := range spans {
+		switch span.Type {
+		case telego.EntityTypeBold:
+			writeWrapped(buf, "*", StripMarkdownV1Entity(span.Text, "*"), "*", nil)
+		case telego.EntityTypeItalic:
+			writeWrapped(buf, "_", StripMarkdownV1Entity(span.Text, "_"), "_", nil)
+		case telego.EntityTypeCode:
+			writeWrapped(buf, "`", StripMarkdownV1Entity(span.Text, "`"), "`", nil)
+		case telego.EntityTypePre:
+			buf.WriteString("```")
+			buf.WriteString(span.Language)
+			writeWrapped(buf, "\n", StripMarkdownV1Entity(span.Text, "```"), "\n```", nil)
+		case telego.EntityTypeTextLink:
+			writeWrapped(buf, "[", StripMarkdownV1Entity(span.Text, "]"), "](", nil)
+			buf.WriteString(span.URL)
+			buf.WriteString(")")
+		default:
+			WriteMarkdownV1(buf, span.Text)
+		}
+	}
+}
+
+// SpansToHTML renders spans as Telegram HTML with escaping.
+func SpansToHTML(spans []Span) string {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	WriteSpansHTML(buf, spans)
+	return buf.String()
+}
+
+// WriteSpansHTML writes spans as Telegram HTML with escaping.
+func WriteSpansHTML(buf *bytes.Buffer, spans []Span) {
+	for _, span := range spans {
+		switch span.Type {
+		case telego.EntityTypeBold:
+			writeWrapped(buf, "<b>", span.Text, "</b>", WriteHTML)
+		case telego.EntityTypeItalic:
+			writeWrapped(buf, "<i>", span.Text, "</i>", WriteHTML)
+		case telego.EntityTypeStrikethrough:
+			writeWrapped(buf, "<s>", span.Text, "</s>", WriteHTML)
+		case telego.EntityTypeCode:
+			writeWrapped(buf, "<code>", span.Text, "</code>", WriteHTML)
+		case telego.EntityTypePre:
+			if span.Language == "" {
+				writeWrapped(buf, "<pre>", span.Text, "</pre>", WriteHTML)
+			} else {
+				writeWrapped(buf, `<pre><code class="language-`, span.Language, `">`, WriteHTML)
+				writeWrapped(buf, "", span.Text, "</code></pre>", WriteHTML)
+			}
+		case telego.EntityTypeTextLink:
+			writeWrapped(buf, `<a href="`, span.URL, `">`, WriteHTML)
+			writeWrapped(buf, "", span.Text, "</a>", WriteHTML)
+		default:
+			WriteHTML(buf, span.Text)
+		}
+	}
+}
+
+// writeWrapped writes text between the delimiters, escaping it with escape unless it is nil.
+func writeWrapped(buf *bytes.Buffer, before, text, after string, escape func(*bytes.Buffer, string)) {
+	buf.WriteString(before)
+	if escape == nil {
+		buf.WriteString(text)
+	} else {
+		escape(buf, text)
+	}
+	buf.WriteString(after)
+}
+
+// SpansToEntities returns plain text of spans and their entities; offset is the UTF-16 position of the text
+// in the message.
+func SpansToEntities(spans []Span, offset int) (string, []telego.MessageEntity) {
+	var builder strings.Builder
+	var entities []telego.MessageEntity
+	for _, span := range spans {
+		length := TextLength(span.Text)
+		if span.Type != "" {
+			entities = append(entities, telego.MessageEntity{
+				Type:     span.Type,
+				Offset:   offset,
+				Length:   length,
+				URL:      span.URL,
+				Language: span.Language,
+			})
+		}
+		builder.WriteString(span.Text)
+		offset += length
+	}
+	return builder.String(), entities
+}
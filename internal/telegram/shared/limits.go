@@ -0,0 +1,43 @@
+package shared
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MaxMessageLength is Telegram limit for message text measured in UTF-16 code units.
+const MaxMessageLength = 4096
+
+// MaxCaptionLength is Telegram limit for media captions measured in UTF-16 code units.
+const MaxCaptionLength = 1024
+
+// MaxCallbackAnswerLength is Telegram limit for callback query answer (toast) text.
+const MaxCallbackAnswerLength = 200
+
+// TextLength returns text length as counted by Telegram (UTF-16 code units).
+func TextLength(value string) int {
+	n := 0
+	for _, r := range value {
+		// Runes decoded from a string are never surrogates, so RuneLen is 1 or 2.
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// FitsMessage reports whether text fits into a single Telegram message.
+func FitsMessage(value string) bool {
+	return TextLength(value) <= MaxMessageLength
+}
+
+// TruncateRunes shortens value to maxRunes runes, appending suffix when truncated.
+func TruncateRunes(value string, maxRunes int, suffix string) string {
+	if utf8.RuneCountInString(value) <= maxRunes {
+		return value
+	}
+	runes := []rune(value)
+	keep := maxRunes - len([]rune(suffix))
+	if keep < 0 {
+		keep = 0
+	}
+	return string(runes[:keep]) + suffix
+}
@@ -1,40 +1,172 @@
 package shared
 
-import "strings"
+import (
+	"bytes"
+	"strings"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/mymmrac/telego"
+)
+
+// htmlReplacer is built once: strings.Replacer is safe for concurrent use and returns the input unchanged
+// when there is nothing to escape.
+var htmlReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&#39;",
+)
+
+// Characters escaped with a backslash; all of them are ASCII, so escaping works on bytes without decoding runes.
+var (
+	markdownV2Escapes     = newByteSet("_*[]()~`>#+-=|{}.!\\")
+	markdownV1Escapes     = newByteSet("_*`[")
+	markdownV2CodeEscapes = newByteSet("\\`")
+	markdownV2URLEscapes  = newByteSet(`)\`)
+)
+
+// ParseMode returns the Telegram parse mode of a request markup; entities markup has none and unknown markups
+// are MarkdownV2.
+func ParseMode(markup string) string {
+	switch strings.ToLower(strings.TrimSpace(markup)) {
+	case executions.MarkupHTML:
+		return telego.ModeHTML
+	case executions.MarkupEntities:
+		return ""
+	case executions.MarkupMarkdownV1:
+		return telego.ModeMarkdown
+	default:
+		return telego.ModeMarkdownV2
+	}
+}
+
+// Escape escapes text for the Telegram parse mode; without a parse mode text is returned as is.
+func Escape(mode, value string) string {
+	switch mode {
+	case "":
+		return value
+	case telego.ModeHTML:
+		return EscapeHTML(value)
+	case telego.ModeMarkdown:
+		return EscapeMarkdownV1(value)
+	default:
+		return EscapeMarkdownV2(value)
+	}
+}
+
+// EscapeCode escapes code payload for the Telegram parse mode. Legacy Markdown cannot escape inside entities,
+// so backticks are stripped there.
+func EscapeCode(mode, value string) string {
+	switch mode {
+	case "":
+		return value
+	case telego.ModeHTML:
+		return EscapeHTML(value)
+	case telego.ModeMarkdown:
+		return StripMarkdownV1Entity(value, "`")
+	default:
+		return EscapeMarkdownV2Code(value)
+	}
+}
 
 // EscapeHTML escapes text for Telegram HTML mode.
 func EscapeHTML(value string) string {
-	replacer := strings.NewReplacer(
-		"&", "&amp;",
-		"<", "&lt;",
-		">", "&gt;",
-		`"`, "&quot;",
-		"'", "&#39;",
-	)
-	return replacer.Replace(value)
+	return htmlReplacer.Replace(value)
+}
+
+// WriteHTML writes text escaped for Telegram HTML mode.
+func WriteHTML(buf *bytes.Buffer, value string) {
+	_, _ = htmlReplacer.WriteString(buf, value)
 }
 
 // EscapeMarkdownV2 escapes text for Telegram MarkdownV2 mode.
 func EscapeMarkdownV2(value string) string {
-	return escapeWithSet(value, "_*[]()~`>#+-=|{}.!\\")
+	return escapeWithSet(value, &markdownV2Escapes)
+}
+
+// WriteMarkdownV2 writes text escaped for Telegram MarkdownV2 mode.
+func WriteMarkdownV2(buf *bytes.Buffer, value string) {
+	writeWithSet(buf, value, &markdownV2Escapes)
+}
+
+// EscapeMarkdownV1 escapes text outside of entities for legacy Telegram Markdown mode.
+func EscapeMarkdownV1(value string) string {
+	return escapeWithSet(value, &markdownV1Escapes)
+}
+
+// WriteMarkdownV1 writes text outside of entities escaped for legacy Telegram Markdown mode.
+func WriteMarkdownV1(buf *bytes.Buffer, value string) {
+	writeWithSet(buf, value, &markdownV1Escapes)
+}
+
+// StripMarkdownV1Entity removes the entity delimiter from text placed inside a legacy Markdown entity,
+// because legacy Markdown has no escaping inside entities.
+func StripMarkdownV1Entity(value, delimiter string) string {
+	return strings.ReplaceAll(value, delimiter, "")
 }
 
 // EscapeMarkdownV2Code escapes inline code payload for Telegram MarkdownV2 mode.
 func EscapeMarkdownV2Code(value string) string {
-	return escapeWithSet(value, "\\`")
+	return escapeWithSet(value, &markdownV2CodeEscapes)
 }
 
-func escapeWithSet(value, escapedRunes string) string {
-	if value == "" {
+// WriteMarkdownV2Code writes inline code payload escaped for Telegram MarkdownV2 mode.
+func WriteMarkdownV2Code(buf *bytes.Buffer, value string) {
+	writeWithSet(buf, value, &markdownV2CodeEscapes)
+}
+
+// byteSet is a lookup table of ASCII characters.
+type byteSet [256]bool
+
+func newByteSet(chars string) byteSet {
+	var set byteSet
+	for idx := 0; idx < len(chars); idx++ {
+		set[chars[idx]] = true
+	}
+	return set
+}
+
+// count returns the number of bytes of value in the set.
+func (s *byteSet) count(value string) int {
+	n := 0
+	for idx := 0; idx < len(value); idx++ {
+		if s[value[idx]] {
+			n++
+		}
+	}
+	return n
+}
+
+// escapeWithSet prefixes bytes of the set with a backslash; value is returned as is when nothing needs escaping
+// and the escaped copy is allocated once otherwise.
+func escapeWithSet(value string, set *byteSet) string {
+	escaped := set.count(value)
+	if escaped == 0 {
 		return value
 	}
 	var builder strings.Builder
-	builder.Grow(len(value) * 2)
-	for _, r := range value {
-		if strings.ContainsRune(escapedRunes, r) {
+	builder.Grow(len(value) + escaped)
+	start := 0
+	for idx := 0; idx < len(value); idx++ {
+		if set[value[idx]] {
+			builder.WriteString(value[start:idx])
 			builder.WriteByte('\\')
+			start = idx
 		}
-		builder.WriteRune(r)
 	}
+	builder.WriteString(value[start:])
 	return builder.String()
 }
+
+func writeWithSet(buf *bytes.Buffer, value string, set *byteSet) {
+	start := 0
+	for idx := 0; idx < len(value); idx++ {
+		if set[value[idx]] {
+			buf.WriteString(value[start:idx])
+			buf.WriteByte('\\')
+			start = idx
+		}
+	}
+	buf.WriteString(value[start:])
+}
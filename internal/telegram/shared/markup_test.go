@@ -0,0 +1,144 @@
+package shared
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mymmrac/telego"
+)
+
+// specialCharacters are Telegram's documented special characters: MarkdownV2 reserves all of them but the HTML ones
+// outside entities, legacy Markdown only its entity delimiters and HTML the characters of its syntax.
+const specialCharacters = "_*[]()~`>#+-=|{}.!\\<&\"'"
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		value string
+		want  string
+	}{
+		{name: "markdown special", mode: telego.ModeMarkdownV2, value: specialCharacters,
+			want: "\\_\\*\\[\\]\\(\\)\\~\\`\\>\\#\\+\\-\\=\\|\\{\\}\\.\\!\\\\<&\"'"},
+		{name: "markdown text", mode: telego.ModeMarkdownV2, value: "v1.2 (beta) costs 5$ - ok!",
+			want: "v1\\.2 \\(beta\\) costs 5$ \\- ok\\!"},
+		{name: "markdown plain", mode: telego.ModeMarkdownV2, value: "Roll out 🚀 now", want: "Roll out 🚀 now"},
+		{name: "markdown_v1 special", mode: telego.ModeMarkdown, value: specialCharacters,
+			want: "\\_\\*\\[]()~\\`>#+-=|{}.!\\<&\"'"},
+		{name: "markdown_v1 text", mode: telego.ModeMarkdown, value: "snake_case *bold* [link]",
+			want: "snake\\_case \\*bold\\* \\[link]"},
+		{name: "html special", mode: telego.ModeHTML, value: specialCharacters,
+			want: "_*[]()~`&gt;#+-=|{}.!\\&lt;&amp;&quot;&#39;"},
+		{name: "html text", mode: telego.ModeHTML, value: "<b>a & b</b>", want: "&lt;b&gt;a &amp; b&lt;/b&gt;"},
+		{name: "no parse mode", mode: "", value: specialCharacters, want: specialCharacters},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Escape(tt.mode, tt.value); got != tt.want {
+				t.Errorf("Escape(%q, %q) = %q, want %q", tt.mode, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		value string
+		want  string
+	}{
+		// Inside MarkdownV2 code only "`" and "\" must be escaped.
+		{name: "markdown special", mode: telego.ModeMarkdownV2, value: specialCharacters,
+			want: "_*[]()~\\`>#+-=|{}.!\\\\<&\"'"},
+		{name: "markdown code", mode: telego.ModeMarkdownV2, value: "echo `date` > C:\\tmp",
+			want: "echo \\`date\\` > C:\\\\tmp"},
+		// Legacy Markdown can't escape inside entities, so the delimiter is dropped.
+		{name: "markdown_v1 special", mode: telego.ModeMarkdown, value: specialCharacters,
+			want: "_*[]()~>#+-=|{}.!\\<&\"'"},
+		{name: "html special", mode: telego.ModeHTML, value: specialCharacters,
+			want: "_*[]()~`&gt;#+-=|{}.!\\&lt;&amp;&quot;&#39;"},
+		{name: "no parse mode", mode: "", value: specialCharacters, want: specialCharacters},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeCode(tt.mode, tt.value); got != tt.want {
+				t.Errorf("EscapeCode(%q, %q) = %q, want %q", tt.mode, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkEscaping(t *testing.T) {
+	link := []Span{{Text: "docs [v2]", Type: telego.EntityTypeTextLink, URL: "https://example.com/a_(b)?q=1&r=\\x"}}
+	tests := []struct {
+		name string
+		mode string
+		want string
+	}{
+		// Inside the (...) part of a MarkdownV2 link only ")" and "\" must be escaped.
+		{name: "markdown", mode: telego.ModeMarkdownV2, want: "[docs \\[v2\\]](https://example.com/a_(b\\)?q=1&r=\\\\x)"},
+		// Legacy Markdown has no escaping inside entities: "]" is dropped from the text, the URL is written as is.
+		{name: "markdown_v1", mode: telego.ModeMarkdown, want: "[docs [v2](https://example.com/a_(b)?q=1&r=\\x)"},
+		{name: "html", mode: telego.ModeHTML, want: "<a href=\"https://example.com/a_(b)?q=1&amp;r=\\x\">docs [v2]</a>"},
+		{name: "no parse mode", mode: "", want: "docs [v2]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SpansToMode(tt.mode, link); got != tt.want {
+				t.Errorf("SpansToMode(%q) = %q, want %q", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+// benchmarkText is a prompt-sized text with a few characters to escape in every mode.
+var benchmarkText = strings.Repeat("Roll out billing-api_v2 to prod (eu-1) after <review> & sign-off. ", 20)
+
+var benchmarkModes = []struct {
+	name string
+	mode string
+}{
+	{name: "markdown", mode: telego.ModeMarkdownV2},
+	{name: "markdown_v1", mode: telego.ModeMarkdown},
+	{name: "html", mode: telego.ModeHTML},
+}
+
+func BenchmarkEscape(b *testing.B) {
+	for _, tt := range benchmarkModes {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				Escape(tt.mode, benchmarkText)
+			}
+		})
+	}
+	b.Run("nothing to escape", func(b *testing.B) {
+		plain := strings.Repeat("Roll out billing api to prod ", 40)
+		b.ReportAllocs()
+		for b.Loop() {
+			Escape(telego.ModeMarkdownV2, plain)
+		}
+	})
+}
+
+func BenchmarkEscapeCode(b *testing.B) {
+	for _, tt := range benchmarkModes {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				EscapeCode(tt.mode, benchmarkText)
+			}
+		})
+	}
+}
+
+func BenchmarkWriteMarkdownV2(b *testing.B) {
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	for b.Loop() {
+		buf.Reset()
+		WriteMarkdownV2(&buf, benchmarkText)
+	}
+}
@@ -0,0 +1,23 @@
+package shared
+
+// Theme holds emoji used in resolution notes and prompt titles.
+type Theme struct {
+	Success string
+	Error   string
+	Timeout string
+	// Priority maps request priority to the prompt title emoji; empty keeps the localized one.
+	Priority map[string]string
+}
+
+// PriorityEmoji returns title emoji for the priority or empty string.
+func (t Theme) PriorityEmoji(priority string) string {
+	return t.Priority[priority]
+}
+
+// WithEmoji prefixes text with emoji, keeping text as is when emoji is empty.
+func WithEmoji(emoji, text string) string {
+	if emoji == "" {
+		return text
+	}
+	return emoji + " " + text
+}
@@ -0,0 +1,91 @@
+//go:build simulate
+
+package telegram
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/config"
+	"github.com/codex-k8s/telegram-executor/internal/events"
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/reporting"
+	"github.com/codex-k8s/telegram-executor/internal/state"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/updates"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+	"github.com/codex-k8s/telegram-executor/internal/testutil"
+	"github.com/mymmrac/telego"
+)
+
+// simulation is the in-memory Bot API client and update source of TG_EXECUTOR_SIMULATE. It is only built with the
+// simulate tag, so production binaries don't link internal/testutil.
+type simulation struct {
+	bot    *testutil.Bot
+	source *updates.Simulated
+}
+
+// NewSimulated creates a service for TG_EXECUTOR_SIMULATE: Bot API calls go to an in-memory client and updates
+// come from SimulateMessage and SimulateCallback, so flows run end to end without Telegram.
+func NewSimulated(cfg config.Config, bundle i18n.Bundle, registry *executions.Registry, store *state.Store, tenantSet *tenants.Set, bus *events.Bus, reporter reporting.Reporter, log *slog.Logger) (*Service, error) {
+	sim := &simulation{bot: testutil.NewBot(), source: updates.NewSimulated()}
+	svc, err := NewWithAPI(cfg, sim.bot, sim.source, bundle, registry, store, tenantSet, bus, reporter, log)
+	if err != nil {
+		return nil, err
+	}
+	svc.simulation = sim
+	svc.handler.SetUpdateMode("simulation")
+	return svc, nil
+}
+
+// SimulateMessage injects a text message of the user to the chat, replying to replyTo when positive, and returns
+// the message id. The message is handled asynchronously like any update.
+func (s *Service) SimulateMessage(chatID int64, from telego.User, text string, replyTo int) (int, error) {
+	if s.simulation == nil {
+		return 0, ErrNotSimulated
+	}
+	message := s.simulation.message(chatID, from)
+	message.Text = text
+	if replyTo > 0 {
+		message.ReplyToMessage = &telego.Message{MessageID: replyTo, Chat: message.Chat, From: &telego.User{ID: testutil.BotUserID, IsBot: true}}
+	}
+	if _, err := s.simulation.source.Inject(telego.Update{Message: message}); err != nil {
+		return 0, err
+	}
+	return message.MessageID, nil
+}
+
+// SimulateCallback injects a press of the inline button with data on the bot message of the chat.
+func (s *Service) SimulateCallback(chatID int64, from telego.User, messageID int, data string) error {
+	if s.simulation == nil {
+		return ErrNotSimulated
+	}
+	message := s.simulation.message(chatID, from)
+	// The id reserved for the message identifies the query; the button belongs to the bot message.
+	queryID := "simulated-" + strconv.Itoa(message.MessageID)
+	message.MessageID = messageID
+	message.From = &telego.User{ID: testutil.BotUserID, IsBot: true}
+	_, err := s.simulation.source.Inject(telego.Update{CallbackQuery: &telego.CallbackQuery{
+		ID:           queryID,
+		From:         from,
+		Message:      message,
+		ChatInstance: strconv.FormatInt(chatID, 10),
+		Data:         data,
+	}})
+	return err
+}
+
+// message returns a new message of the user in the chat.
+func (sim *simulation) message(chatID int64, from telego.User) *telego.Message {
+	chatType := telego.ChatTypeSupergroup
+	if chatID > 0 {
+		chatType = telego.ChatTypePrivate
+	}
+	return &telego.Message{
+		MessageID: sim.bot.NextMessageID(),
+		Date:      time.Now().Unix(),
+		Chat:      telego.Chat{ID: chatID, Type: chatType},
+		From:      &from,
+	}
+}
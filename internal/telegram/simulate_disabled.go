@@ -0,0 +1,35 @@
+//go:build !simulate
+
+package telegram
+
+import (
+	"log/slog"
+
+	"github.com/codex-k8s/telegram-executor/internal/config"
+	"github.com/codex-k8s/telegram-executor/internal/events"
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/reporting"
+	"github.com/codex-k8s/telegram-executor/internal/state"
+	"github.com/codex-k8s/telegram-executor/internal/tenants"
+	"github.com/mymmrac/telego"
+)
+
+// simulation is never set without the simulate tag.
+type simulation struct{}
+
+// NewSimulated fails with ErrSimulationNotBuilt: the in-memory Bot API client is only linked into binaries built
+// with the simulate tag.
+func NewSimulated(cfg config.Config, bundle i18n.Bundle, registry *executions.Registry, store *state.Store, tenantSet *tenants.Set, bus *events.Bus, reporter reporting.Reporter, log *slog.Logger) (*Service, error) {
+	return nil, ErrSimulationNotBuilt
+}
+
+// SimulateMessage fails with ErrNotSimulated.
+func (s *Service) SimulateMessage(chatID int64, from telego.User, text string, replyTo int) (int, error) {
+	return 0, ErrNotSimulated
+}
+
+// SimulateCallback fails with ErrNotSimulated.
+func (s *Service) SimulateCallback(chatID int64, from telego.User, messageID int, data string) error {
+	return ErrNotSimulated
+}
@@ -0,0 +1,153 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/executions"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/codex-k8s/telegram-executor/internal/render"
+	"github.com/codex-k8s/telegram-executor/internal/telegram/shared"
+)
+
+const (
+	templateExt         = ".tmpl"
+	defaultTemplateName = "_default"
+)
+
+// messageTemplates holds per-tool prompt templates keyed by tool name and markup.
+type messageTemplates struct {
+	templates map[string]*template.Template
+}
+
+// templateData is passed to prompt templates.
+type templateData struct {
+	Title         string
+	Messages      i18n.Messages
+	Tool          executions.Tool
+	CorrelationID string
+	Question      string
+	Context       string
+	Options       []string
+	Arguments     map[string]any
+	Spec          map[string]any
+	Lang          string
+	Sections      []string
+	SubmittedAt   time.Time
+	Deadline      time.Time
+	Assignee      executions.Assignee
+}
+
+// loadTemplates parses <tool>.<markdown|markdown_v1|html>.tmpl files from dir; _default.<markup>.tmpl applies to all tools.
+func loadTemplates(dir string) (*messageTemplates, error) {
+	out := &messageTemplates{templates: make(map[string]*template.Template)}
+	if strings.TrimSpace(dir) == "" {
+		return out, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read templates dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), templateExt) {
+			continue
+		}
+		base := strings.TrimSuffix(entry.Name(), templateExt)
+		dot := strings.LastIndex(base, ".")
+		if dot <= 0 {
+			return nil, fmt.Errorf("template %s: expected <tool>.<markup>%s", entry.Name(), templateExt)
+		}
+		tool, markup := base[:dot], strings.ToLower(base[dot+1:])
+		if markup != executions.MarkupMarkdown && markup != executions.MarkupMarkdownV1 && markup != executions.MarkupHTML {
+			return nil, fmt.Errorf("template %s: unsupported markup %q", entry.Name(), markup)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read template %s: %w", entry.Name(), err)
+		}
+		tmpl, err := template.New(entry.Name()).Funcs(templateFuncs(markup)).Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("parse template %s: %w", entry.Name(), err)
+		}
+		out.templates[templateKey(tool, markup)] = tmpl
+	}
+	return out, nil
+}
+
+// Lookup returns template for tool and markup, falling back to the default override.
+func (t *messageTemplates) Lookup(tool, markup string) *template.Template {
+	if t == nil {
+		return nil
+	}
+	markup = normalizeTemplateMarkup(markup)
+	if tmpl, ok := t.templates[templateKey(tool, markup)]; ok {
+		return tmpl
+	}
+	return t.templates[templateKey(defaultTemplateName, markup)]
+}
+
+// Len returns number of loaded templates.
+func (t *messageTemplates) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.templates)
+}
+
+func renderTemplate(tmpl *template.Template, msg i18n.Messages, req executions.Request) (string, error) {
+	data := templateData{
+		Title:         msg.ExecutionTitle,
+		Messages:      msg,
+		Tool:          req.Tool,
+		CorrelationID: req.CorrelationID,
+		Question:      req.Question,
+		Context:       req.Context,
+		Options:       req.Options,
+		Arguments:     req.Arguments,
+		Spec:          req.Spec,
+		Lang:          req.Lang,
+		Sections:      req.Render.Sections,
+		SubmittedAt:   req.SubmittedAt,
+		Deadline:      req.Deadline,
+		Assignee:      req.Assignee,
+	}
+	builder := &strings.Builder{}
+	if err := tmpl.Execute(builder, data); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+func templateFuncs(markup string) template.FuncMap {
+	mode := shared.ParseMode(markup)
+	return template.FuncMap{
+		"escape":     func(value string) string { return shared.Escape(mode, value) },
+		"escapeCode": func(value string) string { return shared.EscapeCode(mode, value) },
+		"markdown":   func(value string) string { return shared.SpansToMode(mode, shared.ParseMarkdown(value)) },
+		"json": func(value any) (string, error) {
+			data, err := json.MarshalIndent(value, "", "  ")
+			return string(data), err
+		},
+		"inc":    func(value int) int { return value + 1 },
+		"join":   strings.Join,
+		"isDiff": render.LooksLikeDiff,
+	}
+}
+
+func templateKey(tool, markup string) string {
+	return tool + "|" + markup
+}
+
+func normalizeTemplateMarkup(markup string) string {
+	switch markup = strings.ToLower(strings.TrimSpace(markup)); markup {
+	case executions.MarkupHTML, executions.MarkupMarkdownV1:
+		return markup
+	default:
+		return executions.MarkupMarkdown
+	}
+}
@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/config"
+	"github.com/codex-k8s/telegram-executor/internal/reporting"
+	"github.com/mymmrac/telego"
+)
+
+// RotateToken switches the service to a new token of the same bot without downtime: the new client is verified
+// with getMe, updates delivery moves over to it and then all Bot API calls use it. Message ids do not depend on
+// the token, so prompts sent before the rotation keep resolving.
+func (s *Service) RotateToken(ctx context.Context, token string) error {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+	token = strings.TrimSpace(token)
+	if token == s.token {
+		return nil
+	}
+	if botID(token) != botID(s.token) {
+		return fmt.Errorf("rotated token belongs to another bot")
+	}
+	api, err := telego.NewBot(token, telego.WithLogger(telegoLogger{log: s.log}))
+	if err != nil {
+		return err
+	}
+	if _, err := api.GetMe(ctx); err != nil {
+		return fmt.Errorf("verify rotated token: %w", err)
+	}
+	if err := s.source.SwitchBot(ctx, api); err != nil {
+		return fmt.Errorf("switch updates delivery: %w", err)
+	}
+	s.bot.SetAPI(api)
+	s.token = token
+	s.log.InfoContext(ctx, "Telegram bot token rotated")
+	return nil
+}
+
+// watchTokenFile applies a token written to TG_EXECUTOR_TOKEN_FILE, e.g. by an updated Kubernetes secret.
+func (s *Service) watchTokenFile(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.TokenReloadInterval)
+	defer ticker.Stop()
+	var rejected string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		token, err := config.ReadTokenFile(s.cfg.TokenFile)
+		if err != nil {
+			s.log.WarnContext(ctx, "Failed to reload bot token", "error", err)
+			continue
+		}
+		if token == rejected {
+			continue
+		}
+		if err := s.RotateToken(ctx, token); err != nil {
+			rejected = token
+			s.log.ErrorContext(ctx, "Failed to rotate bot token", "error", err)
+			s.reporter.Report(ctx, err, reporting.Tags(reporting.TagComponent, "telegram", reporting.TagOperation, "token_rotation"))
+		}
+	}
+}
+
+// botID returns the bot id part of a token ("<id>:<secret>").
+func botID(token string) string {
+	id, _, _ := strings.Cut(token, ":")
+	return id
+}
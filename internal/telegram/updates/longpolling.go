@@ -4,38 +4,130 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mymmrac/telego"
 )
 
+// pollRetryDelay is the pause after a failed getUpdates call.
+const pollRetryDelay = 5 * time.Second
+
 // LongPolling delivers Telegram updates via long polling.
 type LongPolling struct {
-	bot     *telego.Bot
-	updates <-chan telego.Update
 	log     *slog.Logger
+	updates chan telego.Update
+	// offset is the id after the last delivered update, so that a new poller neither skips nor repeats updates.
+	offset atomic.Int64
+	stats  counters
+
+	mu  sync.Mutex
+	ctx context.Context
+	bot *telego.Bot
+	// cancel and done stop the current poller and wait until it returns.
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
 // NewLongPolling creates a new long polling source.
 func NewLongPolling(bot *telego.Bot, log *slog.Logger) *LongPolling {
-	return &LongPolling{bot: bot, log: log}
+	return &LongPolling{bot: bot, log: log, updates: make(chan telego.Update, 128)}
 }
 
 // Start initializes long polling updates.
 func (l *LongPolling) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ctx = ctx
+	l.pollLocked(l.bot)
+	l.log.Info("Telegram updates started via long polling")
+	return nil
+}
+
+// SwitchBot stops polling with the current client and resumes with bot, from the same offset for a rotated token
+// of the same bot and from the first pending update for another bot.
+func (l *LongPolling) SwitchBot(ctx context.Context, bot *telego.Bot) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cancel == nil {
+		l.bot = bot
+		return nil
+	}
+	l.cancel()
+	select {
+	case <-l.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if !sameBot(bot, l.bot) {
+		l.offset.Store(0)
+	}
+	l.bot = bot
+	l.pollLocked(bot)
+	l.log.Info("Telegram long polling switched to another token")
+	return nil
+}
+
+// pollLocked starts a poller of bot.
+func (l *LongPolling) pollLocked(bot *telego.Bot) {
+	pollCtx, cancel := context.WithCancel(l.ctx)
+	done := make(chan struct{})
+	l.cancel, l.done = cancel, done
+	go l.poll(pollCtx, bot, done)
+}
+
+// poll calls getUpdates until ctx is done, pausing after failures. A successful call after failures counts as
+// a reconnect.
+func (l *LongPolling) poll(ctx context.Context, bot *telego.Bot, done chan<- struct{}) {
+	defer close(done)
 	params := &telego.GetUpdatesParams{
 		Timeout: 10,
 		AllowedUpdates: []string{
 			telego.MessageUpdates,
+			telego.EditedMessageUpdates,
 			telego.CallbackQueryUpdates,
+			telego.PollAnswerUpdates,
+			telego.MessageReactionUpdates,
+			telego.MyChatMemberUpdates,
+			telego.InlineQueryUpdates,
 		},
 	}
-	updates, err := l.bot.UpdatesViaLongPolling(ctx, params)
-	if err != nil {
-		return err
+	failing := false
+	for ctx.Err() == nil {
+		params.Offset = int(l.offset.Load())
+		updates, err := bot.GetUpdates(ctx, params)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			l.stats.pollErrors.Add(1)
+			failing = true
+			l.log.Warn("Failed to get Telegram updates, retrying", "error", err, "delay", pollRetryDelay)
+			timer := time.NewTimer(pollRetryDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		if failing {
+			failing = false
+			l.stats.reconnects.Add(1)
+			l.log.Info("Telegram long polling reconnected")
+		}
+		for _, update := range updates {
+			select {
+			case l.updates <- update:
+			case <-ctx.Done():
+				return
+			}
+			l.stats.received.Add(1)
+			l.offset.Store(int64(update.UpdateID) + 1)
+		}
 	}
-	l.updates = updates
-	l.log.Info("Telegram updates started via long polling")
-	return nil
 }
 
 // Updates returns the updates channel.
@@ -43,6 +135,11 @@ func (l *LongPolling) Updates() <-chan telego.Update {
 	return l.updates
 }
 
+// Stats returns delivery counters.
+func (l *LongPolling) Stats() Stats {
+	return l.stats.snapshot()
+}
+
 // Stop stops long polling.
 func (l *LongPolling) Stop(context.Context) error {
 	return nil
@@ -52,3 +149,8 @@ func (l *LongPolling) Stop(context.Context) error {
 func (l *LongPolling) Handler() http.Handler {
 	return nil
 }
+
+// Check is a no-op for long polling: delivery health is covered by Bot API reachability.
+func (l *LongPolling) Check(context.Context) error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package updates
+
+// recentUpdateIDs is the number of queued webhook update ids remembered to detect redeliveries.
+const recentUpdateIDs = 1024
+
+// recentIDs remembers the last added ids, forgetting the oldest one when full. It is not safe for concurrent use.
+type recentIDs struct {
+	ring []int
+	next int
+	ids  map[int]struct{}
+}
+
+func newRecentIDs(size int) *recentIDs {
+	return &recentIDs{ring: make([]int, 0, size), ids: make(map[int]struct{}, size)}
+}
+
+func (r *recentIDs) contains(id int) bool {
+	_, ok := r.ids[id]
+	return ok
+}
+
+func (r *recentIDs) add(id int) {
+	if len(r.ring) < cap(r.ring) {
+		r.ring = append(r.ring, id)
+	} else {
+		delete(r.ids, r.ring[r.next])
+		r.ring[r.next] = id
+		r.next = (r.next + 1) % len(r.ring)
+	}
+	r.ids[id] = struct{}{}
+}
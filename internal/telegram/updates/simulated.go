@@ -0,0 +1,73 @@
+package updates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/mymmrac/telego"
+)
+
+// ErrQueueFull is returned by Simulated.Inject when the updates channel is full.
+var ErrQueueFull = errors.New("updates queue is full")
+
+// Simulated delivers synthetic updates passed to Inject instead of receiving them from Telegram.
+type Simulated struct {
+	updates chan telego.Update
+	lastID  atomic.Int64
+	stats   counters
+}
+
+// NewSimulated creates a new simulated source.
+func NewSimulated() *Simulated {
+	return &Simulated{updates: make(chan telego.Update, 128)}
+}
+
+// Inject assigns the next update id to the update and queues it for the handler.
+func (s *Simulated) Inject(update telego.Update) (int, error) {
+	update.UpdateID = int(s.lastID.Add(1))
+	select {
+	case s.updates <- update:
+		s.stats.received.Add(1)
+		return update.UpdateID, nil
+	default:
+		s.stats.dropped.Add(1)
+		return 0, ErrQueueFull
+	}
+}
+
+// Start is a no-op: updates arrive through Inject.
+func (s *Simulated) Start(context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (s *Simulated) Stop(context.Context) error {
+	return nil
+}
+
+// Updates returns the updates channel.
+func (s *Simulated) Updates() <-chan telego.Update {
+	return s.updates
+}
+
+// Handler is not used for simulated updates.
+func (s *Simulated) Handler() http.Handler {
+	return nil
+}
+
+// SwitchBot fails: simulated updates do not come from a bot.
+func (s *Simulated) SwitchBot(context.Context, *telego.Bot) error {
+	return errors.New("simulated updates cannot switch bots")
+}
+
+// Stats returns delivery counters.
+func (s *Simulated) Stats() Stats {
+	return s.stats.snapshot()
+}
+
+// Check is a no-op for simulated updates.
+func (s *Simulated) Check(context.Context) error {
+	return nil
+}
@@ -3,6 +3,8 @@ package updates
 import (
 	"context"
 	"net/http"
+	"strings"
+	"sync/atomic"
 
 	"github.com/mymmrac/telego"
 )
@@ -17,4 +19,45 @@ type Source interface {
 	Updates() <-chan telego.Update
 	// Handler returns HTTP handler for webhook mode (nil for long polling).
 	Handler() http.Handler
+	// SwitchBot moves updates delivery to another client: a rotated token of the same bot or a standby bot.
+	SwitchBot(ctx context.Context, bot *telego.Bot) error
+	// Stats returns delivery counters.
+	Stats() Stats
+	// Check verifies that updates delivery is configured on Telegram side.
+	Check(ctx context.Context) error
+}
+
+// sameBot reports whether both clients use tokens of the same bot ("<id>:<secret>").
+func sameBot(a, b *telego.Bot) bool {
+	idA, _, _ := strings.Cut(a.Token(), ":")
+	idB, _, _ := strings.Cut(b.Token(), ":")
+	return idA == idB
+}
+
+// Stats are update delivery counters since startup.
+type Stats struct {
+	// Received is the number of updates accepted into the updates channel.
+	Received uint64
+	// Dropped is the number of webhook updates rejected because the channel was full.
+	Dropped uint64
+	// Duplicates is the number of webhook updates ignored as redeliveries of queued ones.
+	Duplicates uint64
+	// PollErrors is the number of failed getUpdates calls (long polling only).
+	PollErrors uint64
+	// Reconnects is the number of times long polling recovered after failed calls.
+	Reconnects uint64
+}
+
+type counters struct {
+	received, dropped, duplicates, pollErrors, reconnects atomic.Uint64
+}
+
+func (c *counters) snapshot() Stats {
+	return Stats{
+		Received:   c.received.Load(),
+		Dropped:    c.dropped.Load(),
+		Duplicates: c.duplicates.Load(),
+		PollErrors: c.pollErrors.Load(),
+		Reconnects: c.reconnects.Load(),
+	}
 }
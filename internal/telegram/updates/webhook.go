@@ -2,56 +2,130 @@ package updates
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/mymmrac/telego"
 )
 
+// WebhookOptions configures the webhook source.
+type WebhookOptions struct {
+	// URL is the public URL of the webhook endpoint.
+	URL string
+	// Secret is the secret token Telegram sends in the X-Telegram-Bot-Api-Secret-Token header.
+	Secret string
+	// PreviousSecret, if set, is accepted for SecretGrace after Start during a secret rotation.
+	PreviousSecret string
+	SecretGrace    time.Duration
+	// PathSecret, if set, is appended to URL as the last path segment.
+	PathSecret string
+}
+
 // Webhook delivers Telegram updates via HTTP webhook.
 type Webhook struct {
-	bot     *telego.Bot
-	url     string
-	secret  string
-	updates chan telego.Update
-	closed  atomic.Bool
-	log     *slog.Logger
+	bot atomic.Pointer[telego.Bot]
+	// url is registered on Telegram side; logURL is url without the path secret.
+	url    string
+	logURL string
+	secret string
+	// previousSecret is accepted until previousUntil while Telegram still delivers updates signed with it.
+	previousSecret string
+	previousGrace  time.Duration
+	previousUntil  atomic.Int64
+	updates        chan telego.Update
+	stats          counters
+	seenMu         sync.Mutex
+	seen           *recentIDs
+	closed         atomic.Bool
+	log            *slog.Logger
 }
 
 // NewWebhook creates a new webhook source.
-func NewWebhook(bot *telego.Bot, url, secret string, log *slog.Logger) *Webhook {
-	return &Webhook{
-		bot:     bot,
-		url:     url,
-		secret:  secret,
-		updates: make(chan telego.Update, 128),
-		log:     log,
+func NewWebhook(bot *telego.Bot, opts WebhookOptions, log *slog.Logger) *Webhook {
+	w := &Webhook{
+		url:            opts.URL,
+		logURL:         opts.URL,
+		secret:         opts.Secret,
+		previousSecret: opts.PreviousSecret,
+		previousGrace:  opts.SecretGrace,
+		updates:        make(chan telego.Update, 128),
+		seen:           newRecentIDs(recentUpdateIDs),
+		log:            log,
+	}
+	if opts.PathSecret != "" {
+		w.url = strings.TrimRight(opts.URL, "/") + "/" + opts.PathSecret
+		w.logURL = strings.TrimRight(opts.URL, "/") + "/<path secret>"
 	}
+	w.bot.Store(bot)
+	if w.previousSecret != "" {
+		w.previousUntil.Store(time.Now().Add(w.previousGrace).UnixNano())
+	}
+	return w
 }
 
 // Start sets webhook on Telegram side.
 func (w *Webhook) Start(ctx context.Context) error {
+	if w.previousSecret != "" {
+		// The window restarts with the registration: Telegram may still retry updates signed with the previous secret.
+		w.previousUntil.Store(time.Now().Add(w.previousGrace).UnixNano())
+	}
+	if err := w.register(ctx, w.bot.Load()); err != nil {
+		return err
+	}
+	w.log.Info("Telegram updates started via webhook", "url", w.logURL)
+	return nil
+}
+
+// SwitchBot registers the webhook with bot; updates keep arriving at the same URL meanwhile.
+func (w *Webhook) SwitchBot(ctx context.Context, bot *telego.Bot) error {
+	if err := w.register(ctx, bot); err != nil {
+		return err
+	}
+	w.bot.Store(bot)
+	w.log.Info("Telegram webhook registered with another token", "url", w.logURL)
+	return nil
+}
+
+func (w *Webhook) register(ctx context.Context, bot *telego.Bot) error {
 	params := &telego.SetWebhookParams{
 		URL:         w.url,
 		SecretToken: w.secret,
 		AllowedUpdates: []string{
 			telego.MessageUpdates,
+			telego.EditedMessageUpdates,
 			telego.CallbackQueryUpdates,
+			telego.PollAnswerUpdates,
+			telego.MessageReactionUpdates,
+			telego.MyChatMemberUpdates,
+			telego.InlineQueryUpdates,
 		},
 	}
-	if err := w.bot.SetWebhook(ctx, params); err != nil {
-		return err
-	}
-	w.log.Info("Telegram updates started via webhook", "url", w.url)
-	return nil
+	return bot.SetWebhook(ctx, params)
 }
 
 // Stop removes the webhook.
 func (w *Webhook) Stop(ctx context.Context) error {
 	w.closed.Store(true)
-	return w.bot.DeleteWebhook(ctx, &telego.DeleteWebhookParams{DropPendingUpdates: true})
+	return w.bot.Load().DeleteWebhook(ctx, &telego.DeleteWebhookParams{DropPendingUpdates: true})
+}
+
+// Check verifies that Telegram has the webhook registered with the expected URL.
+func (w *Webhook) Check(ctx context.Context) error {
+	info, err := w.bot.Load().GetWebhookInfo(ctx)
+	if err != nil {
+		return err
+	}
+	if info.URL != w.url {
+		return fmt.Errorf("webhook is not registered at %s", w.logURL)
+	}
+	return nil
 }
 
 // Updates returns the updates channel.
@@ -59,6 +133,11 @@ func (w *Webhook) Updates() <-chan telego.Update {
 	return w.updates
 }
 
+// Stats returns delivery counters.
+func (w *Webhook) Stats() Stats {
+	return w.stats.snapshot()
+}
+
 // Handler returns HTTP handler for Telegram webhook updates.
 func (w *Webhook) Handler() http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
@@ -70,8 +149,7 @@ func (w *Webhook) Handler() http.Handler {
 			rw.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
-		secret := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
-		if secret != w.secret {
+		if !w.acceptSecret(r.Header.Get("X-Telegram-Bot-Api-Secret-Token")) {
 			w.log.Warn("Webhook secret mismatch")
 			rw.WriteHeader(http.StatusUnauthorized)
 			return
@@ -84,12 +162,60 @@ func (w *Webhook) Handler() http.Handler {
 			rw.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		select {
-		case w.updates <- update:
+		switch w.enqueue(update) {
+		case enqueueDuplicate:
+			w.stats.duplicates.Add(1)
+			w.log.Debug("Duplicate webhook update ignored", "update_id", update.UpdateID)
 			rw.WriteHeader(http.StatusOK)
-		default:
+		case enqueueFull:
+			w.stats.dropped.Add(1)
 			w.log.Error("Webhook update dropped: queue full")
 			rw.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.stats.received.Add(1)
+			rw.WriteHeader(http.StatusOK)
 		}
 	})
 }
+
+type enqueueResult int
+
+const (
+	enqueued enqueueResult = iota
+	enqueueDuplicate
+	enqueueFull
+)
+
+// enqueue queues the update unless one with the same update_id was queued recently: Telegram redelivers updates
+// whose delivery timed out, and the handler must not process them twice. Updates rejected with a full queue are
+// not remembered, so their redelivery is queued.
+func (w *Webhook) enqueue(update telego.Update) enqueueResult {
+	w.seenMu.Lock()
+	defer w.seenMu.Unlock()
+	if w.seen.contains(update.UpdateID) {
+		return enqueueDuplicate
+	}
+	select {
+	case w.updates <- update:
+		w.seen.add(update.UpdateID)
+		return enqueued
+	default:
+		return enqueueFull
+	}
+}
+
+// acceptSecret reports whether the delivery carries the current secret or, within the rotation window, the
+// previous one.
+func (w *Webhook) acceptSecret(secret string) bool {
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(w.secret)) == 1 {
+		return true
+	}
+	if w.previousSecret == "" || time.Now().UnixNano() > w.previousUntil.Load() {
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(w.previousSecret)) != 1 {
+		return false
+	}
+	w.log.Info("Webhook update accepted with previous secret")
+	return true
+}
@@ -0,0 +1,74 @@
+package updates
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testSecret = "secret"
+
+func newTestWebhook() *Webhook {
+	return NewWebhook(nil, WebhookOptions{URL: "https://example.com/telegram/webhook", Secret: testSecret}, slog.New(slog.DiscardHandler))
+}
+
+// deliver posts an update the way Telegram does and returns the response status.
+func deliver(t *testing.T, w *Webhook, updateID int) int {
+	t.Helper()
+	body := fmt.Sprintf(`{"update_id":%d,"message":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"},"text":"ok"}}`, updateID)
+	req := httptest.NewRequest(http.MethodPost, "/telegram/webhook", strings.NewReader(body))
+	req.Header.Set("X-Telegram-Bot-Api-Secret-Token", testSecret)
+	rec := httptest.NewRecorder()
+	w.Handler().ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestWebhookRedelivery(t *testing.T) {
+	w := newTestWebhook()
+	for attempt := 1; attempt <= 2; attempt++ {
+		if code := deliver(t, w, 42); code != http.StatusOK {
+			t.Fatalf("delivery %d: status %d, want %d", attempt, code, http.StatusOK)
+		}
+	}
+	if queued := len(w.Updates()); queued != 1 {
+		t.Fatalf("queued %d updates, want 1", queued)
+	}
+	if update := <-w.Updates(); update.UpdateID != 42 {
+		t.Errorf("queued update_id %d, want 42", update.UpdateID)
+	}
+	stats := w.Stats()
+	if stats.Received != 1 || stats.Duplicates != 1 {
+		t.Errorf("stats received=%d duplicates=%d, want 1 and 1", stats.Received, stats.Duplicates)
+	}
+}
+
+func TestWebhookRedeliveryAfterQueueFull(t *testing.T) {
+	w := newTestWebhook()
+	for id := range cap(w.updates) {
+		if code := deliver(t, w, id); code != http.StatusOK {
+			t.Fatalf("filling queue: update %d status %d", id, code)
+		}
+	}
+	const updateID = 1000
+	if code := deliver(t, w, updateID); code != http.StatusServiceUnavailable {
+		t.Fatalf("delivery to full queue: status %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	<-w.Updates()
+	if code := deliver(t, w, updateID); code != http.StatusOK {
+		t.Fatalf("retried delivery: status %d, want %d", code, http.StatusOK)
+	}
+	var last int
+	for len(w.Updates()) > 0 {
+		last = (<-w.Updates()).UpdateID
+	}
+	if last != updateID {
+		t.Errorf("last queued update_id %d, want %d", last, updateID)
+	}
+	stats := w.Stats()
+	if stats.Dropped != 1 || stats.Duplicates != 0 {
+		t.Errorf("stats dropped=%d duplicates=%d, want 1 and 0", stats.Dropped, stats.Duplicates)
+	}
+}
@@ -0,0 +1,178 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/events"
+	"github.com/codex-k8s/telegram-executor/internal/i18n"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// Subsystems observed by the watchdog.
+const (
+	subsystemTelegram  = "telegram_api"
+	subsystemCallbacks = "callbacks"
+	subsystemUpdates   = "updates"
+)
+
+// watchdog notices sustained subsystem failures and posts rate-limited alerts into the admin chat.
+type watchdog struct {
+	chatID    int64
+	interval  time.Duration
+	threshold int
+	cooldown  time.Duration
+
+	mu            sync.Mutex
+	callbackFails int
+	callbackErr   string
+	failures      map[string]int
+	alerted       map[string]time.Time
+}
+
+func newWatchdog(chatID int64, interval time.Duration, threshold int, cooldown time.Duration) *watchdog {
+	return &watchdog{
+		chatID:    chatID,
+		interval:  interval,
+		threshold: threshold,
+		cooldown:  cooldown,
+		failures:  make(map[string]int),
+		alerted:   make(map[string]time.Time),
+	}
+}
+
+// observe tracks callback delivery streaks from lifecycle events.
+func (w *watchdog) observe(event events.Event) {
+	switch event.Type {
+	case events.TypeCallbackFailed:
+		w.mu.Lock()
+		w.callbackFails++
+		w.callbackErr = event.Error
+		w.mu.Unlock()
+	case events.TypeCallbackDelivered:
+		w.mu.Lock()
+		w.callbackFails = 0
+		w.callbackErr = ""
+		w.mu.Unlock()
+	}
+}
+
+// runWatchdog checks subsystems every interval until ctx is done.
+func (s *Service) runWatchdog(ctx context.Context) {
+	ticker := time.NewTicker(s.watchdog.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkSubsystems(ctx)
+		}
+	}
+}
+
+func (s *Service) checkSubsystems(ctx context.Context) {
+	w := s.watchdog
+	msg := s.messagesFor(s.lang)
+
+	probeCtx, cancel := context.WithTimeout(ctx, w.interval)
+	_, err := s.bot.API().GetMe(probeCtx)
+	if err == nil && s.cfg.WebhookEnabled() {
+		err = s.source.Check(probeCtx)
+	}
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		count := w.fail(subsystemTelegram)
+		s.alert(ctx, subsystemTelegram, count, msg.Format(fallbackText(msg.AlertTelegram, "🚨 Telegram API checks failed {count} times in a row: {error}"), i18n.Vars{
+			"count": count,
+			"error": err.Error(),
+		}))
+	} else {
+		s.clearAlert(ctx, subsystemTelegram)
+	}
+
+	w.mu.Lock()
+	callbackFails, callbackErr := w.callbackFails, w.callbackErr
+	w.mu.Unlock()
+	if callbackFails > 0 {
+		s.alert(ctx, subsystemCallbacks, callbackFails, msg.Format(fallbackText(msg.AlertCallbacks, "🚨 {count} callback deliveries failed in a row: {error}"), i18n.Vars{
+			"count": callbackFails,
+			"error": callbackErr,
+		}))
+	} else {
+		s.clearAlert(ctx, subsystemCallbacks)
+	}
+
+	// Updates are stuck when they keep queueing while the handler is busy with one update for a whole interval.
+	busySince := s.handler.BusySince()
+	waiting := len(s.source.Updates()) + s.handler.Backlog()
+	if waiting > 0 && !busySince.IsZero() && time.Since(busySince) >= w.interval {
+		count := w.fail(subsystemUpdates)
+		s.alert(ctx, subsystemUpdates, count, msg.Format(fallbackText(msg.AlertUpdates, "🚨 Update processing is stuck for {duration}, {count} updates waiting"), i18n.Vars{
+			"duration": time.Since(busySince).Round(time.Second).String(),
+			"count":    waiting,
+		}))
+	} else {
+		s.clearAlert(ctx, subsystemUpdates)
+	}
+}
+
+// fail increments the consecutive failure counter of subsystem.
+func (w *watchdog) fail(subsystem string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failures[subsystem]++
+	return w.failures[subsystem]
+}
+
+// alert posts text once count reaches the threshold, at most once per cooldown for each subsystem.
+func (s *Service) alert(ctx context.Context, subsystem string, count int, text string) {
+	w := s.watchdog
+	if count < w.threshold {
+		return
+	}
+	w.mu.Lock()
+	last, alerted := w.alerted[subsystem]
+	if alerted && time.Since(last) < w.cooldown {
+		w.mu.Unlock()
+		return
+	}
+	w.alerted[subsystem] = time.Now()
+	w.mu.Unlock()
+
+	s.log.WarnContext(ctx, "Watchdog alert", "subsystem", subsystem, "count", count)
+	s.sendAlert(ctx, text)
+}
+
+// clearAlert resets failure counter and posts a recovery notice for subsystem that was alerted.
+func (s *Service) clearAlert(ctx context.Context, subsystem string) {
+	w := s.watchdog
+	w.mu.Lock()
+	delete(w.failures, subsystem)
+	_, alerted := w.alerted[subsystem]
+	delete(w.alerted, subsystem)
+	w.mu.Unlock()
+	if !alerted {
+		return
+	}
+
+	s.log.InfoContext(ctx, "Watchdog recovered", "subsystem", subsystem)
+	msg := s.messagesFor(s.lang)
+	s.sendAlert(ctx, msg.Format(fallbackText(msg.AlertRecovered, "✅ Recovered: {subsystem}"), i18n.Vars{"subsystem": subsystem}))
+}
+
+func (s *Service) sendAlert(ctx context.Context, text string) {
+	_, err := s.bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID: tu.ID(s.state.MigratedChat(s.watchdog.chatID)),
+		Text:   text,
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.log.ErrorContext(ctx, "Failed to send watchdog alert", "error", err)
+	}
+}
@@ -0,0 +1,2 @@
+// Package tenants maps API keys to isolated per-team settings (chat, language, timeout, callbacks).
+package tenants
@@ -0,0 +1,193 @@
+package tenants
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Tenant describes a single team served by the deployment.
+type Tenant struct {
+	// ID namespaces correlation and group ids of the tenant.
+	ID string `yaml:"id"`
+	// APIKey authenticates requests; APIKeyEnv names an environment variable holding it instead.
+	APIKey    string `yaml:"api_key"`
+	APIKeyEnv string `yaml:"api_key_env"`
+	// ChatID is the Telegram chat receiving tenant prompts.
+	ChatID int64 `yaml:"chat_id"`
+	// Lang is the default language of tenant prompts.
+	Lang string `yaml:"lang"`
+	// Timeout is the default execution timeout of tenant prompts.
+	Timeout time.Duration `yaml:"timeout"`
+	// CallbackAllowlist lists allowed callback URL prefixes: scheme and host must match exactly and the path must
+	// be the prefix path or lie under it. Empty allows any URL.
+	CallbackAllowlist []string `yaml:"callback_allowlist"`
+	// MaxPending limits concurrently pending prompts (0 is unlimited).
+	MaxPending int `yaml:"max_pending"`
+	// DailyLimit limits submissions per UTC day (0 is unlimited).
+	DailyLimit int `yaml:"daily_limit"`
+}
+
+// CallbackAllowed reports whether callback URL matches the tenant allowlist.
+func (t Tenant) CallbackAllowed(rawURL string) bool {
+	if len(t.CallbackAllowlist) == 0 {
+		return true
+	}
+	target, err := url.Parse(rawURL)
+	if err != nil || target.User != nil || target.Host == "" {
+		return false
+	}
+	for _, entry := range t.CallbackAllowlist {
+		if allowed, err := url.Parse(entry); err == nil && callbackUnder(target, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// callbackUnder reports whether target has the scheme and host of allowed and its path lies under the allowed
+// path on a segment boundary, so "https://hooks.example/team" neither admits "https://hooks.example.evil.net"
+// nor "https://hooks.example/team-b".
+func callbackUnder(target, allowed *url.URL) bool {
+	if !strings.EqualFold(target.Scheme, allowed.Scheme) || !strings.EqualFold(target.Host, allowed.Host) {
+		return false
+	}
+	prefix := strings.TrimSuffix(allowed.Path, "/")
+	if prefix == "" {
+		return true
+	}
+	targetPath := path.Clean("/" + target.Path)
+	return targetPath == prefix || strings.HasPrefix(targetPath, prefix+"/")
+}
+
+// Set holds configured tenants. A nil or empty Set disables authentication.
+type Set struct {
+	tenants []Tenant
+
+	mu        sync.Mutex
+	day       string
+	submitted map[string]int
+	rejected  map[string]int
+}
+
+type file struct {
+	Tenants []Tenant `yaml:"tenants"`
+}
+
+// Load reads tenants from a YAML file; empty path returns an empty set.
+func Load(path string) (*Set, error) {
+	if path == "" {
+		return &Set{}, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tenants file: %w", err)
+	}
+	var parsed file
+	if err := yaml.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("parse tenants file: %w", err)
+	}
+	ids := make(map[string]struct{}, len(parsed.Tenants))
+	keys := make(map[string]struct{}, len(parsed.Tenants))
+	for idx := range parsed.Tenants {
+		tenant := &parsed.Tenants[idx]
+		if !idPattern.MatchString(tenant.ID) {
+			return nil, fmt.Errorf("tenants[%d]: id must match %s", idx, idPattern)
+		}
+		if _, dup := ids[tenant.ID]; dup {
+			return nil, fmt.Errorf("tenants[%d]: duplicate id %q", idx, tenant.ID)
+		}
+		ids[tenant.ID] = struct{}{}
+		if tenant.APIKeyEnv != "" {
+			tenant.APIKey = os.Getenv(tenant.APIKeyEnv)
+		}
+		if tenant.APIKey == "" {
+			return nil, fmt.Errorf("tenant %q: api_key is required", tenant.ID)
+		}
+		if _, dup := keys[tenant.APIKey]; dup {
+			return nil, fmt.Errorf("tenant %q: api_key is shared with another tenant", tenant.ID)
+		}
+		keys[tenant.APIKey] = struct{}{}
+		if tenant.ChatID == 0 {
+			return nil, fmt.Errorf("tenant %q: chat_id is required", tenant.ID)
+		}
+		if tenant.Timeout < 0 {
+			return nil, fmt.Errorf("tenant %q: timeout must not be negative", tenant.ID)
+		}
+		if tenant.MaxPending < 0 || tenant.DailyLimit < 0 {
+			return nil, fmt.Errorf("tenant %q: quotas must not be negative", tenant.ID)
+		}
+		for _, entry := range tenant.CallbackAllowlist {
+			if allowed, err := url.Parse(entry); err != nil || allowed.Scheme == "" || allowed.Host == "" || allowed.User != nil {
+				return nil, fmt.Errorf("tenant %q: callback_allowlist entry %q must be an absolute URL", tenant.ID, entry)
+			}
+		}
+		tenant.Lang = strings.ToLower(strings.TrimSpace(tenant.Lang))
+	}
+	return &Set{tenants: parsed.Tenants}, nil
+}
+
+// Enabled reports whether requests must be authenticated.
+func (s *Set) Enabled() bool {
+	return s != nil && len(s.tenants) > 0
+}
+
+// Get returns the tenant with the ID; without tenants the empty ID resolves to the empty tenant.
+func (s *Set) Get(id string) (Tenant, bool) {
+	if !s.Enabled() {
+		return Tenant{}, id == ""
+	}
+	for _, tenant := range s.tenants {
+		if tenant.ID == id {
+			return tenant, true
+		}
+	}
+	return Tenant{}, false
+}
+
+// ChatIDs returns chats of all tenants.
+func (s *Set) ChatIDs() []int64 {
+	if s == nil {
+		return nil
+	}
+	ids := make([]int64, 0, len(s.tenants))
+	for _, tenant := range s.tenants {
+		ids = append(ids, tenant.ChatID)
+	}
+	return ids
+}
+
+// Authenticate resolves tenant by "Authorization: Bearer <key>", "X-API-Key" header or HTTP Basic password.
+func (s *Set) Authenticate(r *http.Request) (Tenant, bool) {
+	if !s.Enabled() {
+		return Tenant{}, false
+	}
+	key := r.Header.Get("X-API-Key")
+	if value, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		key = value
+	} else if _, password, ok := r.BasicAuth(); ok {
+		// Browsers (the dashboard) authenticate with HTTP Basic, the API key being the password.
+		key = password
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return Tenant{}, false
+	}
+	for _, tenant := range s.tenants {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(tenant.APIKey)) == 1 {
+			return tenant, true
+		}
+	}
+	return Tenant{}, false
+}
@@ -0,0 +1,95 @@
+package tenants
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDailyLimit rejects submissions over the tenant daily_limit quota.
+var ErrDailyLimit = errors.New("tenant daily submissions limit reached")
+
+// Usage describes tenant quota consumption for the current UTC day.
+type Usage struct {
+	Tenant         string `json:"tenant"`
+	Day            string `json:"day"`
+	Pending        int    `json:"pending"`
+	MaxPending     int    `json:"max_pending,omitempty"`
+	SubmittedToday int    `json:"submitted_today"`
+	RejectedToday  int    `json:"rejected_today"`
+	DailyLimit     int    `json:"daily_limit,omitempty"`
+}
+
+// Reservation is a daily quota slot taken by Admit; Release returns it when the submission fails.
+type Reservation struct {
+	set    *Set
+	tenant string
+	day    string
+}
+
+// Admit checks the tenant daily quota for a new submission and reserves a slot for it. Rejections are
+// counted too; the max_pending quota is enforced when the execution is registered.
+func (s *Set) Admit(tenant Tenant, now time.Time) (Reservation, error) {
+	if !s.Enabled() || tenant.ID == "" {
+		return Reservation{}, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollLocked(now)
+	if tenant.DailyLimit > 0 && s.submitted[tenant.ID] >= tenant.DailyLimit {
+		s.rejected[tenant.ID]++
+		return Reservation{}, ErrDailyLimit
+	}
+	s.submitted[tenant.ID]++
+	return Reservation{set: s, tenant: tenant.ID, day: s.day}, nil
+}
+
+// Release returns the reserved slot of a failed submission; rejected counts it as a quota rejection.
+// Slots of a previous day are not returned.
+func (r Reservation) Release(rejected bool) {
+	if r.set == nil {
+		return
+	}
+	r.set.mu.Lock()
+	defer r.set.mu.Unlock()
+	if r.set.day != r.day {
+		return
+	}
+	r.set.submitted[r.tenant]--
+	if rejected {
+		r.set.rejected[r.tenant]++
+	}
+}
+
+// Usage returns quota consumption of all tenants; pending counts tenant executions awaiting an answer.
+func (s *Set) Usage(pending func(tenant string) int, now time.Time) []Usage {
+	if !s.Enabled() {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollLocked(now)
+	out := make([]Usage, 0, len(s.tenants))
+	for _, tenant := range s.tenants {
+		out = append(out, Usage{
+			Tenant:         tenant.ID,
+			Day:            s.day,
+			Pending:        pending(tenant.ID),
+			MaxPending:     tenant.MaxPending,
+			SubmittedToday: s.submitted[tenant.ID],
+			RejectedToday:  s.rejected[tenant.ID],
+			DailyLimit:     tenant.DailyLimit,
+		})
+	}
+	return out
+}
+
+// rollLocked resets daily counters when the UTC day changes.
+func (s *Set) rollLocked(now time.Time) {
+	day := now.UTC().Format(time.DateOnly)
+	if day == s.day && s.submitted != nil {
+		return
+	}
+	s.day = day
+	s.submitted = make(map[string]int)
+	s.rejected = make(map[string]int)
+}
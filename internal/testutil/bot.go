@@ -0,0 +1,223 @@
+package testutil
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/codex-k8s/telegram-executor/internal/telegram/outbound"
+	"github.com/mymmrac/telego"
+)
+
+// BotUserID is the user id of the bot returned by Bot.GetMe.
+const BotUserID = 1
+
+// Call is a Bot API call recorded by Bot.
+type Call struct {
+	// Method is the Bot API method name, e.g. "sendMessage".
+	Method string
+	// Params is the params pointer passed to the call, e.g. *telego.SendMessageParams.
+	Params any
+}
+
+// Bot is an in-memory outbound.BotAPI: it records calls, answers them with messages carrying increasing ids and
+// fails methods set with Fail. Every user is a member of every chat unless Members says otherwise.
+type Bot struct {
+	// Members answers getChatMember when set.
+	Members func(chatID, userID int64) telego.ChatMember
+	// FileBaseURL prefixes file paths in FileDownloadURL; getFile returns the file id as its path.
+	FileBaseURL string
+
+	mu     sync.Mutex
+	calls  []Call
+	lastID int
+	polls  int
+	fails  map[string]error
+}
+
+var _ outbound.BotAPI = (*Bot)(nil)
+
+// NewBot creates an empty in-memory bot.
+func NewBot() *Bot {
+	return &Bot{fails: make(map[string]error)}
+}
+
+// Fail makes calls of the method return err; nil err makes them succeed again.
+func (b *Bot) Fail(method string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		delete(b.fails, method)
+		return
+	}
+	b.fails[method] = err
+}
+
+// Calls returns recorded calls of the method, or all calls when method is empty, in call order.
+func (b *Bot) Calls(method string) []Call {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var calls []Call
+	for _, call := range b.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Sent returns params of sendMessage calls in call order.
+func (b *Bot) Sent() []*telego.SendMessageParams {
+	calls := b.Calls("sendMessage")
+	sent := make([]*telego.SendMessageParams, 0, len(calls))
+	for _, call := range calls {
+		sent = append(sent, call.Params.(*telego.SendMessageParams))
+	}
+	return sent
+}
+
+// Reset forgets recorded calls; message ids keep increasing.
+func (b *Bot) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = nil
+}
+
+// NextMessageID reserves a message id for a message not sent by the bot, e.g. a simulated user message, so that
+// ids stay unique within all chats.
+func (b *Bot) NextMessageID() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	return b.lastID
+}
+
+// record stores the call and returns the error set for its method.
+func (b *Bot) record(method string, params any) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = append(b.calls, Call{Method: method, Params: params})
+	return b.fails[method]
+}
+
+// message returns a new message of the chat with the next id.
+func (b *Bot) message(chat telego.ChatID, text string) *telego.Message {
+	return &telego.Message{
+		MessageID: b.NextMessageID(),
+		Date:      time.Now().Unix(),
+		Chat:      telego.Chat{ID: chat.ID, Username: chat.Username},
+		From:      &telego.User{ID: BotUserID, IsBot: true},
+		Text:      text,
+	}
+}
+
+// GetMe implements outbound.BotAPI.
+func (b *Bot) GetMe(_ context.Context) (*telego.User, error) {
+	if err := b.record("getMe", nil); err != nil {
+		return nil, err
+	}
+	return &telego.User{ID: BotUserID, IsBot: true, FirstName: "Test", Username: "test_bot"}, nil
+}
+
+// SendMessage implements outbound.BotAPI.
+func (b *Bot) SendMessage(_ context.Context, params *telego.SendMessageParams) (*telego.Message, error) {
+	if err := b.record("sendMessage", params); err != nil {
+		return nil, err
+	}
+	return b.message(params.ChatID, params.Text), nil
+}
+
+// SendDocument implements outbound.BotAPI.
+func (b *Bot) SendDocument(_ context.Context, params *telego.SendDocumentParams) (*telego.Message, error) {
+	if err := b.record("sendDocument", params); err != nil {
+		return nil, err
+	}
+	return b.message(params.ChatID, ""), nil
+}
+
+// SendPoll implements outbound.BotAPI.
+func (b *Bot) SendPoll(_ context.Context, params *telego.SendPollParams) (*telego.Message, error) {
+	if err := b.record("sendPoll", params); err != nil {
+		return nil, err
+	}
+	msg := b.message(params.ChatID, "")
+	b.mu.Lock()
+	b.polls++
+	msg.Poll = &telego.Poll{ID: "poll-" + strconv.Itoa(b.polls), Question: params.Question}
+	b.mu.Unlock()
+	return msg, nil
+}
+
+// StopPoll implements outbound.BotAPI.
+func (b *Bot) StopPoll(_ context.Context, params *telego.StopPollParams) (*telego.Poll, error) {
+	if err := b.record("stopPoll", params); err != nil {
+		return nil, err
+	}
+	return &telego.Poll{IsClosed: true}, nil
+}
+
+// EditMessageText implements outbound.BotAPI.
+func (b *Bot) EditMessageText(_ context.Context, params *telego.EditMessageTextParams) (*telego.Message, error) {
+	if err := b.record("editMessageText", params); err != nil {
+		return nil, err
+	}
+	return &telego.Message{MessageID: params.MessageID, Chat: telego.Chat{ID: params.ChatID.ID}, Text: params.Text}, nil
+}
+
+// EditMessageReplyMarkup implements outbound.BotAPI.
+func (b *Bot) EditMessageReplyMarkup(_ context.Context, params *telego.EditMessageReplyMarkupParams) (*telego.Message, error) {
+	if err := b.record("editMessageReplyMarkup", params); err != nil {
+		return nil, err
+	}
+	return &telego.Message{MessageID: params.MessageID, Chat: telego.Chat{ID: params.ChatID.ID}}, nil
+}
+
+// DeleteMessage implements outbound.BotAPI.
+func (b *Bot) DeleteMessage(_ context.Context, params *telego.DeleteMessageParams) error {
+	return b.record("deleteMessage", params)
+}
+
+// PinChatMessage implements outbound.BotAPI.
+func (b *Bot) PinChatMessage(_ context.Context, params *telego.PinChatMessageParams) error {
+	return b.record("pinChatMessage", params)
+}
+
+// UnpinChatMessage implements outbound.BotAPI.
+func (b *Bot) UnpinChatMessage(_ context.Context, params *telego.UnpinChatMessageParams) error {
+	return b.record("unpinChatMessage", params)
+}
+
+// AnswerCallbackQuery implements outbound.BotAPI.
+func (b *Bot) AnswerCallbackQuery(_ context.Context, params *telego.AnswerCallbackQueryParams) error {
+	return b.record("answerCallbackQuery", params)
+}
+
+// AnswerInlineQuery implements outbound.BotAPI.
+func (b *Bot) AnswerInlineQuery(_ context.Context, params *telego.AnswerInlineQueryParams) error {
+	return b.record("answerInlineQuery", params)
+}
+
+// GetChatMember implements outbound.BotAPI.
+func (b *Bot) GetChatMember(_ context.Context, params *telego.GetChatMemberParams) (telego.ChatMember, error) {
+	if err := b.record("getChatMember", params); err != nil {
+		return nil, err
+	}
+	if b.Members != nil {
+		return b.Members(params.ChatID.ID, params.UserID), nil
+	}
+	return &telego.ChatMemberMember{Status: telego.MemberStatusMember, User: telego.User{ID: params.UserID}}, nil
+}
+
+// GetFile implements outbound.BotAPI.
+func (b *Bot) GetFile(_ context.Context, params *telego.GetFileParams) (*telego.File, error) {
+	if err := b.record("getFile", params); err != nil {
+		return nil, err
+	}
+	return &telego.File{FileID: params.FileID, FilePath: params.FileID}, nil
+}
+
+// FileDownloadURL implements outbound.BotAPI.
+func (b *Bot) FileDownloadURL(filepath string) string {
+	return b.FileBaseURL + filepath
+}
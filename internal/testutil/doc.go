@@ -0,0 +1,2 @@
+// Package testutil provides in-memory doubles of external services for tests and simulations.
+package testutil
@@ -0,0 +1,3 @@
+// Package tools keeps per-tool default profiles (timeout, option limits, template, chat, approvers) applied to
+// /execute requests so they can stay thin and consistent.
+package tools
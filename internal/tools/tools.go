@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const maxNameLength = 128
+
+var templatePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Profile holds defaults applied to /execute requests of a tool; request fields still win.
+type Profile struct {
+	// Name is the tool name the profile applies to.
+	Name string `yaml:"name"`
+	// Timeout is the default execution timeout (0 keeps the tenant or global default).
+	Timeout time.Duration `yaml:"timeout"`
+	// OptionsMin and OptionsMax are default option count limits (0 keeps the built-in 2-5).
+	OptionsMin int `yaml:"options_min"`
+	OptionsMax int `yaml:"options_max"`
+	// Template names the message template used instead of the tool name ("<template>.<markup>.tmpl").
+	Template string `yaml:"template"`
+	// ChatID routes prompts of requests without a tenant to another chat.
+	ChatID int64 `yaml:"chat_id"`
+	// Approvers are Telegram usernames (without "@") allowed to answer; empty allows everyone.
+	Approvers []string `yaml:"approvers"`
+}
+
+// Normalize trims the profile and validates it.
+func (p *Profile) Normalize() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" || len(p.Name) > maxNameLength {
+		return fmt.Errorf("name must be 1-%d characters", maxNameLength)
+	}
+	if p.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if p.OptionsMin < 0 || p.OptionsMax < 0 || p.OptionsMax > 0 && p.OptionsMax < max(p.OptionsMin, 1) {
+		return fmt.Errorf("options_max must not be less than options_min")
+	}
+	p.Template = strings.TrimSpace(p.Template)
+	if p.Template != "" && !templatePattern.MatchString(p.Template) {
+		return fmt.Errorf("template must match %s", templatePattern)
+	}
+	approvers := make([]string, 0, len(p.Approvers))
+	for _, approver := range p.Approvers {
+		approver = strings.TrimPrefix(strings.TrimSpace(approver), "@")
+		if approver == "" {
+			return fmt.Errorf("approvers must be Telegram usernames")
+		}
+		approvers = append(approvers, approver)
+	}
+	p.Approvers = approvers
+	return nil
+}
+
+// Registry holds tool profiles by tool name.
+type Registry struct {
+	mu       sync.RWMutex
+	profiles map[string]Profile
+}
+
+type file struct {
+	Tools []Profile `yaml:"tools"`
+}
+
+// Load reads profiles from a YAML file; empty path returns an empty registry.
+func Load(path string) (*Registry, error) {
+	registry := &Registry{profiles: make(map[string]Profile)}
+	if path == "" {
+		return registry, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tools file: %w", err)
+	}
+	var parsed file
+	if err := yaml.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("parse tools file: %w", err)
+	}
+	for idx := range parsed.Tools {
+		profile := parsed.Tools[idx]
+		if err := profile.Normalize(); err != nil {
+			return nil, fmt.Errorf("tools[%d]: %w", idx, err)
+		}
+		if _, dup := registry.profiles[profile.Name]; dup {
+			return nil, fmt.Errorf("tools[%d]: duplicate name %q", idx, profile.Name)
+		}
+		registry.profiles[profile.Name] = profile
+	}
+	return registry, nil
+}
+
+// Get returns the profile of the tool.
+func (r *Registry) Get(name string) (Profile, bool) {
+	if r == nil {
+		return Profile{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	profile, ok := r.profiles[name]
+	return profile, ok
+}
+
+// Put validates and registers the profile, replacing an existing one; it reports whether the profile is new.
+func (r *Registry) Put(profile Profile) (bool, error) {
+	if err := profile.Normalize(); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, exists := r.profiles[profile.Name]
+	r.profiles[profile.Name] = profile
+	return !exists, nil
+}
+
+// Delete removes the profile of the tool and reports whether it existed.
+func (r *Registry) Delete(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.profiles[name]
+	delete(r.profiles, name)
+	return ok
+}
+
+// List returns profiles sorted by tool name.
+func (r *Registry) List() []Profile {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Profile, 0, len(r.profiles))
+	for _, profile := range r.profiles {
+		out = append(out, profile)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Len returns the number of profiles.
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.profiles)
+}